@@ -7,7 +7,10 @@ import (
 
 // SequenceOf is a parser that executes a sequence of parsers against a parser state
 func SequenceOf(parsers ...*Parser) *Parser {
-	newParser := Parser{name: "SequenceOf(" + getParserNames(parsers...) + ")"}
+	newParser := Parser{
+		name: "SequenceOf(" + getParserNames(parsers...) + ")",
+		spec: ParserSpec{Kind: SequenceOfKind, Parsers: parsers},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
@@ -18,11 +21,11 @@ func SequenceOf(parsers ...*Parser) *Parser {
 			nextState = (*parser).ParserFun(nextState)
 			if nextState.IsError {
 				// TODO: Enrich error message
-				return updateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), nextState.Err))
+				return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), nextState.Err))
 			}
 			results = slices.Concat(results, []Result{Result(nextState.Results)})
 		}
-		return updateParserState(nextState, nextState.Index, Result(results))
+		return UpdateParserState(nextState, nextState.Index, Result(results))
 	}
 	newParser.SetParserFun(parserFun)
 	return &newParser
@@ -36,7 +39,10 @@ var Times = Count
 // It returns error if it could not run the parser exaclty count times.
 // You can use Times parser, instead of Count since that is an alias of this parser.
 func Count(parser *Parser, count int) *Parser {
-	newParser := Parser{name: "Count(" + parser.Name() + ")"}
+	newParser := Parser{
+		name: "Count(" + parser.Name() + ")",
+		spec: ParserSpec{Kind: CountKind, Parsers: []*Parser{parser}, Min: count, Max: count},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
@@ -46,7 +52,7 @@ func Count(parser *Parser, count int) *Parser {
 		var testState ParserState
 
 		for {
-			testState := parser.ParserFun(nextState)
+			testState = parser.ParserFun(nextState)
 			if testState.IsError || len(results) >= count {
 				break
 			} else {
@@ -56,9 +62,9 @@ func Count(parser *Parser, count int) *Parser {
 		}
 		if len(results) != count {
 			// TODO: Enrich error message
-			return updateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
+			return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
 		}
-		return updateParserState(nextState, nextState.Index, Result(results))
+		return UpdateParserState(nextState, nextState.Index, Result(results))
 	}
 	newParser.SetParserFun(parserFun)
 	return &newParser
@@ -72,7 +78,10 @@ var TimesMin = CountMin
 // It returns error if it could not run the parser at least minOccurences times.
 // You can use TimesMin parser, instead of CountMin since that is an alias of this parser.
 func CountMin(parser *Parser, minOccurences int) *Parser {
-	newParser := Parser{name: "CountMin(" + parser.Name() + ")"}
+	newParser := Parser{
+		name: "CountMin(" + parser.Name() + ")",
+		spec: ParserSpec{Kind: CountMinKind, Parsers: []*Parser{parser}, Min: minOccurences, Max: Unbounded},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
@@ -82,7 +91,10 @@ func CountMin(parser *Parser, minOccurences int) *Parser {
 		var testState ParserState
 
 		for {
-			testState := parser.ParserFun(nextState)
+			testState = parser.ParserFun(nextState)
+			if testState.IsIncomplete() {
+				return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
+			}
 			if testState.IsError {
 				break
 			} else {
@@ -92,9 +104,9 @@ func CountMin(parser *Parser, minOccurences int) *Parser {
 		}
 		if len(results) < minOccurences {
 			// TODO: Enrich error message
-			return updateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
+			return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
 		}
-		return updateParserState(nextState, nextState.Index, Result(results))
+		return UpdateParserState(nextState, nextState.Index, Result(results))
 	}
 	newParser.SetParserFun(parserFun)
 	return &newParser
@@ -108,7 +120,10 @@ var TimesMinMax = CountMinMax
 // It returns error if it could not run the parser at least minOccurences times.
 // You can use TimesMinMax parser, instead of CountMinMax since that is an alias of this parser.
 func CountMinMax(parser *Parser, minOccurences int, maxOccurences int) *Parser {
-	newParser := Parser{name: "CountMinMax(" + parser.Name() + ")"}
+	newParser := Parser{
+		name: "CountMinMax(" + parser.Name() + ")",
+		spec: ParserSpec{Kind: CountMinMaxKind, Parsers: []*Parser{parser}, Min: minOccurences, Max: maxOccurences},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
@@ -118,7 +133,10 @@ func CountMinMax(parser *Parser, minOccurences int, maxOccurences int) *Parser {
 		var testState ParserState
 
 		for {
-			testState := parser.ParserFun(nextState)
+			testState = parser.ParserFun(nextState)
+			if testState.IsIncomplete() && len(results) < maxOccurences {
+				return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
+			}
 			if testState.IsError || len(results) >= maxOccurences {
 				break
 			} else {
@@ -128,9 +146,9 @@ func CountMinMax(parser *Parser, minOccurences int, maxOccurences int) *Parser {
 		}
 		if len(results) < minOccurences {
 			// TODO: Enrich error message
-			return updateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
+			return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
 		}
-		return updateParserState(nextState, nextState.Index, Result(results))
+		return UpdateParserState(nextState, nextState.Index, Result(results))
 	}
 	newParser.SetParserFun(parserFun)
 	return &newParser
@@ -138,17 +156,24 @@ func CountMinMax(parser *Parser, minOccurences int, maxOccurences int) *Parser {
 
 // ZeroOrOne tries to execute the parser given as a parameter once.
 // It returns `nil` if it could not match, or a single result if match occured.
-// It never returns error either it could run the parser only once or could not run it at all.
+// It never returns error either it could run the parser only once or could not run it at all,
+// except in partial mode, if the parser needs more input.
 func ZeroOrOne(parser *Parser) *Parser {
-	newParser := Parser{name: "ZeroOrOne(" + parser.Name() + ")"}
+	newParser := Parser{
+		name: "ZeroOrOne(" + parser.Name() + ")",
+		spec: ParserSpec{Kind: ZeroOrOneKind, Parsers: []*Parser{parser}, Min: 0, Max: 1},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 
 		nextState := parser.ParserFun(parserState)
+		if nextState.IsIncomplete() {
+			return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), nextState.Err))
+		}
 		if nextState.IsError {
-			return updateParserState(parserState, nextState.Index, Result(nil))
+			return UpdateParserState(parserState, nextState.Index, Result(nil))
 		}
 
 		return nextState
@@ -162,9 +187,13 @@ var Optional = ZeroOrOne
 
 // ZeroOrMore tries to execute the parser given as a parameter, until it succeeds.
 // Collects the results into an array and returns with it at the end.
-// It never returns error either it could run the parser any times without errors or never.
+// It never returns error either it could run the parser any times without errors or never,
+// except in partial mode, if the parser needs more input.
 func ZeroOrMore(parser *Parser) *Parser {
-	newParser := Parser{name: "ZeroOrMore(" + parser.Name() + ")"}
+	newParser := Parser{
+		name: "ZeroOrMore(" + parser.Name() + ")",
+		spec: ParserSpec{Kind: ZeroOrMoreKind, Parsers: []*Parser{parser}, Min: 0, Max: Unbounded},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
@@ -175,6 +204,9 @@ func ZeroOrMore(parser *Parser) *Parser {
 
 		for {
 			testState := parser.ParserFun(nextState)
+			if testState.IsIncomplete() {
+				return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
+			}
 			if testState.IsError {
 				break
 			} else {
@@ -182,7 +214,7 @@ func ZeroOrMore(parser *Parser) *Parser {
 				nextState = testState
 			}
 		}
-		return updateParserState(nextState, nextState.Index, Result(results))
+		return UpdateParserState(nextState, nextState.Index, Result(results))
 	}
 	newParser.SetParserFun(parserFun)
 	return &newParser
@@ -193,7 +225,10 @@ func ZeroOrMore(parser *Parser) *Parser {
 // It executes the parser given as a parameter, until it succeeds,
 // meanwhile it collects the results into an array then returns with it at the end.
 func OneOrMore(parser *Parser) *Parser {
-	newParser := Parser{name: "OneOrMore(" + parser.Name() + ")"}
+	newParser := Parser{
+		name: "OneOrMore(" + parser.Name() + ")",
+		spec: ParserSpec{Kind: OneOrMoreKind, Parsers: []*Parser{parser}, Min: 1, Max: Unbounded},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
@@ -204,6 +239,9 @@ func OneOrMore(parser *Parser) *Parser {
 
 		for {
 			testState := parser.ParserFun(nextState)
+			if testState.IsIncomplete() {
+				return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), testState.Err))
+			}
 			if testState.IsError {
 				break
 			} else {
@@ -213,30 +251,38 @@ func OneOrMore(parser *Parser) *Parser {
 		}
 		if len(results) == 0 {
 			// TODO: Enrich error message
-			return updateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), nextState.Err))
+			return UpdateParserError(parserState, fmt.Errorf("%s: %w", newParser.Name(), nextState.Err))
 		}
-		return updateParserState(nextState, nextState.Index, Result(results))
+		return UpdateParserState(nextState, nextState.Index, Result(results))
 	}
 	newParser.SetParserFun(parserFun)
 	return &newParser
 }
 
 // Choice is a parser that executes a sequence of parsers against a parser state,
-// and returns the first successful result if there is any
+// and returns the first successful result if there is any.
+// In partial mode it needs more input, if an alternative needs more input before a successful one.
 func Choice(parsers ...*Parser) *Parser {
-	parser := Parser{name: "Choice(" + getParserNames(parsers...) + ")"}
+	parser := Parser{
+		name: "Choice(" + getParserNames(parsers...) + ")",
+		spec: ParserSpec{Kind: ChoiceKind, Parsers: parsers},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 		var nextState ParserState
-		for _, parser := range parsers {
-			nextState = (*parser).ParserFun(parserState)
+		for _, alternative := range parsers {
+			nextState = (*alternative).ParserFun(parserState)
 			if !nextState.IsError {
 				return nextState
 			}
+			// The alternative might match with more input, so the next alternatives must not be tried yet
+			if nextState.IsIncomplete() {
+				return UpdateParserError(parserState, fmt.Errorf("%s: %w", parser.Name(), nextState.Err))
+			}
 		}
-		return updateParserError(parserState, fmt.Errorf("%s: Unable to match any with '%s'", parser.Name(), parserState.Remaining()))
+		return UpdateParserError(parserState, fmt.Errorf("%s: Unable to match any with '%s'", parser.Name(), parserState.Remaining()))
 	}
 	parser.SetParserFun(parserFun)
 	return &parser
@@ -258,15 +304,18 @@ func Chain(parser *Parser, parserMakerFn func(Result) *Parser) *Parser {
 		nextParser := parserMakerFn(newState.Results)
 		result := nextParser.ParserFun(newState)
 
-		return updateParserState(newState, newState.Index, Result(result))
+		return UpdateParserState(newState, newState.Index, Result(result))
 	}
 
-	return NewParser("Chain("+parser.Name()+")", parserFun)
+	newParser := NewParser("Chain("+parser.Name()+")", parserFun)
+	newParser.spec = ParserSpec{Kind: ChainKind, Parsers: []*Parser{parser}, ChainFn: parserMakerFn}
+	return newParser
 }
 
 // Between is a utility function that takes two parsers as arguments that defines a starting and ending pattern of a content,
 // and returns a function that takes a content parser as argument.
 // Using the resulted parser will provide a result that is the outcome of the content parser.
+// The Printer prints the result by the content parser between the default texts of the starting and ending parsers.
 func Between(leftParser, rightParser *Parser) func(*Parser) *Parser {
 	return func(contentParser *Parser) *Parser {
 		return SequenceOf(
@@ -276,6 +325,14 @@ func Between(leftParser, rightParser *Parser) func(*Parser) *Parser {
 		).Map(func(result Result) Result {
 			arrResults := result.([]Result)
 			return arrResults[1]
+		}).PrintWith(func(printer *Printer, result Result) error {
+			if err := printer.PrintDefault(leftParser); err != nil {
+				return err
+			}
+			if err := printer.Print(contentParser, result); err != nil {
+				return err
+			}
+			return printer.PrintDefault(rightParser)
 		})
 	}
 }
@@ -0,0 +1,189 @@
+package parc
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// CompletionKind is the kind of a completion
+type CompletionKind int
+
+const (
+	// LiteralCompletion is a literal of a Str or Char parser, that can be inserted as it is
+	LiteralCompletion CompletionKind = iota
+	// RuleCompletion is a parser named by the As method, e.g. `statement`
+	RuleCompletion
+	// ClassCompletion is a class of characters or texts matched by a primitive parser, e.g. `Letters` or `/[0-9]+/`
+	ClassCompletion
+)
+
+// completionKindNames holds the printable names of the completion kinds
+var completionKindNames = map[CompletionKind]string{
+	LiteralCompletion: "literal",
+	RuleCompletion:    "rule",
+	ClassCompletion:   "class",
+}
+
+// String returns with the name of the completion kind
+func (k CompletionKind) String() string {
+	return completionKindNames[k]
+}
+
+// Completion is an item, that could continue the input at the cursor position
+type Completion struct {
+	// Kind is the kind of the completion
+	Kind CompletionKind
+	// Text is the literal in case of the literals, the name of the parser in case of the rules,
+	// and the description of the parser in case of the classes, like in the expected set of the diagnostics
+	Text string
+	// Span is the range of the input, that the completion replaces. It is the part of the word before the cursor,
+	// that is the prefix of the literal, or it is the empty range at the cursor.
+	Span Span
+	// Parser is the expected parser, e.g. to test the characters by the condition of its spec
+	Parser *Parser
+}
+
+// Complete returns with the completions, that the parser would accept at the cursor position of the input.
+//
+// It runs the parser on the input up to the cursor, and collects the parsers expected at the cursor by the failure tracking
+// of the Diagnose method. The parsers named by the As method are completed by their names, and by the parsers, that may
+// start them, according to the specs of the parsers. If the cursor is at the end of a word, the literals are also completed,
+// that were expected at the start of the word and that start with the word, e.g. `sel` may be completed to `select`.
+//
+// The input after the cursor is ignored. The result is empty if the input before the cursor has a syntax error.
+func (p *Parser) Complete(input string, cursor int) ([]Completion, error) {
+	if cursor < 0 || cursor > len(input) {
+		return nil, fmt.Errorf("Complete: invalid cursor %d of input of length %d", cursor, len(input))
+	}
+
+	c := &completer{}
+	wordStart := cursor
+	for wordStart > 0 {
+		r, size := utf8.DecodeLastRuneInString(input[:wordStart])
+		if !isWordRune(r) {
+			break
+		}
+		wordStart -= size
+	}
+	if wordStart < cursor {
+		visited := map[*Parser]bool{}
+		for _, parser := range p.expectedAtEnd(input[:wordStart]) {
+			c.add(parser, Span{Start: wordStart, End: cursor}, input[wordStart:cursor], visited)
+		}
+	}
+	visited := map[*Parser]bool{}
+	for _, parser := range p.expectedAtEnd(input[:cursor]) {
+		c.add(parser, Span{Start: cursor, End: cursor}, "", visited)
+	}
+	return c.completions, nil
+}
+
+// expectedAtEnd returns with the parsers, that failed at the end of the input
+func (p *Parser) expectedAtEnd(input string) []*Parser {
+	initialState := NewParserState(&input, Result(nil), 0, nil)
+	initialState.failures = newFailureTracker()
+	p.ParserFun(initialState)
+	if initialState.failures.furthest != len(input) {
+		return nil
+	}
+	return initialState.failures.expected
+}
+
+// completer collects the completions without duplicates
+type completer struct {
+	completions []Completion
+}
+
+// add adds the completions of the parser, that replace the span. If the prefix is not empty,
+// only the literals are added, that start with the prefix.
+func (c *completer) add(parser *Parser, span Span, prefix string, visited map[*Parser]bool) {
+	if visited[parser] {
+		return
+	}
+	visited[parser] = true
+
+	spec := parser.Spec()
+	switch spec.Kind {
+	case StrKind, CharKind:
+		if len(spec.Literal) > len(prefix) && strings.HasPrefix(spec.Literal, prefix) {
+			c.append(Completion{Kind: LiteralCompletion, Text: spec.Literal, Span: span, Parser: parser})
+		}
+		return
+	case StartOfInputKind, EndOfInputKind:
+		return
+	case CustomParserKind, RestKind, RegExpKind, CondKind, CondMinKind, CondMinMaxKind:
+		if prefix == "" {
+			c.append(Completion{Kind: ClassCompletion, Text: describeParser(parser), Span: span, Parser: parser})
+		}
+		return
+	}
+
+	if parser.named && prefix == "" {
+		c.append(Completion{Kind: RuleCompletion, Text: parser.Name(), Span: span, Parser: parser})
+	}
+	switch spec.Kind {
+	case SequenceOfKind:
+		for _, child := range spec.Parsers {
+			c.add(child, span, prefix, visited)
+			if !isNullable(child, map[*Parser]bool{}) {
+				break
+			}
+		}
+	case ChoiceKind:
+		for _, alternative := range spec.Parsers {
+			c.add(alternative, span, prefix, visited)
+		}
+	default:
+		c.add(spec.Parsers[0], span, prefix, visited)
+	}
+}
+
+// append appends the completion, unless it is already added
+func (c *completer) append(completion Completion) {
+	for _, added := range c.completions {
+		if added.Kind == completion.Kind && added.Text == completion.Text && added.Span == completion.Span {
+			return
+		}
+	}
+	c.completions = append(c.completions, completion)
+}
+
+// isNullable tests if the parser may succeed without consuming input, according to its spec.
+// The recursive parsers are not nullable through their recursion.
+func isNullable(parser *Parser, visiting map[*Parser]bool) bool {
+	if visiting[parser] {
+		return false
+	}
+	visiting[parser] = true
+	defer delete(visiting, parser)
+
+	spec := parser.Spec()
+	switch spec.Kind {
+	case StartOfInputKind, EndOfInputKind, RestKind, ZeroOrOneKind, ZeroOrMoreKind:
+		return true
+	case StrKind, CharKind:
+		return spec.Literal == ""
+	case RegExpKind, CondKind, CondMinKind, CondMinMaxKind:
+		return checkText(spec, "") == nil
+	case CountKind, CountMinKind, CountMinMaxKind, OneOrMoreKind:
+		return spec.Min == 0 || isNullable(spec.Parsers[0], visiting)
+	case SequenceOfKind:
+		for _, child := range spec.Parsers {
+			if !isNullable(child, visiting) {
+				return false
+			}
+		}
+		return true
+	case ChoiceKind:
+		for _, alternative := range spec.Parsers {
+			if isNullable(alternative, visiting) {
+				return true
+			}
+		}
+		return false
+	case MapKind, ErrorMapKind, MemoKind, ChainKind:
+		return isNullable(spec.Parsers[0], visiting)
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package parc
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// completionParser returns a parser of a server block like `server { listen 80; name "web"; }`
+func completionParser() *Parser {
+	whitespace := CondMin(IsWhitespace, 0)
+	directive := Choice(Str("listen"), Str("location"), Str("name")).As("directive")
+	value := Choice(Digits, Str("on"), Str("off"), RegExp(`"[^"]*"`)).As("value")
+	statement := SequenceOf(directive, CondMin(IsWhitespace, 1), value, whitespace, Char(";"))
+	block := SequenceOf(Str("server"), whitespace, Char("{"), ZeroOrMore(SequenceOf(whitespace, statement)), whitespace, Char("}"))
+	return SequenceOf(whitespace, block, whitespace, EndOfInput())
+}
+
+func TestComplete(t *testing.T) {
+	parser := completionParser()
+	for _, test := range []struct {
+		input    string
+		cursor   int
+		expected []Completion
+	}{
+		{"", 0, []Completion{{Kind: LiteralCompletion, Text: "server", Span: Span{Start: 0, End: 0}}}},
+		{"ser", 3, []Completion{{Kind: LiteralCompletion, Text: "server", Span: Span{Start: 0, End: 3}}}},
+		{"server {\n  ", 11, []Completion{
+			{Kind: RuleCompletion, Text: "directive", Span: Span{Start: 11, End: 11}},
+			{Kind: LiteralCompletion, Text: "listen", Span: Span{Start: 11, End: 11}},
+			{Kind: LiteralCompletion, Text: "location", Span: Span{Start: 11, End: 11}},
+			{Kind: LiteralCompletion, Text: "name", Span: Span{Start: 11, End: 11}},
+			{Kind: LiteralCompletion, Text: "}", Span: Span{Start: 11, End: 11}},
+		}},
+		// The input after the cursor is ignored
+		{"server {\n  l}", 12, []Completion{
+			{Kind: LiteralCompletion, Text: "listen", Span: Span{Start: 11, End: 12}},
+			{Kind: LiteralCompletion, Text: "location", Span: Span{Start: 11, End: 12}},
+		}},
+		{"server {\n  listen ", 18, []Completion{
+			{Kind: RuleCompletion, Text: "value", Span: Span{Start: 18, End: 18}},
+			{Kind: ClassCompletion, Text: "Digits", Span: Span{Start: 18, End: 18}},
+			{Kind: LiteralCompletion, Text: "on", Span: Span{Start: 18, End: 18}},
+			{Kind: LiteralCompletion, Text: "off", Span: Span{Start: 18, End: 18}},
+			{Kind: ClassCompletion, Text: `/"[^"]*"/`, Span: Span{Start: 18, End: 18}},
+		}},
+		{"server {\n  listen o", 19, []Completion{
+			{Kind: LiteralCompletion, Text: "on", Span: Span{Start: 18, End: 19}},
+			{Kind: LiteralCompletion, Text: "off", Span: Span{Start: 18, End: 19}},
+		}},
+		{"server {\n  listen 80", 20, []Completion{{Kind: LiteralCompletion, Text: ";", Span: Span{Start: 20, End: 20}}}},
+		// There is a syntax error before the cursor
+		{"server [", 8, nil},
+	} {
+		completions, err := parser.Complete(test.input, test.cursor)
+		require.NoError(t, err)
+		for i := range completions {
+			require.NotNil(t, completions[i].Parser)
+			completions[i].Parser = nil
+		}
+		require.Equal(t, test.expected, completions, test.input)
+	}
+
+	_, err := parser.Complete("abc", 4)
+	require.EqualError(t, err, "Complete: invalid cursor 4 of input of length 3")
+}
+
+func TestComplete_Recursion(t *testing.T) {
+	var expression Parser
+	term := Choice(Digits, SequenceOf(Char("("), &expression, Char(")")), Str("pi")).As("term")
+	expression = *SequenceOf(term, ZeroOrMore(SequenceOf(Choice(Char("+"), Char("-")), term))).As("expression")
+
+	completions, err := expression.Complete("1+(", 3)
+	require.NoError(t, err)
+	texts := []string{}
+	for _, completion := range completions {
+		texts = append(texts, completion.Kind.String()+" "+completion.Text)
+	}
+	require.Equal(t, []string{"rule expression", "rule term", "class Digits", `literal (`, "literal pi"}, texts)
+
+	require.True(t, isNullable(ZeroOrMore(&expression), map[*Parser]bool{}))
+	require.False(t, isNullable(&expression, map[*Parser]bool{}))
+	require.True(t, isNullable(SequenceOf(Optional(Digits), CondMin(IsSpace, 0), RegExp(`a*`)), map[*Parser]bool{}))
+}
@@ -35,7 +35,7 @@ func IsOctalDigit(r rune) bool {
 
 // IsBinaryDigit tests if rune is ASCII binary digit: [0-1]
 func IsBinaryDigit(r rune) bool {
-	return r >= '0' && r <= '7'
+	return r == '0' || r == '1'
 }
 
 // IsAlphaNumeric tests if rune is ASCII alphanumeric: [A-Za-z0-9]
@@ -75,27 +75,33 @@ func IsAnyChar(r rune) bool {
 
 // Cond returns a Parser which tests the next rune in the input with the condition function.
 // If the condition is met, the rune is consumed from the input and the parser succeeds.
-// Otherwise the parser fails.
+// Otherwise the parser fails. In partial mode it needs more input at the end of the input.
 func Cond(conditionFn func(rune) bool) *Parser {
 	fPtr := reflect.ValueOf(conditionFn).Pointer()
 	fn := runtime.FuncForPC(fPtr)
 
-	parser := Parser{name: fmt.Sprintf("Cond('%s')", fn.Name())}
+	parser := Parser{
+		name: fmt.Sprintf("Cond('%s')", fn.Name()),
+		spec: ParserSpec{Kind: CondKind, Condition: conditionFn, Min: 1, Max: 1},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 
+		if parserState.partial && !utf8.FullRuneInString(parserState.Remaining()) {
+			return UpdateParserIncomplete(parserState, parser.Name(), 1)
+		}
 		if parserState.AtTheEnd() {
-			return updateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", parser.Name()))
+			return UpdateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", parser.Name()))
 		}
 
 		// Try to take a single occurence
 		r, nextState := parserState.NextRune()
 		if !conditionFn(r) {
-			return updateParserError(parserState, fmt.Errorf("%s: could not match %c", parser.Name(), r))
+			return UpdateParserError(parserState, fmt.Errorf("%s: could not match %c", parser.Name(), r))
 		}
-		return updateParserState(parserState, nextState.Index, Result(string(r)))
+		return UpdateParserState(parserState, nextState.Index, Result(string(r)))
 	}
 	parser.SetParserFun(parserFun)
 	return &parser
@@ -104,20 +110,26 @@ func Cond(conditionFn func(rune) bool) *Parser {
 // CondMin returns a Parser which tests the next rune in the input with the condition function.
 // If the condition is met, the rune is consumed from the input and the parser succeeds as many times as possible,
 // but at least `minOccurences` times.
-// Otherwise the parser fails.
+// Otherwise the parser fails. In partial mode it needs more input, if it reaches the end of the input.
 func CondMin(conditionFn func(rune) bool, minOccurences int) *Parser {
-	parser := Parser{name: "CondMin"}
+	parser := Parser{
+		name: "CondMin",
+		spec: ParserSpec{Kind: CondMinKind, Condition: conditionFn, Min: minOccurences, Max: Unbounded},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 
+		if parserState.partial && parserState.AtTheEnd() {
+			return UpdateParserIncomplete(parserState, parser.Name(), max(1, minOccurences))
+		}
 		if parserState.AtTheEnd() && minOccurences > 0 {
-			return updateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", parser.Name()))
+			return UpdateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", parser.Name()))
 		}
 
 		if minOccurences < 0 {
-			return updateParserError(parserState, fmt.Errorf("%s: wrong minOccurences value %d", parser.Name(), minOccurences))
+			return UpdateParserError(parserState, fmt.Errorf("%s: wrong minOccurences value %d", parser.Name(), minOccurences))
 		}
 
 		currentState := parserState
@@ -135,10 +147,13 @@ func CondMin(conditionFn func(rune) bool, minOccurences int) *Parser {
 			currentState = nextState
 			results = utf8.AppendRune(results, r)
 		}
+		if parserState.partial && !utf8.FullRuneInString(currentState.Remaining()) {
+			return UpdateParserIncomplete(parserState, parser.Name(), max(1, minOccurences-numFound))
+		}
 		if numFound < minOccurences {
-			return updateParserError(parserState, fmt.Errorf("%s: %d number of found are less then minOccurences %d", parser.Name(), numFound, minOccurences))
+			return UpdateParserError(parserState, fmt.Errorf("%s: %d number of found are less then minOccurences %d", parser.Name(), numFound, minOccurences))
 		}
-		return updateParserState(parserState, currentState.Index, Result(string(results)))
+		return UpdateParserState(parserState, currentState.Index, Result(string(results)))
 	}
 	parser.SetParserFun(parserFun)
 	return &parser
@@ -147,20 +162,26 @@ func CondMin(conditionFn func(rune) bool, minOccurences int) *Parser {
 // CondMinMax returns a Parser which tests the next rune in the input with the condition function.
 // If the condition is met, the rune is consumed from the input and the parser succeeds at minimum of `minOccurences` times,
 // but maximum of `maxOccurences` times.
-// Otherwise the parser fails.
+// Otherwise the parser fails. In partial mode it needs more input, if it reaches the end of the input before the maximum.
 func CondMinMax(conditionFn func(rune) bool, minOccurences, maxOccurences int) *Parser {
-	parser := Parser{name: "CondMinMax"}
+	parser := Parser{
+		name: "CondMinMax",
+		spec: ParserSpec{Kind: CondMinMaxKind, Condition: conditionFn, Min: minOccurences, Max: maxOccurences},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 
+		if parserState.partial && parserState.AtTheEnd() && maxOccurences > 0 {
+			return UpdateParserIncomplete(parserState, parser.Name(), max(1, minOccurences))
+		}
 		if parserState.AtTheEnd() && minOccurences > 0 {
-			return updateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", parser.Name()))
+			return UpdateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", parser.Name()))
 		}
 
 		if minOccurences < 0 || minOccurences > maxOccurences {
-			return updateParserError(parserState, fmt.Errorf("%s: wrong range of occurences min. %d, max. %d", parser.Name(), minOccurences, maxOccurences))
+			return UpdateParserError(parserState, fmt.Errorf("%s: wrong range of occurences min. %d, max. %d", parser.Name(), minOccurences, maxOccurences))
 		}
 
 		currentState := parserState
@@ -178,10 +199,13 @@ func CondMinMax(conditionFn func(rune) bool, minOccurences, maxOccurences int) *
 			currentState = nextState
 			results = utf8.AppendRune(results, r)
 		}
+		if parserState.partial && numFound < maxOccurences && !utf8.FullRuneInString(currentState.Remaining()) {
+			return UpdateParserIncomplete(parserState, parser.Name(), max(1, minOccurences-numFound))
+		}
 		if numFound < minOccurences {
-			return updateParserError(parserState, fmt.Errorf("%s: %d number of found are less then minOccurences %d", parser.Name(), numFound, minOccurences))
+			return UpdateParserError(parserState, fmt.Errorf("%s: %d number of found are less then minOccurences %d", parser.Name(), numFound, minOccurences))
 		}
-		return updateParserState(parserState, currentState.Index, Result(string(results)))
+		return UpdateParserState(parserState, currentState.Index, Result(string(results)))
 	}
 	parser.SetParserFun(parserFun)
 	return &parser
@@ -66,3 +66,11 @@ func TestCondMinMax_Ok(t *testing.T) {
 	expectedState = NewParserState(&input, "Hello", 5, expectedError)
 	require.Equal(t, expectedState, newState)
 }
+
+func TestIsBinaryDigit(t *testing.T) {
+	require.True(t, IsBinaryDigit('0'))
+	require.True(t, IsBinaryDigit('1'))
+	require.False(t, IsBinaryDigit('2'))
+	require.False(t, IsBinaryDigit('7'))
+	require.False(t, IsBinaryDigit('a'))
+}
@@ -0,0 +1,333 @@
+package parc
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Captured is the result of a Capture parser, that holds the result of the captured parser with its name and span
+type Captured struct {
+	// Name is the name of the capture, that identifies the struct field by its `parc:"name"` tag
+	Name string
+	// Value is the result of the captured parser
+	Value Result
+	// Span is the range of the input matched by the captured parser
+	Span Span
+}
+
+// Capture returns a parser, that runs the parser and returns with its result as a Captured value of the given name.
+// The captures are decoded into the struct fields of the same tag name by Decode.
+// The Printer prints the value of a Captured result of the same name by the parser.
+func Capture(name string, parser *Parser) *Parser {
+	parserFun := func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := parser.ParserFun(parserState)
+		if newState.IsError {
+			return newState
+		}
+
+		captured := Captured{Name: name, Value: newState.Results, Span: Span{Start: parserState.Index, End: newState.Index}}
+		return UpdateParserState(newState, newState.Index, Result(captured))
+	}
+
+	newParser := NewParser("Capture("+name+")", parserFun)
+	newParser.spec = ParserSpec{Kind: MapKind, Parsers: []*Parser{parser}}
+	newParser.printer = func(printer *Printer, result Result) error {
+		captured, ok := result.(Captured)
+		if !ok || captured.Name != name {
+			return fmt.Errorf("Print: %s: expected capture %q, got %v", newParser.Name(), name, result)
+		}
+		return printer.Print(parser, captured.Value)
+	}
+	return newParser
+}
+
+// DecodeError is the error of Decode, that tells which struct field could not be decoded from which part of the input
+type DecodeError struct {
+	// Field is the path of the field, e.g. `Headers[1].Name`
+	Field string
+	// Span is the range of the input matched by the parser of the field
+	Span Span
+	// Err is the reason of the failure
+	Err error
+}
+
+// Error returns with the error message
+func (e *DecodeError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("Decode: [%d:%d]: %v", e.Span.Start, e.Span.End, e.Err)
+	}
+	return fmt.Sprintf("Decode: field %s [%d:%d]: %v", e.Field, e.Span.Start, e.Span.End, e.Err)
+}
+
+// Unwrap returns with the reason of the failure
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decode stores the parse result into the value pointed to by the target.
+//
+// The struct fields with a `parc:"name"` tag are decoded from the Captured values of the same name,
+// that are found in the result, including the nested arrays of results, but not the results of other captures.
+// The captures of a nested struct are searched in the result of the capture of its field.
+// The struct fields are required, except the pointers, which are nil if the capture is missing,
+// and the slices, which get an item from each capture of their name. If there is a single capture
+// of an array of results, the slice gets an item from each result, unless the slice holds structs.
+//
+// The values are converted to the type of the target, if they are assignable or convertible without overflow.
+// The strings and the arrays of strings are parsed into the numbers and booleans, and they are passed to the
+// UnmarshalText method of the types implementing encoding.TextUnmarshaler.
+func Decode(result Result, target any) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() {
+		return errors.New("Decode: target must be a non-nil pointer")
+	}
+	span := Span{}
+	if captured, ok := result.(Captured); ok && !isStructTarget(targetValue.Elem()) {
+		result, span = captured.Value, captured.Span
+	}
+	return decodeValue(targetValue.Elem(), result, "", span)
+}
+
+// textUnmarshalerType is the type of the encoding.TextUnmarshaler interface
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// isStructTarget tests if the value is a struct, or a pointer to a struct, that is decoded from captures
+func isStructTarget(value reflect.Value) bool {
+	valueType := value.Type()
+	if valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+	return valueType.Kind() == reflect.Struct && !reflect.PointerTo(valueType).Implements(textUnmarshalerType)
+}
+
+// decodeValue stores the result into the value
+func decodeValue(value reflect.Value, result Result, path string, span Span) error {
+	if captured, ok := result.(Captured); ok && !isStructTarget(value) {
+		return decodeValue(value, captured.Value, path, captured.Span)
+	}
+	fail := func(format string, args ...any) error {
+		return &DecodeError{Field: path, Span: span, Err: fmt.Errorf(format, args...)}
+	}
+
+	if value.Kind() == reflect.Pointer {
+		if result == nil {
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return decodeValue(value.Elem(), result, path, span)
+	}
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		text, ok := textOf(result)
+		if !ok {
+			return fail("cannot decode %T into %s", result, value.Type())
+		}
+		if err := value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return &DecodeError{Field: path, Span: span, Err: err}
+		}
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return decodeStruct(value, result, path, span)
+	case reflect.Slice:
+		return decodeSlice(value, result, path, span)
+	case reflect.Interface:
+		if result == nil {
+			return nil
+		}
+		if !reflect.TypeOf(result).AssignableTo(value.Type()) {
+			return fail("cannot decode %T into %s", result, value.Type())
+		}
+		value.Set(reflect.ValueOf(result))
+		return nil
+	}
+	if result == nil {
+		return nil
+	}
+
+	resultValue := reflect.ValueOf(result)
+	if resultValue.Type().AssignableTo(value.Type()) {
+		value.Set(resultValue)
+		return nil
+	}
+	text, isText := textOf(result)
+	switch {
+	case value.Kind() == reflect.String && isText:
+		value.SetString(text)
+	case value.CanInt() && isText:
+		n, err := strconv.ParseInt(strings.ReplaceAll(text, "_", ""), 0, value.Type().Bits())
+		if err != nil {
+			return fail("%w", err)
+		}
+		value.SetInt(n)
+	case value.CanInt() && resultValue.CanInt():
+		if value.OverflowInt(resultValue.Int()) {
+			return fail("%v overflows %s", result, value.Type())
+		}
+		value.SetInt(resultValue.Int())
+	case value.CanInt() && resultValue.CanUint():
+		if resultValue.Uint() > math.MaxInt64 || value.OverflowInt(int64(resultValue.Uint())) {
+			return fail("%v overflows %s", result, value.Type())
+		}
+		value.SetInt(int64(resultValue.Uint()))
+	case value.CanUint() && isText:
+		n, err := strconv.ParseUint(strings.ReplaceAll(text, "_", ""), 0, value.Type().Bits())
+		if err != nil {
+			return fail("%w", err)
+		}
+		value.SetUint(n)
+	case value.CanUint() && resultValue.CanInt():
+		if resultValue.Int() < 0 || value.OverflowUint(uint64(resultValue.Int())) {
+			return fail("%v overflows %s", result, value.Type())
+		}
+		value.SetUint(uint64(resultValue.Int()))
+	case value.CanUint() && resultValue.CanUint():
+		if value.OverflowUint(resultValue.Uint()) {
+			return fail("%v overflows %s", result, value.Type())
+		}
+		value.SetUint(resultValue.Uint())
+	case value.CanFloat() && isText:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), value.Type().Bits())
+		if err != nil {
+			return fail("%w", err)
+		}
+		value.SetFloat(f)
+	case value.CanFloat() && (resultValue.CanInt() || resultValue.CanUint() || resultValue.CanFloat()):
+		f := resultValue.Convert(reflect.TypeOf(float64(0))).Float()
+		if value.OverflowFloat(f) {
+			return fail("%v overflows %s", result, value.Type())
+		}
+		value.SetFloat(f)
+	case value.Kind() == reflect.Bool && isText:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fail("%w", err)
+		}
+		value.SetBool(b)
+	default:
+		return fail("cannot decode %T into %s", result, value.Type())
+	}
+	return nil
+}
+
+// decodeStruct stores the captures of the result into the tagged fields of the struct
+func decodeStruct(value reflect.Value, result Result, path string, span Span) error {
+	if captured, ok := result.(Captured); ok {
+		result, span = captured.Value, captured.Span
+	}
+	captures := collectCaptures(result, nil)
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, ok := field.Tag.Lookup("parc")
+		if !ok || name == "-" || !field.IsExported() {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		var matches []Captured
+		for _, captured := range captures {
+			if captured.Name == name {
+				matches = append(matches, captured)
+			}
+		}
+		fieldValue := value.Field(i)
+		switch {
+		case fieldValue.Kind() == reflect.Slice && (len(matches) > 1 || len(matches) == 1 && isStructTarget(reflect.New(field.Type.Elem()).Elem())):
+			items := reflect.MakeSlice(field.Type, len(matches), len(matches))
+			for j, captured := range matches {
+				if err := decodeValue(items.Index(j), captured, fmt.Sprintf("%s[%d]", fieldPath, j), captured.Span); err != nil {
+					return err
+				}
+			}
+			fieldValue.Set(items)
+		case len(matches) > 0:
+			if err := decodeValue(fieldValue, matches[0], fieldPath, matches[0].Span); err != nil {
+				return err
+			}
+		case fieldValue.Kind() != reflect.Pointer && fieldValue.Kind() != reflect.Slice:
+			return &DecodeError{Field: fieldPath, Span: span, Err: fmt.Errorf("missing capture %q", name)}
+		}
+	}
+	return nil
+}
+
+// decodeSlice stores the items of the result into the slice.
+// An array of results gives an item from each result, any other result gives a single item.
+func decodeSlice(value reflect.Value, result Result, path string, span Span) error {
+	if result == nil {
+		return nil
+	}
+	if value.Type().Elem().Kind() == reflect.Uint8 {
+		if text, ok := textOf(result); ok {
+			value.SetBytes([]byte(text))
+			return nil
+		}
+	}
+	results, ok := result.([]Result)
+	if !ok {
+		results = []Result{result}
+	}
+	items := reflect.MakeSlice(value.Type(), len(results), len(results))
+	for i, item := range results {
+		itemSpan := span
+		if captured, ok := item.(Captured); ok {
+			itemSpan = captured.Span
+		}
+		if err := decodeValue(items.Index(i), item, fmt.Sprintf("%s[%d]", path, i), itemSpan); err != nil {
+			return err
+		}
+	}
+	value.Set(items)
+	return nil
+}
+
+// collectCaptures appends the captures of the result to the captures in depth-first order.
+// It does not look into the results of the captures.
+func collectCaptures(result Result, captures []Captured) []Captured {
+	switch r := result.(type) {
+	case Captured:
+		captures = append(captures, r)
+	case []Result:
+		for _, item := range r {
+			captures = collectCaptures(item, captures)
+		}
+	}
+	return captures
+}
+
+// textOf returns with the text of a string result or an array of string results
+func textOf(result Result) (string, bool) {
+	switch r := result.(type) {
+	case string:
+		return r, true
+	case []Result:
+		var sb strings.Builder
+		for _, item := range r {
+			if item == nil {
+				continue
+			}
+			text, ok := textOf(item)
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(text)
+		}
+		return sb.String(), true
+	}
+	return "", false
+}
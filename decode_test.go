@@ -0,0 +1,174 @@
+package parc
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// protocolVersion is decoded by its UnmarshalText method
+type protocolVersion struct {
+	Major, Minor int
+}
+
+func (v *protocolVersion) UnmarshalText(text []byte) error {
+	if _, err := fmt.Sscanf(string(text), "%d.%d", &v.Major, &v.Minor); err != nil {
+		return fmt.Errorf("invalid version %q", text)
+	}
+	return nil
+}
+
+type requestHeader struct {
+	Name  string `parc:"name"`
+	Value string `parc:"value"`
+}
+
+type request struct {
+	Method  string          `parc:"method"`
+	Path    string          `parc:"path"`
+	Port    *uint16         `parc:"port"`
+	Version protocolVersion `parc:"version"`
+	Tags    []string        `parc:"tag"`
+	Headers []requestHeader `parc:"header"`
+	// Raw is not decoded, because it has no tag
+	Raw string
+}
+
+// requestParser returns a parser of a request line like `GET /index:8080 1.1 a b` followed by `Name: value` header lines
+func requestParser() *Parser {
+	header := Capture("header", SequenceOf(Capture("name", Letters), Str(": "), Capture("value", CondMin(func(r rune) bool { return r != '\n' }, 1)), Newline))
+	return SequenceOf(
+		Capture("method", Letters),
+		Space,
+		Capture("path", CondMin(func(r rune) bool { return !IsWhitespace(r) && r != ':' }, 1)),
+		Optional(SequenceOf(Char(":"), Capture("port", Digits))),
+		Space,
+		Capture("version", SequenceOf(Digits, Char("."), Digits)),
+		ZeroOrMore(SequenceOf(Space, Capture("tag", Letters))),
+		Newline,
+		ZeroOrMore(header),
+	)
+}
+
+func TestDecode(t *testing.T) {
+	input := "GET /index:8080 1.1 a b\nHost: example.com\nAccept: text\n"
+	newState := requestParser().Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+
+	var actual request
+	require.NoError(t, Decode(newState.Results, &actual))
+	require.Equal(t, request{
+		Method:  "GET",
+		Path:    "/index",
+		Port:    Ref(uint16(8080)),
+		Version: protocolVersion{Major: 1, Minor: 1},
+		Tags:    []string{"a", "b"},
+		Headers: []requestHeader{{Name: "Host", Value: "example.com"}, {Name: "Accept", Value: "text"}},
+	}, actual)
+
+	// The optional parts are missing
+	input = "GET / 2.0\n"
+	newState = requestParser().Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	actual = request{}
+	require.NoError(t, Decode(newState.Results, &actual))
+	require.Equal(t, request{Method: "GET", Path: "/", Version: protocolVersion{Major: 2}}, actual)
+}
+
+func TestDecode_Values(t *testing.T) {
+	var n int8
+	require.NoError(t, Decode(Captured{Name: "n", Value: "-12"}, &n))
+	require.Equal(t, int8(-12), n)
+
+	var f float64
+	require.NoError(t, Decode(42, &f))
+	require.Equal(t, 42.0, f)
+
+	var small uint8
+	require.NoError(t, Decode(uint(7), &small))
+	require.Equal(t, uint8(7), small)
+	require.NoError(t, Decode(uint64(9), &n))
+	require.Equal(t, int8(9), n)
+	var f32 float32
+	require.NoError(t, Decode(uint(3), &f32))
+	require.Equal(t, float32(3), f32)
+	require.NoError(t, Decode(1.5, &f32))
+	require.Equal(t, float32(1.5), f32)
+
+	var stringer fmt.Stringer
+	require.NoError(t, Decode(MapKind, &stringer))
+	require.Equal(t, MapKind, stringer)
+
+	var b bool
+	require.NoError(t, Decode([]Result{"tr", nil, "ue"}, &b))
+	require.True(t, b)
+
+	var values []int
+	require.NoError(t, Decode(Captured{Name: "values", Value: []Result{"1", 2, int64(3)}}, &values))
+	require.Equal(t, []int{1, 2, 3}, values)
+
+	var anything Result
+	require.NoError(t, Decode([]Result{"a", 1}, &anything))
+	require.Equal(t, []Result{"a", 1}, anything)
+
+	var nested struct {
+		Point *struct {
+			X int `parc:"x"`
+			Y int `parc:"y"`
+		} `parc:"point"`
+	}
+	require.NoError(t, Decode([]Result{Captured{Name: "point", Value: []Result{Captured{Name: "x", Value: 1}, "@", Captured{Name: "y", Value: 2}}}}, &nested))
+	require.Equal(t, 1, nested.Point.X)
+	require.Equal(t, 2, nested.Point.Y)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	input := "GET /index:99999 1.1\nHost: example.com\n"
+	results := requestParser().Parse(&input).Results
+
+	var actual request
+	err := Decode(results, &actual)
+	require.EqualError(t, err, `Decode: field Port [11:16]: strconv.ParseUint: parsing "99999": value out of range`)
+	var decodeError *DecodeError
+	require.True(t, errors.As(err, &decodeError))
+	require.Equal(t, "Port", decodeError.Field)
+	require.Equal(t, "99999", decodeError.Span.Text(input))
+	require.True(t, errors.Is(err, strconv.ErrRange))
+
+	var headers struct {
+		Headers []struct {
+			Name  string `parc:"name"`
+			Value int    `parc:"value"`
+		} `parc:"header"`
+	}
+	err = Decode(results, &headers)
+	require.EqualError(t, err, `Decode: field Headers[0].Value [27:38]: strconv.ParseInt: parsing "example.com": invalid syntax`)
+
+	var version struct {
+		Version protocolVersion `parc:"version"`
+	}
+	err = Decode([]Result{Captured{Name: "version", Value: "x", Span: Span{Start: 4, End: 5}}}, &version)
+	require.EqualError(t, err, `Decode: field Version [4:5]: invalid version "x"`)
+
+	var missing struct {
+		Method string `parc:"method"`
+		Body   string `parc:"body"`
+	}
+	err = Decode(Captured{Name: "request", Value: results, Span: Span{Start: 0, End: 39}}, &missing)
+	require.EqualError(t, err, `Decode: field Body [0:39]: missing capture "body"`)
+
+	var n uint8
+	require.EqualError(t, Decode(Captured{Value: 300, Span: Span{Start: 1, End: 4}}, &n), "Decode: [1:4]: 300 overflows uint8")
+	require.EqualError(t, Decode([]Result{1}, &n), "Decode: [0:0]: cannot decode []parc.Result into uint8")
+	require.EqualError(t, Decode(uint(300), &n), "Decode: [0:0]: 300 overflows uint8")
+	var i64 int64
+	require.EqualError(t, Decode(uint64(math.MaxUint64), &i64), "Decode: [0:0]: 18446744073709551615 overflows int64")
+	var f32 float32
+	require.EqualError(t, Decode(1e300, &f32), "Decode: [0:0]: 1e+300 overflows float32")
+	var stringer fmt.Stringer
+	require.EqualError(t, Decode("text", &stringer), "Decode: [0:0]: cannot decode string into fmt.Stringer")
+	require.EqualError(t, Decode(1, n), "Decode: target must be a non-nil pointer")
+}
@@ -0,0 +1,475 @@
+package parc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severity is the severity of a diagnostic
+type Severity int
+
+const (
+	// SeverityError is the severity of the failures of the parsing
+	SeverityError Severity = iota
+	// SeverityWarning is the severity of the problems, that do not prevent the parsing
+	SeverityWarning
+	// SeverityNote is the severity of the informational diagnostics
+	SeverityNote
+)
+
+// severityNames holds the printable names of the severities, that are also the SARIF levels
+var severityNames = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+}
+
+// String returns with the name of the severity
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// MarshalText returns with the name of the severity, so the severity is encoded as a string in JSON
+func (s Severity) MarshalText() ([]byte, error) {
+	if _, ok := severityNames[s]; !ok {
+		return nil, fmt.Errorf("Severity: unknown severity %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// DefaultRule is the rule of the diagnostics, whose failure is not inside of a parser named by the As method
+const DefaultRule = "syntax"
+
+// RelatedLocation is a location of the input, that is related to a diagnostic
+type RelatedLocation struct {
+	// Message describes the relation of the location to the diagnostic, e.g. `in statement`
+	Message string
+	// Span is the related range of the input
+	Span Span
+}
+
+// Diagnostic describes the failure of a parsing in a form, that can be rendered with the snippet of the input
+type Diagnostic struct {
+	// Severity is the severity of the diagnostic. Its default value is SeverityError.
+	Severity Severity
+	// Rule identifies the kind of the failure. It is derived from the name of the innermost parser named by the As method,
+	// that was running at the failure, or it is DefaultRule.
+	Rule string
+	// Message is the description of the failure, e.g. `unexpected "}"`
+	Message string
+	// Span is the failing range of the input
+	Span Span
+	// Expected holds the descriptions of the literals and the named parsers, that could continue the input at the start of the span
+	Expected []string
+	// Notes are additional remarks printed after the snippet of the input
+	Notes []string
+	// Related holds the starting positions of the named parsers, that were running at the failure, from the innermost one.
+	// The diagnostics of the DiagnoseAll method also hold the locations of the previous and the next errors of the input.
+	Related []RelatedLocation
+}
+
+// Diagnose runs the parser on the input string like Parse, but it also tracks the failures of the parsers.
+// It returns with the final state of the parser, and the diagnostic of the failure, or nil if the parsing succeeded.
+//
+// Only one error is reported per input, because the parsing stops at the first failure, that could not be backtracked.
+// The errors of the alternatives tried and abandoned before are not reported separately, they only contribute
+// to the expected parsers of the diagnostic. Use the DiagnoseAll method to recover from the errors and report all of them.
+func (p *Parser) Diagnose(inputString *string) (ParserState, *Diagnostic) {
+	return p.diagnoseAt(inputString, 0)
+}
+
+// DiagnoseAll runs the parser repeatedly on the input string until the end of the input, and recovers from the errors.
+// The parser is the parser of an item of the input, e.g. a statement or a line.
+// After a failure it records the diagnostic of the failure, skips the input to the end of the next match of the sync parser,
+// e.g. a Newline or a Char(";"), and continues the parsing there. A parsing, that succeeds without consuming input, is also a failure.
+// The parsing stops after the last failure, if the sync parser does not match after it.
+//
+// It returns with the results of the successful parsings, and the diagnostics of the failures in the order of the input.
+// The locations of the previous and the next errors are added to the related locations of the diagnostics.
+func (p *Parser) DiagnoseAll(inputString *string, sync *Parser) ([]Result, []*Diagnostic) {
+	results := []Result{}
+	diagnostics := []*Diagnostic{}
+	for index := 0; index >= 0 && index < len(*inputString); {
+		newState, diagnostic := p.diagnoseAt(inputString, index)
+		if diagnostic == nil && newState.Index > index {
+			results = append(results, newState.Results)
+			index = newState.Index
+			continue
+		}
+		if diagnostic == nil {
+			diagnostic = NewDiagnostic(UpdateParserError(newState, fmt.Errorf("%s: no input consumed", p.Name())))
+		}
+		diagnostics = append(diagnostics, diagnostic)
+		index = syncEnd(inputString, sync, max(index, diagnostic.Span.Start), index)
+	}
+
+	for i, diagnostic := range diagnostics {
+		if i > 0 {
+			previous := diagnostics[i-1]
+			diagnostic.Related = append(diagnostic.Related, RelatedLocation{Message: "previous error: " + previous.Message, Span: previous.Span})
+		}
+		if i < len(diagnostics)-1 {
+			next := diagnostics[i+1]
+			diagnostic.Related = append(diagnostic.Related, RelatedLocation{Message: "next error: " + next.Message, Span: next.Span})
+		}
+	}
+	return results, diagnostics
+}
+
+// diagnoseAt runs the parser from the index of the input string, and tracks the failures of the parsers
+func (p *Parser) diagnoseAt(inputString *string, index int) (ParserState, *Diagnostic) {
+	initialState := NewParserState(inputString, Result(nil), index, nil)
+	initialState.failures = newFailureTracker()
+	newState := p.ParserFun(initialState)
+	return newState, NewDiagnostic(newState)
+}
+
+// syncEnd returns with the end of the first match of the sync parser starting from the from index,
+// that ends after the index, or -1 if there is no such match
+func syncEnd(inputString *string, sync *Parser, from, index int) int {
+	input := *inputString
+	for from < len(input) {
+		newState := sync.ParserFun(NewParserState(inputString, Result(nil), from, nil))
+		if !newState.IsError && newState.Index > index {
+			return newState.Index
+		}
+		_, size := utf8.DecodeRuneInString(input[from:])
+		from += size
+	}
+	return -1
+}
+
+// NewDiagnostic returns with the diagnostic of the failed parser state, or nil if the state is not failed.
+//
+// If the state was produced by the Diagnose method, the diagnostic describes the furthest position of the input,
+// where a parser failed, together with the parsers, that were expected there. The expected parsers are the primitive
+// parsers, e.g. the Str and Char parsers, and the parsers named by the As method, that failed without consuming input.
+// The errors of the TryMap functions, that reached this position, e.g. the range errors of the number literals,
+// take precedence over the expected parsers.
+//
+// Otherwise the diagnostic holds the error message of the state at the index of the state.
+func NewDiagnostic(state ParserState) *Diagnostic {
+	if !state.IsError {
+		return nil
+	}
+	failures := state.failures
+	if failures == nil || failures.furthest < 0 && failures.err == nil {
+		return &Diagnostic{Rule: DefaultRule, Message: state.Err.Error(), Span: Span{Start: state.Index, End: state.Index}}
+	}
+	if failures.err != nil && failures.errSpan.End >= failures.furthest {
+		return newTrackedDiagnostic(failures.err.Error(), failures.errSpan, failures.errContext)
+	}
+
+	input := *state.inputString
+	span := Span{Start: failures.furthest, End: tokenEnd(input, failures.furthest)}
+	message := "unexpected end of input"
+	if span.End > span.Start {
+		message = "unexpected " + strconv.Quote(span.Text(input))
+	}
+	diagnostic := newTrackedDiagnostic(message, span, failures.context)
+	for _, parser := range failures.expected {
+		diagnostic.Expected = append(diagnostic.Expected, describeParser(parser))
+	}
+	return diagnostic
+}
+
+// newTrackedDiagnostic creates a diagnostic of a failure inside of the named parsers of the context
+func newTrackedDiagnostic(message string, span Span, context []ruleFrame) *Diagnostic {
+	diagnostic := &Diagnostic{Rule: DefaultRule, Message: message, Span: span}
+	for i := len(context) - 1; i >= 0; i-- {
+		frame := context[i]
+		if i == len(context)-1 {
+			diagnostic.Rule = ruleID(frame.parser.Name())
+		}
+		diagnostic.Related = append(diagnostic.Related, RelatedLocation{Message: "in " + frame.parser.Name(), Span: Span{Start: frame.index, End: frame.index}})
+	}
+	return diagnostic
+}
+
+// ruleID derives a rule identifier from the name of a parser, by replacing the characters other than the letters,
+// digits, `_`, `.` and `-` with `-` characters, e.g. `key value` becomes `key-value`
+func ruleID(name string) string {
+	id := strings.Trim(strings.Map(func(r rune) rune {
+		if isWordRune(r) || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name), "-")
+	if id == "" {
+		return DefaultRule
+	}
+	return id
+}
+
+// String returns with the message and the expected parsers of the diagnostic, e.g. `unexpected "}", expected Digits or "{"`
+func (d *Diagnostic) String() string {
+	if len(d.Expected) == 0 {
+		return d.Message
+	}
+	return d.Message + ", " + d.expectedText()
+}
+
+// tokenEnd returns with the end of the token starting at the index, that is a word of letters and digits, or a single character
+func tokenEnd(input string, index int) int {
+	if index >= len(input) {
+		return index
+	}
+	r, size := utf8.DecodeRuneInString(input[index:])
+	if !isWordRune(r) {
+		return index + size
+	}
+	end := index
+	for end < len(input) {
+		r, size := utf8.DecodeRuneInString(input[end:])
+		if !isWordRune(r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
+
+// isWordRune tests if the rune may be part of a word token
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// describeParser returns with the description of the parser in the set of the expected parsers
+func describeParser(parser *Parser) string {
+	spec := parser.Spec()
+	switch {
+	case parser.named:
+		return parser.Name()
+	case spec.Kind == StrKind || spec.Kind == CharKind:
+		return strconv.Quote(spec.Literal)
+	case spec.Kind == EndOfInputKind:
+		return "end of input"
+	case spec.Kind == RegExpKind:
+		return "/" + spec.Literal + "/"
+	}
+	return parser.Name()
+}
+
+// expectedText returns with the expected parsers in a readable list, e.g. `expected "(" or Letters`
+func (d *Diagnostic) expectedText() string {
+	switch len(d.Expected) {
+	case 0:
+		return ""
+	case 1:
+		return "expected " + d.Expected[0]
+	case 2:
+		return "expected " + d.Expected[0] + " or " + d.Expected[1]
+	}
+	return "expected one of " + strings.Join(d.Expected, ", ")
+}
+
+// failureTracker collects the failures of the parsers during the parsing started by the Diagnose method
+type failureTracker struct {
+	// furthest is the furthest index, where a parser failed, or -1 if no parser failed
+	furthest int
+	// expected holds the parsers, that failed at the furthest index
+	expected []*Parser
+	// context holds the named parsers, that were running at the first failure at the furthest index
+	context []ruleFrame
+	// err is the furthest error of the TryMap functions
+	err error
+	// errSpan is the range of the input, that the failed TryMap function received
+	errSpan Span
+	// errContext holds the named parsers, that were running at the error of the TryMap function
+	errContext []ruleFrame
+	// running holds the named parsers, that are running, from the outermost one
+	running []ruleFrame
+}
+
+// ruleFrame is a named parser running from the index
+type ruleFrame struct {
+	parser *Parser
+	index  int
+}
+
+// failureMark is the state of the failure tracker at the start of a parser
+type failureMark struct {
+	furthest int
+	count    int
+}
+
+// newFailureTracker creates a new failureTracker
+func newFailureTracker() *failureTracker {
+	return &failureTracker{furthest: -1}
+}
+
+// enter registers the start of the parser at the index, and returns with the actual state of the failure tracker
+func (t *failureTracker) enter(parser *Parser, index int) failureMark {
+	if parser.named {
+		t.running = append(t.running, ruleFrame{parser: parser, index: index})
+	}
+	return failureMark{furthest: t.furthest, count: len(t.expected)}
+}
+
+// exit registers the end of the parser, and its failure, if it failed
+func (t *failureTracker) exit(parser *Parser, parserState, newState ParserState, mark failureMark) {
+	if parser.named {
+		t.running = t.running[:len(t.running)-1]
+	}
+	if !parserState.IsError && newState.IsError && !newState.IsIncomplete() {
+		t.record(parser, parserState.Index, mark)
+	}
+}
+
+// record registers the failure of the parser started at the index.
+// The primitive parsers are expected at the index. The named parsers are expected instead of the parsers
+// they expected at the index, if they could not get further.
+func (t *failureTracker) record(parser *Parser, index int, mark failureMark) {
+	switch parser.Spec().Kind {
+	case CustomParserKind, StartOfInputKind, EndOfInputKind, RestKind, CharKind, StrKind, RegExpKind, CondKind, CondMinKind, CondMinMaxKind:
+	default:
+		if !parser.named || t.furthest != index {
+			return
+		}
+		if mark.furthest == index {
+			t.expected = t.expected[:mark.count]
+		} else {
+			t.expected = t.expected[:0]
+			t.context = slices.Clone(t.running)
+		}
+	}
+
+	if index < t.furthest {
+		return
+	}
+	if index > t.furthest {
+		t.furthest = index
+		t.expected = t.expected[:0]
+		t.context = slices.Clone(t.running)
+	}
+	for _, expected := range t.expected {
+		if describeParser(expected) == describeParser(parser) {
+			return
+		}
+	}
+	t.expected = append(t.expected, parser)
+}
+
+// fail registers the error of a TryMap function, that received the range of the input
+func (t *failureTracker) fail(span Span, err error) {
+	if t.err == nil || span.End >= t.errSpan.End {
+		t.err = err
+		t.errSpan = span
+		t.errContext = slices.Clone(t.running)
+	}
+}
+
+// ANSI escape sequences of the colored diagnostics
+const (
+	ansiReset      = "\x1b[0m"
+	ansiBold       = "\x1b[1m"
+	ansiBoldRed    = "\x1b[1;31m"
+	ansiBoldYellow = "\x1b[1;33m"
+	ansiBoldBlue   = "\x1b[1;34m"
+	ansiBoldCyan   = "\x1b[1;36m"
+)
+
+// severityStyles holds the ANSI styles of the severity names and the underlines
+var severityStyles = map[Severity]string{
+	SeverityError:   ansiBoldRed,
+	SeverityWarning: ansiBoldYellow,
+	SeverityNote:    ansiBoldCyan,
+}
+
+// RenderOptions are the options of rendering the diagnostics
+type RenderOptions struct {
+	// File is the name of the input shown in the location line. The location is shown without file name if it is empty.
+	File string
+	// Color switches on the ANSI coloring of the text
+	Color bool
+}
+
+// Render returns with the diagnostic rendered in the style of the compiler error messages, e.g.:
+//
+//	error: unexpected "}"
+//	 --> config.txt:2:10
+//	  |
+//	2 |   listen }
+//	  |          ^ expected Digits or "{"
+//	  = note: the server block is not closed
+//
+// The offending lines of the input are shown with a line number gutter, and the failing range is underlined by carets.
+func (d *Diagnostic) Render(input string, options RenderOptions) string {
+	paint := func(style, text string) string {
+		if !options.Color || text == "" {
+			return text
+		}
+		return style + text + ansiReset
+	}
+
+	startRow, startCol := rowColOf(input, d.Span.Start)
+	endRow, _ := rowColOf(input, max(d.Span.Start, d.Span.End-1))
+	gutterWidth := len(strconv.Itoa(endRow))
+	gutter := func(label string) string {
+		return paint(ansiBoldBlue, fmt.Sprintf("%*s |", gutterWidth, label))
+	}
+
+	var sb strings.Builder
+	style := severityStyles[d.Severity]
+	sb.WriteString(paint(style, d.Severity.String()) + paint(ansiBold, ": "+d.Message) + "\n")
+	location := fmt.Sprintf("%d:%d", startRow, startCol)
+	if options.File != "" {
+		location = options.File + ":" + location
+	}
+	sb.WriteString(strings.Repeat(" ", gutterWidth) + paint(ansiBoldBlue, "-->") + " " + location + "\n")
+	sb.WriteString(gutter("") + "\n")
+
+	lineStart := d.Span.Start - (startCol - 1)
+	for row := startRow; row <= endRow; row++ {
+		lineEnd := strings.IndexByte(input[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(input)
+		} else {
+			lineEnd += lineStart
+		}
+		line := input[lineStart:lineEnd]
+		sb.WriteString(strings.TrimRight(gutter(strconv.Itoa(row))+" "+line, " ") + "\n")
+
+		// The underline covers the part of the span in the line, but at least a single column
+		from, to := 0, len(line)
+		if row == startRow {
+			from = d.Span.Start - lineStart
+		}
+		if row == endRow {
+			to = max(from, min(d.Span.End-lineStart, len(line)))
+		}
+		var underline strings.Builder
+		for _, r := range line[:from] {
+			if r == '\t' {
+				underline.WriteRune('\t')
+			} else {
+				underline.WriteRune(' ')
+			}
+		}
+		underline.WriteString(paint(style, strings.Repeat("^", max(1, utf8.RuneCountInString(line[from:to])))))
+		if row == endRow && len(d.Expected) > 0 {
+			underline.WriteString(" " + paint(style, d.expectedText()))
+		}
+		sb.WriteString(gutter("") + " " + underline.String() + "\n")
+		lineStart = lineEnd + 1
+	}
+
+	notes := slices.Clone(d.Notes)
+	for _, related := range d.Related {
+		row, col := rowColOf(input, related.Span.Start)
+		notes = append(notes, fmt.Sprintf("%s at %d:%d", related.Message, row, col))
+	}
+	for _, note := range notes {
+		sb.WriteString(strings.Repeat(" ", gutterWidth) + " " + paint(ansiBoldBlue, "=") + " " + paint(ansiBold, "note") + ": " + note + "\n")
+	}
+	return sb.String()
+}
+
+// rowColOf returns with the row and column position of the index of the input
+func rowColOf(input string, index int) (row, col int) {
+	return NewParserState(&input, nil, index, nil).IndexRowCol()
+}
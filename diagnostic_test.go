@@ -0,0 +1,171 @@
+package parc
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	parser := configParser()
+
+	input := "server {\n  listen 8080;\n}\n"
+	newState, diagnostic := parser.Diagnose(&input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Nil(t, diagnostic)
+
+	for _, test := range []struct {
+		input    string
+		expected Diagnostic
+	}{
+		{"server {\n  listen }\n}", Diagnostic{Rule: DefaultRule, Message: `unexpected "}"`, Span: Span{Start: 18, End: 19}, Expected: []string{"IntegerLiteral", "Letters", `"{"`}}},
+		{"server {\n  listen 8080", Diagnostic{Rule: DefaultRule, Message: "unexpected end of input", Span: Span{Start: 22, End: 22}, Expected: []string{`";"`}}},
+		{"server {\n  listen 80 port;\n}", Diagnostic{Rule: DefaultRule, Message: `unexpected "port"`, Span: Span{Start: 21, End: 25}, Expected: []string{`";"`}}},
+		{"server {\n  port 99999999999999999999;\n}", Diagnostic{Rule: "IntegerLiteral", Message: "IntegerLiteral: 99999999999999999999 is out of range", Span: Span{Start: 16, End: 36}, Related: []RelatedLocation{{Message: "in IntegerLiteral", Span: Span{Start: 16, End: 16}}}}},
+	} {
+		newState, diagnostic := parser.Diagnose(&test.input)
+		require.True(t, newState.IsError)
+		require.Equal(t, &test.expected, diagnostic, test.input)
+	}
+
+	// Only the first error of the input is reported
+	input = "server {\n  listen }\n  name ;\n}"
+	_, diagnostic = parser.Diagnose(&input)
+	require.Equal(t, `unexpected "}"`, diagnostic.Message)
+	require.Empty(t, diagnostic.Related)
+}
+
+func TestDiagnoseAll(t *testing.T) {
+	statement := SequenceOf(Letters, Str(" = "), Digits, Char(";"), Newline)
+
+	input := "a = 1;\nb = ;\nc = 2;\nd = x;\ne = 3;\n"
+	results, diagnostics := statement.DiagnoseAll(&input, Newline)
+	require.Len(t, results, 3)
+	require.Equal(t, []Result{"e", " = ", "3", ";", "\n"}, results[2])
+	require.Equal(t, []*Diagnostic{
+		{Rule: DefaultRule, Message: `unexpected ";"`, Span: Span{Start: 11, End: 12}, Expected: []string{"Digits"}, Related: []RelatedLocation{
+			{Message: `next error: unexpected "x"`, Span: Span{Start: 24, End: 25}},
+		}},
+		{Rule: DefaultRule, Message: `unexpected "x"`, Span: Span{Start: 24, End: 25}, Expected: []string{"Digits"}, Related: []RelatedLocation{
+			{Message: `previous error: unexpected ";"`, Span: Span{Start: 11, End: 12}},
+		}},
+	}, diagnostics)
+
+	// The parsing stops, if the sync parser does not match after the failure
+	input = "a = 1;\nb = ;"
+	results, diagnostics = statement.DiagnoseAll(&input, Newline)
+	require.Len(t, results, 1)
+	require.Len(t, diagnostics, 1)
+	require.Empty(t, diagnostics[0].Related)
+
+	// The successful parsing without consuming input is a failure too
+	input = "a1;b2"
+	results, diagnostics = ZeroOrMore(Letters).DiagnoseAll(&input, Char(";"))
+	require.Equal(t, []Result{[]Result{"a"}, []Result{"b"}}, results)
+	require.Len(t, diagnostics, 2)
+	require.Equal(t, `unexpected "1"`, diagnostics[0].Message)
+	require.Equal(t, `unexpected "2"`, diagnostics[1].Message)
+
+	nothing := NewParser("nothing", func(parserState ParserState) ParserState { return parserState })
+	_, diagnostics = nothing.DiagnoseAll(&input, Char(";"))
+	require.Len(t, diagnostics, 2)
+	require.Equal(t, "1:4: nothing: no input consumed", diagnostics[1].Message)
+
+	results, diagnostics = statement.DiagnoseAll(new(string), Newline)
+	require.Empty(t, results)
+	require.Empty(t, diagnostics)
+}
+
+func TestDiagnose_NamedParsers(t *testing.T) {
+	value := Choice(Str("true"), Str("false"), SequenceOf(Char("["), Digits, Char("]"))).As("value")
+	parser := SequenceOf(Letters, Char("="), value, Choice(Char(";"), EndOfInput()))
+
+	input := "a=tru"
+	_, diagnostic := parser.Diagnose(&input)
+	require.Equal(t, `unexpected "tru"`, diagnostic.Message)
+	require.Equal(t, []string{"value"}, diagnostic.Expected)
+
+	// The named parser is not expected, if it could get further
+	input = "a=[1"
+	_, diagnostic = parser.Diagnose(&input)
+	require.Equal(t, "unexpected end of input", diagnostic.Message)
+	require.Equal(t, []string{`"]"`}, diagnostic.Expected)
+
+	input = "a=true "
+	_, diagnostic = parser.Diagnose(&input)
+	require.Equal(t, `unexpected " "`, diagnostic.Message)
+	require.Equal(t, []string{`";"`, "end of input"}, diagnostic.Expected)
+}
+
+func TestNewDiagnostic(t *testing.T) {
+	input := "ab"
+	newState := SequenceOf(Letters, Digits).Parse(&input)
+	require.Equal(t, &Diagnostic{Rule: DefaultRule, Message: newState.Err.Error(), Span: Span{Start: 0, End: 0}}, NewDiagnostic(newState))
+
+	newState = Letters.Parse(&input)
+	require.Nil(t, NewDiagnostic(newState))
+}
+
+func TestDiagnostic_Render(t *testing.T) {
+	input := "server {\n  listen }\n}"
+	_, diagnostic := configParser().Diagnose(&input)
+	diagnostic.Notes = append(diagnostic.Notes, "the port is a number")
+	require.Equal(t, `error: unexpected "}"
+ --> server.conf:2:10
+  |
+2 |   listen }
+  |          ^ expected one of IntegerLiteral, Letters, "{"
+  = note: the port is a number
+`, diagnostic.Render(input, RenderOptions{File: "server.conf"}))
+
+	require.Equal(t, "\x1b[1;31merror\x1b[0m\x1b[1m: unexpected \"}\"\x1b[0m\n"+
+		" \x1b[1;34m-->\x1b[0m 2:10\n"+
+		"\x1b[1;34m  |\x1b[0m\n"+
+		"\x1b[1;34m2 |\x1b[0m   listen }\n"+
+		"\x1b[1;34m  |\x1b[0m          \x1b[1;31m^\x1b[0m \x1b[1;31mexpected one of IntegerLiteral, Letters, \"{\"\x1b[0m\n"+
+		"  \x1b[1;34m=\x1b[0m \x1b[1mnote\x1b[0m: the port is a number\n",
+		diagnostic.Render(input, RenderOptions{Color: true}))
+
+	// The underline spans multiple lines, and it is aligned to the tabs
+	input = "1\n2\n3\n4\n5\n6\n7\n8\n\tkey = \"a\n\tb\" x"
+	diagnostic = &Diagnostic{Message: "unterminated string", Span: Span{Start: 23, End: 29}}
+	require.Equal(t, `error: unterminated string
+  --> 9:8
+   |
+ 9 | 	key = "a
+   | 	      ^^
+10 | 	b" x
+   | ^^^
+`, diagnostic.Render(input, RenderOptions{}))
+
+	// The related locations are shown as notes
+	value := Choice(Str("true"), SequenceOf(Char("["), Digits, Char("]"))).As("list value")
+	input = "a=[1"
+	_, diagnostic = SequenceOf(Letters, Char("="), value).As("setting").Diagnose(&input)
+	require.Equal(t, "list-value", diagnostic.Rule)
+	require.Equal(t, `error: unexpected end of input
+ --> 1:5
+  |
+1 | a=[1
+  |     ^ expected "]"
+  = note: in list value at 1:3
+  = note: in setting at 1:1
+`, diagnostic.Render(input, RenderOptions{}))
+
+	diagnostic = &Diagnostic{Severity: SeverityWarning, Message: "deprecated", Span: Span{Start: 0, End: 1}}
+	require.Equal(t, "\x1b[1;33mwarning\x1b[0m\x1b[1m: deprecated\x1b[0m\n"+
+		" \x1b[1;34m-->\x1b[0m 1:1\n"+
+		"\x1b[1;34m  |\x1b[0m\n"+
+		"\x1b[1;34m1 |\x1b[0m a=[1\n"+
+		"\x1b[1;34m  |\x1b[0m \x1b[1;33m^\x1b[0m\n",
+		diagnostic.Render(input, RenderOptions{Color: true}))
+
+	// The end of the input is underlined by a single caret
+	input = "a\n"
+	diagnostic = &Diagnostic{Message: "unexpected end of input", Span: Span{Start: 2, End: 2}, Expected: []string{`"b"`}}
+	require.Equal(t, `error: unexpected end of input
+ --> 2:1
+  |
+2 |
+  | ^ expected "b"
+`, diagnostic.Render(input, RenderOptions{}))
+}
@@ -0,0 +1,278 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tombenke/parc"
+)
+
+// AccessLogRecord is a line of a web server access log
+type AccessLogRecord struct {
+	// RemoteAddr is the address of the client ($remote_addr)
+	RemoteAddr string
+	// RemoteUser is the name of the authenticated user ($remote_user)
+	RemoteUser string
+	// Time is the time of the request ($time_local or $time_iso8601)
+	Time time.Time
+	// Request is the request line ($request)
+	Request string
+	// Method is the method of the request line
+	Method string
+	// Target is the request target of the request line
+	Target string
+	// Protocol is the protocol version of the request line
+	Protocol string
+	// Status is the status code of the response ($status)
+	Status int
+	// BytesSent is the size of the response body ($body_bytes_sent) or of the whole response ($bytes_sent)
+	BytesSent int64
+	// Referer is the value of the Referer header field ($http_referer)
+	Referer string
+	// UserAgent is the value of the User-Agent header field ($http_user_agent)
+	UserAgent string
+	// Fields holds the raw value of every variable of the log format, including the ones without typed field
+	Fields map[string]string
+}
+
+var (
+	// CommonLog is a parser of a line in the Common Log Format of Apache and Nginx,
+	// e.g. `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`.
+	// The result is an AccessLogRecord value.
+	CommonLog = mustCompileLogFormat(`$remote_addr $ident $remote_user [$time_local] "$request" $status $body_bytes_sent`)
+
+	// CombinedLog is a parser of a line in the Combined Log Format of Apache and Nginx, that is the Common Log Format
+	// followed by the quoted referer and user agent. The result is an AccessLogRecord value.
+	CombinedLog = mustCompileLogFormat(`$remote_addr $ident $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent"`)
+)
+
+// CompileLogFormat creates a parser of access log lines from an Nginx style `log_format` string,
+// e.g. `$remote_addr [$time_local] "$request" $status`. The variables are written as `$name` or `${name}`,
+// and they must be separated by literal text. The value of a variable lasts until the first character of the next literal,
+// or until the end of the line. The values between double quotes may contain `\"`, `\\` and `\xHH` escape sequences.
+// The result of the parser is an AccessLogRecord value, where the `-` values of the typed fields mean missing values.
+func CompileLogFormat(format string) (*parc.Parser, error) {
+	state := logFormat.Parse(&format)
+	if state.IsError {
+		return nil, syntaxErrorOf(state)
+	}
+	items := state.Results.([]parc.Result)
+
+	parsers := make([]*parc.Parser, len(items))
+	for i, item := range items {
+		switch item := item.(type) {
+		case string:
+			parsers[i] = expected("log", item)
+		case logVariable:
+			stop, quoted := '\n', false
+			if i+1 < len(items) {
+				next, ok := items[i+1].(string)
+				if !ok {
+					return nil, fmt.Errorf("log: variables $%s and $%s are not separated", item, items[i+1].(logVariable))
+				}
+				stop, _ = utf8.DecodeRuneInString(next)
+				if i > 0 {
+					previous, _ := items[i-1].(string)
+					quoted = stop == '"' && strings.HasSuffix(previous, `"`)
+				}
+			}
+			parsers[i] = logValue(item, stop, quoted)
+		}
+	}
+	return parc.NewParser("log line", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		record := AccessLogRecord{Fields: map[string]string{}}
+		nextState := parserState
+		for _, parser := range parsers {
+			valueState := parser.ParserFun(nextState)
+			if valueState.IsError {
+				return valueState
+			}
+			if value, ok := valueState.Results.(logValueResult); ok {
+				if err := record.set(string(value.name), value.value); err != nil {
+					return parc.UpdateParserError(nextState, err)
+				}
+			}
+			nextState = valueState
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, record)
+	}).As("log line"), nil
+}
+
+// ParseAccessLog parses the lines of an access log by the line parser, e.g. CombinedLog. The empty lines are skipped.
+func ParseAccessLog(input string, line *parc.Parser) ([]AccessLogRecord, error) {
+	records := []AccessLogRecord{}
+	lineWithEnd := sequence(line, lineEnd.ErrorMap(func(state parc.ParserState) error {
+		r, _ := state.NextRune()
+		return fmt.Errorf("log: unexpected character %q", r)
+	})).Map(first)
+	emptyLine := parc.Choice(parc.Newline, parc.Crlf)
+	state := parc.NewParserState(&input, nil, 0, nil)
+	for !state.AtTheEnd() {
+		if emptyState := emptyLine.ParserFun(state); !emptyState.IsError {
+			state = emptyState
+			continue
+		}
+		state = lineWithEnd.ParserFun(state)
+		if state.IsError {
+			return records, syntaxErrorOf(state)
+		}
+		records = append(records, state.Results.(AccessLogRecord))
+	}
+	return records, nil
+}
+
+// mustCompileLogFormat compiles the log format, and panics if it is invalid
+func mustCompileLogFormat(format string) *parc.Parser {
+	parser, err := CompileLogFormat(format)
+	if err != nil {
+		panic(err)
+	}
+	return parser
+}
+
+// set assigns the value of the variable to the record
+func (r *AccessLogRecord) set(name, value string) error {
+	r.Fields[name] = value
+	if value == "-" {
+		return nil
+	}
+	switch name {
+	case "remote_addr":
+		r.RemoteAddr = value
+	case "remote_user":
+		r.RemoteUser = value
+	case "time_local":
+		t, err := time.Parse("02/Jan/2006:15:04:05 -0700", value)
+		if err != nil {
+			return fmt.Errorf("log: invalid time %q", value)
+		}
+		r.Time = t
+	case "time_iso8601":
+		t, err := ParseRFC3339(value)
+		if err != nil {
+			return fmt.Errorf("log: invalid time %q", value)
+		}
+		r.Time = t
+	case "request":
+		r.Request = value
+		if parts := strings.Split(value, " "); len(parts) == 3 {
+			r.Method, r.Target, r.Protocol = parts[0], parts[1], parts[2]
+		}
+	case "status":
+		status, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("log: invalid status %q", value)
+		}
+		r.Status = status
+	case "body_bytes_sent", "bytes_sent":
+		bytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("log: invalid size %q", value)
+		}
+		r.BytesSent = bytes
+	case "http_referer":
+		r.Referer = value
+	case "http_user_agent":
+		r.UserAgent = value
+	}
+	return nil
+}
+
+// logVariable is a variable of a log format
+type logVariable string
+
+// logValueResult is the result of parsing the value of a variable
+type logValueResult struct {
+	name  logVariable
+	value string
+}
+
+// logFormat is a parser of Nginx style log formats. The result is an array of literals and logVariable values.
+var logFormat = buildLogFormatParser()
+
+// buildLogFormatParser creates the parser of log formats
+func buildLogFormatParser() *parc.Parser {
+	isNameChar := func(r rune) bool { return parc.IsAlphaNumeric(r) || r == '_' }
+	name := parc.CondMin(isNameChar, 1).Map(func(result parc.Result) parc.Result { return logVariable(result.(string)) })
+	bracedName := sequence(parc.Char("{"), name, expected("log format", "}")).Map(second)
+	// A $ sign that is not followed by a variable name is a literal
+	variable := sequence(parc.Char("$"), dispatch("variable", map[rune]*parc.Parser{'{': bracedName}, parc.Optional(name))).Map(func(result parc.Result) parc.Result {
+		if variable := second(result); variable != nil {
+			return variable
+		}
+		return "$"
+	})
+	literal := parc.CondMin(func(r rune) bool { return r != '$' }, 1)
+	item := dispatch("log format item", map[rune]*parc.Parser{'$': variable}, literal)
+	// The items can only stop before the end of the format at an invalid braced variable
+	end := parc.EndOfInput().ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("log format: invalid variable")
+	})
+	return sequence(parc.ZeroOrMore(item), end).Map(func(result parc.Result) parc.Result {
+		// The adjacent literals are merged
+		items := []parc.Result{}
+		for _, item := range first(result).([]parc.Result) {
+			if s, ok := item.(string); ok && len(items) > 0 {
+				if previous, ok := items[len(items)-1].(string); ok {
+					items[len(items)-1] = previous + s
+					continue
+				}
+			}
+			items = append(items, item)
+		}
+		return items
+	})
+}
+
+// logValue returns a parser of the value of the variable, that lasts until the stop rune or the end of the line.
+// The quoted values may contain escape sequences.
+func logValue(name logVariable, stop rune, quoted bool) *parc.Parser {
+	plain := parc.CondMin(func(r rune) bool { return r != stop && isNotLineEnd(r) }, 0)
+	value := plain
+	if quoted {
+		escape := sequence(parc.Char(`\`), parc.AnyChar).Map(func(result parc.Result) parc.Result {
+			return `\` + second(result).(string)
+		})
+		unescaped := parc.CondMin(func(r rune) bool { return r != stop && r != '\\' && isNotLineEnd(r) }, 1)
+		value = parc.ZeroOrMore(parc.Choice(unescaped, escape)).Map(func(result parc.Result) parc.Result {
+			return unescapeLogValue(parc.JoinStrResults(result).(string))
+		})
+	}
+	return value.Map(func(result parc.Result) parc.Result {
+		return logValueResult{name: name, value: result.(string)}
+	}).As("$" + string(name))
+}
+
+// unescapeLogValue resolves the `\"`, `\\` and `\xHH` escape sequences of a quoted value.
+// The quoted values are not parsed by parc.QuotedString, because their closing quote is a part of the literal text
+// of the log format, and like in the logs of nginx, the unknown escape sequences are kept as they are.
+func unescapeLogValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch next := s[i+1]; {
+			case next == '"' || next == '\\':
+				sb.WriteByte(next)
+				i++
+				continue
+			case next == 'x' && i+3 < len(s):
+				if b, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+					sb.WriteByte(byte(b))
+					i += 3
+					continue
+				}
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,98 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCommonLog(t *testing.T) {
+	input := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`
+	state := CommonLog.Parse(&input)
+	require.False(t, state.IsError)
+	record := state.Results.(AccessLogRecord)
+	require.Equal(t, "127.0.0.1", record.RemoteAddr)
+	require.Equal(t, "frank", record.RemoteUser)
+	require.True(t, time.Date(2000, time.October, 10, 20, 55, 36, 0, time.UTC).Equal(record.Time))
+	require.Equal(t, "GET /apache_pb.gif HTTP/1.0", record.Request)
+	require.Equal(t, "GET", record.Method)
+	require.Equal(t, "/apache_pb.gif", record.Target)
+	require.Equal(t, "HTTP/1.0", record.Protocol)
+	require.Equal(t, 200, record.Status)
+	require.Equal(t, int64(2326), record.BytesSent)
+	require.Equal(t, "-", record.Fields["ident"])
+}
+
+func TestParseAccessLog(t *testing.T) {
+	input := `192.0.2.1 - - [17/Oct/2026:12:00:00 +0200] "GET / HTTP/1.1" 200 612 "-" "curl/8.0"` + "\n" +
+		"\n" +
+		`192.0.2.2 - - [17/Oct/2026:12:00:01 +0200] "POST /api?q=\"x\" HTTP/1.1" 404 - "https://example.com/" "Mozilla/5.0 \x22quoted\x22"` + "\r\n"
+	records, err := ParseAccessLog(input, CombinedLog)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	require.Equal(t, "", records[0].RemoteUser)
+	require.Equal(t, "", records[0].Referer)
+	require.Equal(t, "curl/8.0", records[0].UserAgent)
+
+	require.Equal(t, `POST /api?q="x" HTTP/1.1`, records[1].Request)
+	require.Equal(t, 404, records[1].Status)
+	require.Equal(t, int64(0), records[1].BytesSent)
+	require.Equal(t, "https://example.com/", records[1].Referer)
+	require.Equal(t, `Mozilla/5.0 "quoted"`, records[1].UserAgent)
+}
+
+func TestParseAccessLogErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		row     int
+		col     int
+		message string
+	}{
+		{`192.0.2.1 - - [17/Oct/2026:12:00:00 +0200] "GET / HTTP/1.1" 2x0 612 "-" "curl"`, 1, 61, `log: invalid status "2x0"`},
+		{`192.0.2.1 - - [17/Okt/2026:12:00:00 +0200] "GET / HTTP/1.1" 200 612 "-" "curl"`, 1, 16, `log: invalid time "17/Okt/2026:12:00:00 +0200"`},
+		{"192.0.2.1 - - [17/Oct/2026:12:00:00 +0200] \"GET / HTTP/1.1\" 200 612 \"-\" \"curl\"\n192.0.2.1 - -", 2, 14, `log: expected " ["`},
+		{`192.0.2.1 - - [17/Oct/2026:12:00:00 +0200] "GET / HTTP/1.1" 200 612 "-" "curl" extra`, 1, 79, `log: unexpected character ' '`},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseAccessLog(testCase.input, CombinedLog)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.row, syntaxError.Pos.Row, testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
+
+func TestCompileLogFormat(t *testing.T) {
+	parser, err := CompileLogFormat(`${remote_addr}|$time_iso8601|"$request"|$status|$request_time|$upstream_addr $$`)
+	require.NoError(t, err)
+	input := `10.0.0.1|2026-10-17T12:00:00+02:00|"GET /health HTTP/1.1"|200|0.003|10.0.0.2:8080 $$`
+	state := parser.Parse(&input)
+	require.False(t, state.IsError, state.Err)
+	record := state.Results.(AccessLogRecord)
+	require.Equal(t, "10.0.0.1", record.RemoteAddr)
+	require.True(t, time.Date(2026, time.October, 17, 10, 0, 0, 0, time.UTC).Equal(record.Time))
+	require.Equal(t, "/health", record.Target)
+	require.Equal(t, 200, record.Status)
+	require.Equal(t, "0.003", record.Fields["request_time"])
+	require.Equal(t, "10.0.0.2:8080", record.Fields["upstream_addr"])
+
+	// The values end at the non-ASCII first character of the following literal
+	parser, err = CompileLogFormat(`$remote_addr→$status`)
+	require.NoError(t, err)
+	input = "10.0.0.1→200"
+	state = parser.Parse(&input)
+	require.False(t, state.IsError, state.Err)
+	require.Equal(t, "10.0.0.1", state.Results.(AccessLogRecord).RemoteAddr)
+	require.Equal(t, 200, state.Results.(AccessLogRecord).Status)
+
+	_, err = CompileLogFormat(`$remote_addr$status`)
+	require.EqualError(t, err, "log: variables $remote_addr and $status are not separated")
+
+	_, err = CompileLogFormat(`$remote_addr ${status`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "log format: invalid variable")
+}
@@ -0,0 +1,304 @@
+package formats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tombenke/parc"
+)
+
+// CSVOptions holds the configuration of the CSV parser
+type CSVOptions struct {
+	// Delimiter is the field separator. Its default value is ','.
+	Delimiter rune
+	// Quote is the character that encloses the escaped fields. Its default value is '"'.
+	Quote rune
+	// Header means that the first record holds the names of the fields
+	Header bool
+	// TrimSpace removes the leading and trailing spaces and tabs of the unquoted fields,
+	// and allows spaces and tabs around the quoted fields
+	TrimSpace bool
+	// FieldsPerRecord is the number of expected fields per record.
+	// If it is 0, the number of fields of the first record is expected in every record.
+	// If it is negative, the records may have variable number of fields.
+	FieldsPerRecord int
+}
+
+// TSVOptions returns with the options of parsing tab separated values
+func TSVOptions() CSVOptions {
+	return CSVOptions{Delimiter: '\t'}
+}
+
+// CSVRecord is a record of a CSV input
+type CSVRecord struct {
+	// Row is the line number of the beginning of the record
+	Row int
+	// Fields holds the values of the fields
+	Fields []string
+
+	header []string
+}
+
+// Get returns with the value of the field with the given name, if the input has a header
+func (r CSVRecord) Get(name string) (string, bool) {
+	for i, fieldName := range r.header {
+		if fieldName == name && i < len(r.Fields) {
+			return r.Fields[i], true
+		}
+	}
+	return "", false
+}
+
+// CSVError is the error of parsing a CSV input
+type CSVError struct {
+	// Row is the line number of the error
+	Row int
+	// Col is the column number of the error
+	Col int
+	// Err is the error reported by the parser
+	Err error
+}
+
+// Error returns with the error message, that holds the row and column of the error
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("csv: %d:%d: %v", e.Row, e.Col, e.Err)
+}
+
+// Unwrap returns with the error reported by the parser
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// CSVReader reads the records of a CSV input one by one.
+// The input is read line by line, and only the lines of the actual record are buffered,
+// so the reader can process inputs, that do not fit into the memory.
+// The empty lines are skipped.
+type CSVReader struct {
+	source  *bufio.Reader
+	options CSVOptions
+	record  *parc.Parser
+	row     int
+	header  []string
+	err     error
+}
+
+// NewCSVReader creates a new reader of the CSV input
+func NewCSVReader(input io.Reader, options CSVOptions) *CSVReader {
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	if options.Quote == 0 {
+		options.Quote = '"'
+	}
+	return &CSVReader{
+		source:  bufio.NewReader(input),
+		options: options,
+		record:  CSVRecordParser(options),
+		row:     1,
+	}
+}
+
+// Header returns with the names of the fields, if the Header option is set.
+// It reads the header record if it was not read yet.
+func (r *CSVReader) Header() ([]string, error) {
+	if r.options.Header && r.header == nil && r.err == nil {
+		record, err := r.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		r.header = record.Fields
+	}
+	return r.header, r.err
+}
+
+// Next returns with the next record of the input. It returns io.EOF if there are no more records.
+func (r *CSVReader) Next() (CSVRecord, error) {
+	if _, err := r.Header(); err != nil {
+		return CSVRecord{}, err
+	}
+	record, err := r.readRecord()
+	record.header = r.header
+	return record, err
+}
+
+// ReadAll returns with all the remaining records of the input
+func (r *CSVReader) ReadAll() ([]CSVRecord, error) {
+	records := []CSVRecord{}
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// readRecord reads the lines of the next record from the input, and parses them
+func (r *CSVReader) readRecord() (CSVRecord, error) {
+	if r.err != nil {
+		return CSVRecord{}, r.err
+	}
+
+	var buffer string
+	for {
+		line, err := r.source.ReadString('\n')
+		if err != nil && err != io.EOF {
+			r.err = err
+			return CSVRecord{}, r.err
+		}
+		if buffer == "" && (line == "\n" || line == "\r\n") {
+			r.row++
+			continue
+		}
+		buffer += line
+		if err == io.EOF || !r.inQuotedField(buffer) {
+			break
+		}
+	}
+	if buffer == "" {
+		r.err = io.EOF
+		return CSVRecord{}, r.err
+	}
+
+	row := r.row
+	r.row += strings.Count(buffer, "\n")
+	nextState := r.record.Parse(&buffer)
+	if nextState.IsError {
+		errRow, errCol := nextState.IndexRowCol()
+		err := errors.Unwrap(nextState.Err)
+		if err == nil {
+			err = nextState.Err
+		}
+		r.err = &CSVError{Row: row + errRow - 1, Col: errCol, Err: err}
+		return CSVRecord{}, r.err
+	}
+
+	fields := nextState.Results.([]string)
+	if r.options.FieldsPerRecord == 0 {
+		r.options.FieldsPerRecord = len(fields)
+	}
+	if r.options.FieldsPerRecord > 0 && len(fields) != r.options.FieldsPerRecord {
+		r.err = &CSVError{Row: row, Col: 1,
+			Err: fmt.Errorf("CSV: wrong number of fields %d, expected %d", len(fields), r.options.FieldsPerRecord)}
+		return CSVRecord{}, r.err
+	}
+	return CSVRecord{Row: row, Fields: fields}, nil
+}
+
+// inQuotedField tests if the lines of the record end inside of a quoted field, so the record continues in the next line.
+// A quote opens a quoted field only at the beginning of a field, and a doubled quote inside of a quoted field is an escaped quote.
+func (r *CSVReader) inQuotedField(lines string) bool {
+	inQuotes := false
+	closed := false
+	fieldStart := true
+	for _, c := range lines {
+		switch {
+		case inQuotes:
+			if c == r.options.Quote {
+				inQuotes = false
+				closed = true
+			}
+			continue
+		case c == r.options.Quote && (fieldStart || closed):
+			inQuotes = true
+			fieldStart = false
+		case c == r.options.Delimiter || c == '\n':
+			fieldStart = true
+		case r.options.TrimSpace && fieldStart && (c == ' ' || c == '\t'):
+		default:
+			fieldStart = false
+		}
+		closed = false
+	}
+	return inQuotes
+}
+
+// ParseCSV parses the whole CSV input, and returns with the header, if the Header option is set, and the records
+func ParseCSV(input string, options CSVOptions) ([]string, []CSVRecord, error) {
+	reader := NewCSVReader(strings.NewReader(input), options)
+	header, err := reader.Header()
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	records, err := reader.ReadAll()
+	return header, records, err
+}
+
+// CSVRecordParser returns a parser of a single RFC 4180 record including its line terminator, if there is any.
+// The result is a []string holding the values of the fields.
+// The line terminator may be CRLF or LF, and the last record of the input may have no line terminator.
+func CSVRecordParser(options CSVOptions) *parc.Parser {
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	if options.Quote == 0 {
+		options.Quote = '"'
+	}
+	quote := string(options.Quote)
+	isSpace := func(r rune) bool { return (r == ' ' || r == '\t') && r != options.Delimiter }
+	isTextData := func(r rune) bool {
+		return r != options.Delimiter && r != options.Quote && r != '\r' && r != '\n'
+	}
+	isQuotedData := func(r rune) bool { return r != options.Quote }
+
+	escapedField := parc.SequenceOf(
+		parc.Str(quote),
+		parc.ZeroOrMore(parc.Choice(
+			parc.CondMin(isQuotedData, 1),
+			parc.Str(quote+quote).Map(func(parc.Result) parc.Result { return quote }),
+		)),
+		parc.Str(quote),
+	).Map(func(result parc.Result) parc.Result {
+		return parc.JoinStrResults(second(result))
+	}).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("CSV: unterminated quoted field")
+	}).As("escaped field")
+
+	nonEscapedField := parc.CondMin(isTextData, 0).Map(func(result parc.Result) parc.Result {
+		if options.TrimSpace {
+			return strings.Trim(result.(string), " \t")
+		}
+		return result
+	}).As("non-escaped field")
+
+	field := dispatch("field", map[rune]*parc.Parser{options.Quote: escapedField}, nonEscapedField)
+	if options.TrimSpace {
+		spaces := parc.CondMin(isSpace, 0)
+		field = parc.SequenceOf(spaces, field, spaces).Map(second).As("field")
+	}
+
+	delimiter := parc.Str(string(options.Delimiter))
+	lineEnd := parc.Choice(parc.Newline, parc.Crlf, parc.EndOfInput())
+
+	return parc.NewParser("CSV record", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		fields := []string{}
+		nextState := parserState
+		for {
+			nextState = field.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			fields = append(fields, nextState.Results.(string))
+
+			if delimiterState := delimiter.ParserFun(nextState); !delimiterState.IsError {
+				nextState = delimiterState
+				continue
+			}
+			endState := lineEnd.ParserFun(nextState)
+			if endState.IsError {
+				r, _ := nextState.NextRune()
+				return parc.UpdateParserError(nextState, fmt.Errorf("CSV: unexpected character %q after field", r))
+			}
+			return parc.UpdateParserState(endState, endState.Index, parc.Result(fields))
+		}
+	})
+}
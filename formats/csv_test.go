@@ -0,0 +1,130 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCSVRecordParser(t *testing.T) {
+	parser := CSVRecordParser(CSVOptions{})
+	testCases := map[string][]string{
+		"a,b,c":                    {"a", "b", "c"},
+		"a,b,c\r\nnext":            {"a", "b", "c"},
+		"a,,c\n":                   {"a", "", "c"},
+		`"a,b","c""d",e`:           {"a,b", `c"d`, "e"},
+		"\"multi\nline\",x":        {"multi\nline", "x"},
+		`""`:                       {""},
+		"a,b,":                     {"a", "b", ""},
+		" spaced , fields ":        {" spaced ", " fields "},
+		`"quoted ""and"" escaped"`: {`quoted "and" escaped`},
+	}
+	for input, expected := range testCases {
+		state := parser.Parse(&input)
+		require.False(t, state.IsError, input)
+		require.Equal(t, expected, state.Results, input)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "name,age,city\r\n" +
+		"Alice,30,\"Budapest, Hungary\"\r\n" +
+		"\r\n" +
+		"Bob,25,\"New\nYork\"\r\n" +
+		"\"Carol \"\"CJ\"\"\",41,Paris"
+
+	reader := NewCSVReader(strings.NewReader(input), CSVOptions{Header: true})
+	header, err := reader.Header()
+	require.NoError(t, err)
+	require.Equal(t, []string{"name", "age", "city"}, header)
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, 2, record.Row)
+	require.Equal(t, []string{"Alice", "30", "Budapest, Hungary"}, record.Fields)
+	city, ok := record.Get("city")
+	require.True(t, ok)
+	require.Equal(t, "Budapest, Hungary", city)
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, 4, record.Row)
+	require.Equal(t, []string{"Bob", "25", "New\nYork"}, record.Fields)
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, 6, record.Row)
+	name, _ := record.Get("name")
+	require.Equal(t, `Carol "CJ"`, name)
+
+	_, err = reader.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestCSVReader_Streaming(t *testing.T) {
+	// The records are read one by one, before the rest of the input is read
+	input := io.MultiReader(
+		iotest.OneByteReader(strings.NewReader("id,note\n1,\"multi\nline, \"\"quoted\"\"\"\n\n2,plain\n")),
+		iotest.ErrReader(errors.New("connection reset")),
+	)
+	reader := NewCSVReader(input, CSVOptions{Header: true})
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, CSVRecord{Row: 2, Fields: []string{"1", "multi\nline, \"quoted\""}, header: []string{"id", "note"}}, record)
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, 5, record.Row)
+	require.Equal(t, []string{"2", "plain"}, record.Fields)
+
+	_, err = reader.Next()
+	require.EqualError(t, err, "connection reset")
+	_, err = reader.Next()
+	require.EqualError(t, err, "connection reset")
+}
+
+func TestCSVOptions(t *testing.T) {
+	header, records, err := ParseCSV("a\tb\n1\t'x\ty'\n", CSVOptions{Delimiter: '\t', Quote: '\'', Header: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, header)
+	require.Equal(t, []string{"1", "x\ty"}, records[0].Fields)
+
+	_, records, err = ParseCSV("x\ty\n", TSVOptions())
+	require.NoError(t, err)
+	require.Equal(t, []string{"x", "y"}, records[0].Fields)
+
+	_, records, err = ParseCSV(` a , "b" ,c `+"\n", CSVOptions{TrimSpace: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, records[0].Fields)
+
+	_, records, err = ParseCSV("a,b\nc\n", CSVOptions{FieldsPerRecord: -1})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(records))
+}
+
+func TestCSVErrors(t *testing.T) {
+	testCases := []struct {
+		input string
+		row   int
+		col   int
+	}{
+		{input: "a,b\nc,d\"e\n", row: 2, col: 4},
+		{input: "a,b\n\"c\"x,d\n", row: 2, col: 4},
+		{input: "a,b\nc,\"d\ne,f", row: 2, col: 3},
+		{input: "a,b\nc,d\ne\n", row: 3, col: 1},
+	}
+	for _, tc := range testCases {
+		_, _, err := ParseCSV(tc.input, CSVOptions{})
+		var csvErr *CSVError
+		require.True(t, errors.As(err, &csvErr), tc.input)
+		require.Equal(t, tc.row, csvErr.Row, tc.input)
+		require.Equal(t, tc.col, csvErr.Col, tc.input)
+	}
+
+	_, _, err := ParseCSV("a,b\n\"c\nd\",e\nf,\"g\"h\n", CSVOptions{})
+	require.EqualError(t, err, `csv: 4:6: CSV: unexpected character 'h' after field`)
+}
@@ -0,0 +1,442 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tombenke/parc"
+)
+
+var (
+	// RFC3339 is a parser of an RFC 3339 date-time, e.g. `2026-10-17T12:00:00.123+02:00`.
+	// The seconds and the time offset are mandatory. The result is a time.Time value.
+	// The leap seconds are accepted at 23:59:60 UTC at the end of a month, and they are represented by
+	// the first second of the next day, since time.Time can not represent leap seconds.
+	RFC3339 *parc.Parser
+
+	// ISODateTime is a parser of an ISO 8601 date-time in extended format.
+	// The date may be a calendar date, a week date or an ordinal date,
+	// the seconds and their fraction are optional, and the missing time offset means UTC.
+	// The result is a time.Time value.
+	ISODateTime *parc.Parser
+
+	// ISODate is a parser of an ISO 8601 date in extended format:
+	// calendar date (`2026-10-17`), week date (`2026-W42-6`) or ordinal date (`2026-290`).
+	// The result is a time.Time value at midnight UTC.
+	ISODate *parc.Parser
+
+	// ISODuration is a parser of an ISO 8601 duration, e.g. `P1Y2M10DT2H30M` or `P3W`.
+	// The duration may have a leading minus sign. The result is a Duration value.
+	ISODuration *parc.Parser
+)
+
+func init() {
+	ISODate = buildISODateParser(false)
+	RFC3339 = buildDateTimeParser(true)
+	ISODateTime = buildDateTimeParser(false)
+	ISODuration = buildISODurationParser()
+}
+
+// ParseRFC3339 parses an RFC 3339 date-time
+func ParseRFC3339(input string) (time.Time, error) {
+	return parseTime(RFC3339, input)
+}
+
+// ParseISODateTime parses an ISO 8601 date-time
+func ParseISODateTime(input string) (time.Time, error) {
+	return parseTime(ISODateTime, input)
+}
+
+// ParseISODate parses an ISO 8601 date
+func ParseISODate(input string) (time.Time, error) {
+	return parseTime(ISODate, input)
+}
+
+// ParseISODuration parses an ISO 8601 duration
+func ParseISODuration(input string) (Duration, error) {
+	state := sequence(ISODuration, endOfDateTime).Parse(&input)
+	if state.IsError {
+		return Duration{}, syntaxErrorOf(state)
+	}
+	return first(state.Results).(Duration), nil
+}
+
+// parseTime parses the whole input by the parser, that results in a time.Time value
+func parseTime(parser *parc.Parser, input string) (time.Time, error) {
+	state := sequence(parser, endOfDateTime).Parse(&input)
+	if state.IsError {
+		return time.Time{}, syntaxErrorOf(state)
+	}
+	return first(state.Results).(time.Time), nil
+}
+
+// Duration is an ISO 8601 duration. The components are kept as they are written, without normalization.
+type Duration struct {
+	// Negative is true if the duration has a leading minus sign
+	Negative bool
+	// Years is the number of years
+	Years int
+	// Months is the number of months
+	Months int
+	// Weeks is the number of weeks
+	Weeks int
+	// Days is the number of days
+	Days int
+	// Hours is the number of hours
+	Hours int
+	// Minutes is the number of minutes
+	Minutes int
+	// Seconds is the number of whole seconds
+	Seconds int
+	// Nanoseconds is the fraction of the seconds in nanoseconds
+	Nanoseconds int
+}
+
+// AddTo adds the duration to the time. The years, months, weeks and days are added as calendar units.
+func (d Duration) AddTo(t time.Time) time.Time {
+	sign := 1
+	if d.Negative {
+		sign = -1
+	}
+	clock := time.Duration(d.Hours)*time.Hour + time.Duration(d.Minutes)*time.Minute +
+		time.Duration(d.Seconds)*time.Second + time.Duration(d.Nanoseconds)
+	return t.AddDate(sign*d.Years, sign*d.Months, sign*(d.Weeks*7+d.Days)).Add(time.Duration(sign) * clock)
+}
+
+// String returns with the ISO 8601 format of the duration
+func (d Duration) String() string {
+	var sb strings.Builder
+	if d.Negative {
+		sb.WriteString("-")
+	}
+	sb.WriteString("P")
+	for _, component := range []struct {
+		value      int
+		designator string
+	}{{d.Years, "Y"}, {d.Months, "M"}, {d.Weeks, "W"}, {d.Days, "D"}} {
+		if component.value != 0 {
+			sb.WriteString(strconv.Itoa(component.value) + component.designator)
+		}
+	}
+	if d.Hours != 0 || d.Minutes != 0 || d.Seconds != 0 || d.Nanoseconds != 0 {
+		sb.WriteString("T")
+		if d.Hours != 0 {
+			sb.WriteString(strconv.Itoa(d.Hours) + "H")
+		}
+		if d.Minutes != 0 {
+			sb.WriteString(strconv.Itoa(d.Minutes) + "M")
+		}
+		if d.Seconds != 0 || d.Nanoseconds != 0 {
+			sb.WriteString(strconv.Itoa(d.Seconds))
+			if d.Nanoseconds != 0 {
+				sb.WriteString(strings.TrimRight(fmt.Sprintf(".%09d", d.Nanoseconds), "0"))
+			}
+			sb.WriteString("S")
+		}
+	}
+	if sb.Len() <= 2 {
+		sb.WriteString("T0S")
+	}
+	return sb.String()
+}
+
+// endOfDateTime matches the end of the input, and reports the unexpected character otherwise
+var endOfDateTime = parc.EndOfInput().ErrorMap(func(state parc.ParserState) error {
+	r, _ := state.NextRune()
+	return fmt.Errorf("datetime: unexpected character %q", r)
+})
+
+// empty is a parser that matches the empty string, and results in nil
+var empty = parc.NewParser("empty", func(parserState parc.ParserState) parc.ParserState {
+	if parserState.IsError {
+		return parserState
+	}
+	return parc.UpdateParserState(parserState, parserState.Index, nil)
+})
+
+// dateField returns a parser of a fixed width decimal field, that checks if its value is in the range of [min, max].
+// The result is an int value.
+func dateField(name string, width, min, max int) *parc.Parser {
+	digits := parc.CountMinMax(parc.Digit, width, width).Map(parc.JoinStrResults)
+	return parc.NewParser(name, func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := digits.ParserFun(parserState)
+		if newState.IsError {
+			return parc.UpdateParserError(parserState, fmt.Errorf("datetime: expected %d digits of %s", width, name))
+		}
+		value, _ := strconv.Atoi(newState.Results.(string))
+		if value < min || value > max {
+			return parc.UpdateParserError(parserState, fmt.Errorf("datetime: %s %d is out of range %d..%d", name, value, min, max))
+		}
+		return parc.UpdateParserState(newState, newState.Index, value)
+	})
+}
+
+// daysIn returns with the number of days of the month
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// isoWeeksIn returns with the number of ISO weeks of the year, that is 53 if December 28 is in the 53rd week
+func isoWeeksIn(year int) int {
+	_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return week
+}
+
+// buildISODateParser creates the parser of ISO 8601 dates. The RFC 3339 dates can only be calendar dates.
+func buildISODateParser(calendarOnly bool) *parc.Parser {
+	year := dateField("year", 4, 0, 9999)
+	month := dateField("month", 2, 1, 12)
+	day := dateField("day", 2, 1, 31)
+	week := dateField("week", 2, 1, 53)
+	weekday := dateField("weekday", 1, 1, 7)
+	ordinal := dateField("ordinal day", 3, 1, 366)
+	dash := expected("datetime", "-")
+
+	calendarDate := sequence(month, dash, located(day)).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		return []parc.Result{arr[0], arr[2]}
+	})
+	weekDate := sequence(expected("datetime", "W"), located(week), dash, weekday).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		return []parc.Result{arr[1], arr[3]}
+	})
+	ordinalDate := located(ordinal)
+
+	return parc.NewParser("date", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		nextState := sequence(year, dash).ParserFun(parserState)
+		if nextState.IsError {
+			return nextState
+		}
+		y := first(nextState.Results).(int)
+
+		var date time.Time
+		remaining := nextState.Remaining()
+		switch {
+		case !calendarOnly && strings.HasPrefix(remaining, "W"):
+			nextState = weekDate.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			w := first(nextState.Results).(Located)
+			if w.Value.(int) > isoWeeksIn(y) {
+				return errorAt(nextState, w.Pos, fmt.Errorf("datetime: week %d is out of range 1..%d", w.Value, isoWeeksIn(y)))
+			}
+			// The first week of the year is the week that contains January 4
+			jan4 := time.Date(y, time.January, 4, 0, 0, 0, 0, time.UTC)
+			monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+			date = monday.AddDate(0, 0, (w.Value.(int)-1)*7+second(nextState.Results).(int)-1)
+		case !calendarOnly && len(remaining) >= 3 && strings.IndexFunc(remaining[:3], func(r rune) bool { return !parc.IsDigit(r) }) < 0:
+			nextState = ordinalDate.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			d := nextState.Results.(Located)
+			if daysInYear := time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay(); d.Value.(int) > daysInYear {
+				return errorAt(nextState, d.Pos, fmt.Errorf("datetime: ordinal day %d is out of range 1..%d", d.Value, daysInYear))
+			}
+			date = time.Date(y, time.January, d.Value.(int), 0, 0, 0, 0, time.UTC)
+		default:
+			nextState = calendarDate.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			m := time.Month(first(nextState.Results).(int))
+			d := second(nextState.Results).(Located)
+			if d.Value.(int) > daysIn(y, m) {
+				return errorAt(nextState, d.Pos, fmt.Errorf("datetime: day %d is out of range 1..%d", d.Value, daysIn(y, m)))
+			}
+			date = time.Date(y, m, d.Value.(int), 0, 0, 0, 0, time.UTC)
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, date)
+	}).As("date")
+}
+
+// clock is the result of parsing the time of day
+type clock struct {
+	hour, minute, second, nanosecond int
+	secondPos                        Position
+}
+
+// buildDateTimeParser creates the parser of RFC 3339 date-times if strict is true, otherwise the parser of ISO 8601 date-times
+func buildDateTimeParser(strict bool) *parc.Parser {
+	date := buildISODateParser(strict)
+	separator := parc.Cond(func(r rune) bool { return r == 'T' || r == 't' || r == ' ' }).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("datetime: expected 'T'")
+	})
+	hour := dateField("hour", 2, 0, 23)
+	minute := dateField("minute", 2, 0, 59)
+	colon := expected("datetime", ":")
+
+	fraction := parc.SequenceOf(parc.Choice(parc.Char("."), parc.Char(",")), parc.Digits).Map(func(result parc.Result) parc.Result {
+		digits := second(result).(string)
+		if len(digits) > 9 {
+			digits = digits[:9]
+		}
+		nanoseconds, _ := strconv.Atoi(digits + strings.Repeat("0", 9-len(digits)))
+		return nanoseconds
+	})
+	// RFC 3339 allows only the '.' decimal separator, ISO 8601 allows ',' too
+	fractions := map[rune]*parc.Parser{'.': fraction}
+	if !strict {
+		fractions[','] = fraction
+	}
+	seconds := sequence(located(dateField("second", 2, 0, 60)), dispatch("fraction", fractions, empty))
+	optionalSeconds := sequence(colon, seconds).Map(second)
+	if !strict {
+		optionalSeconds = dispatch("seconds", map[rune]*parc.Parser{':': optionalSeconds}, empty)
+	}
+	timeOfDay := sequence(hour, colon, minute, optionalSeconds).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		c := clock{hour: arr[0].(int), minute: arr[2].(int)}
+		if arr[3] != nil {
+			s := arr[3].([]parc.Result)
+			c.second = s[0].(Located).Value.(int)
+			c.secondPos = s[0].(Located).Pos
+			if s[1] != nil {
+				c.nanosecond = s[1].(int)
+			}
+		}
+		return c
+	})
+
+	utc := parc.Cond(func(r rune) bool { return r == 'Z' || r == 'z' }).Map(func(parc.Result) parc.Result { return time.UTC })
+	numericOffset := sequence(parc.Cond(func(r rune) bool { return r == '+' || r == '-' }), hour, colon, minute).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		offset := arr[1].(int)*3600 + arr[3].(int)*60
+		if arr[0] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone("", offset)
+	})
+	missingOffset := empty.Map(func(parc.Result) parc.Result { return time.UTC })
+	if strict {
+		missingOffset = parc.NewParser("missing offset", func(parserState parc.ParserState) parc.ParserState {
+			return parc.UpdateParserError(parserState, fmt.Errorf("datetime: expected time offset"))
+		})
+	}
+	offset := dispatch("offset", map[rune]*parc.Parser{'Z': utc, 'z': utc, '+': numericOffset, '-': numericOffset}, missingOffset)
+
+	dateTime := sequence(date, separator, timeOfDay, offset)
+	return parc.NewParser("date-time", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		nextState := dateTime.ParserFun(parserState)
+		if nextState.IsError {
+			return nextState
+		}
+		arr := nextState.Results.([]parc.Result)
+		y, m, d := arr[0].(time.Time).Date()
+		c := arr[2].(clock)
+		location := arr[3].(*time.Location)
+
+		if c.second == 60 {
+			// The leap second is inserted after 23:59:59 UTC of the last day of a month
+			lastSecond := time.Date(y, m, d, c.hour, c.minute, 59, 0, location).UTC()
+			if lastSecond.Hour() != 23 || lastSecond.Minute() != 59 || lastSecond.AddDate(0, 0, 1).Day() != 1 {
+				return errorAt(nextState, c.secondPos, fmt.Errorf("datetime: leap second is only allowed at 23:59:60 UTC at the end of a month"))
+			}
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, time.Date(y, m, d, c.hour, c.minute, c.second, c.nanosecond, location))
+	}).As("date-time")
+}
+
+// buildISODurationParser creates the parser of ISO 8601 durations
+func buildISODurationParser() *parc.Parser {
+	number := sequence(parc.Digits, dispatch("fraction", map[rune]*parc.Parser{
+		'.': parc.SequenceOf(parc.Char("."), parc.Digits).Map(second),
+		',': parc.SequenceOf(parc.Char(","), parc.Digits).Map(second),
+	}, empty))
+	designator := parc.Cond(parc.IsAsciiLetter)
+	minus := parc.Char("-")
+	period := parc.Char("P").ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("duration: expected 'P'")
+	})
+	timeDesignator := parc.Char("T")
+
+	// component parses the components of the date or the time part in the order of the designators
+	component := func(parserState parc.ParserState, designators string, values []*int, d *Duration) (parc.ParserState, int) {
+		count := 0
+		nextState := parserState
+		for !nextState.AtTheEnd() {
+			if r, _ := nextState.NextRune(); !parc.IsDigit(r) {
+				break
+			}
+			numberPos := positionOf(nextState)
+			nextState = number.ParserFun(nextState)
+			designatorPos := positionOf(nextState)
+			designatorState := designator.ParserFun(nextState)
+			if designatorState.IsError {
+				return parc.UpdateParserError(nextState, fmt.Errorf("duration: expected designator")), count
+			}
+			idx := strings.Index(designators, designatorState.Results.(string))
+			if idx < 0 {
+				return errorAt(nextState, designatorPos, fmt.Errorf("duration: unexpected designator %q", designatorState.Results)), count
+			}
+			digits := first(nextState.Results).(string)
+			value, err := strconv.Atoi(digits)
+			if err != nil {
+				return errorAt(nextState, numberPos, fmt.Errorf("duration: %s is out of range", digits)), count
+			}
+			*values[idx] = value
+			if fractionDigits, ok := second(nextState.Results).(string); ok {
+				if designators[idx] != 'S' {
+					return errorAt(nextState, numberPos, fmt.Errorf("duration: fraction is only allowed in the seconds")), count
+				}
+				if len(fractionDigits) > 9 {
+					fractionDigits = fractionDigits[:9]
+				}
+				d.Nanoseconds, _ = strconv.Atoi(fractionDigits + strings.Repeat("0", 9-len(fractionDigits)))
+			}
+			// The remaining components must follow this one
+			designators = designators[idx+1:]
+			values = values[idx+1:]
+			nextState = designatorState
+			count++
+		}
+		return nextState, count
+	}
+
+	return parc.NewParser("duration", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		d := Duration{}
+		nextState := parserState
+		if state := minus.ParserFun(nextState); !state.IsError {
+			d.Negative = true
+			nextState = state
+		}
+		nextState = period.ParserFun(nextState)
+		if nextState.IsError {
+			return nextState
+		}
+		nextState, count := component(nextState, "YMWD", []*int{&d.Years, &d.Months, &d.Weeks, &d.Days}, &d)
+		if nextState.IsError {
+			return nextState
+		}
+		if state := timeDesignator.ParserFun(nextState); !state.IsError {
+			var timeCount int
+			nextState, timeCount = component(state, "HMS", []*int{&d.Hours, &d.Minutes, &d.Seconds}, &d)
+			if nextState.IsError {
+				return nextState
+			}
+			if timeCount == 0 {
+				return parc.UpdateParserError(nextState, fmt.Errorf("duration: expected time component"))
+			}
+			count += timeCount
+		}
+		if count == 0 {
+			return parc.UpdateParserError(nextState, fmt.Errorf("duration: expected component"))
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, d)
+	}).As("duration")
+}
@@ -0,0 +1,141 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseRFC3339(t *testing.T) {
+	testCases := map[string]time.Time{
+		"2026-10-17T12:00:00.123+02:00": time.Date(2026, time.October, 17, 12, 0, 0, 123000000, time.FixedZone("", 2*3600)),
+		"1985-04-12T23:20:50.52Z":       time.Date(1985, time.April, 12, 23, 20, 50, 520000000, time.UTC),
+		"1996-12-19t16:39:57-08:00":     time.Date(1996, time.December, 19, 16, 39, 57, 0, time.FixedZone("", -8*3600)),
+		"2024-02-29 00:00:00z":          time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"1937-01-01T12:00:27.87+00:20":  time.Date(1937, time.January, 1, 12, 0, 27, 870000000, time.FixedZone("", 20*60)),
+		// The leap seconds are represented by the first second of the next day
+		"1990-12-31T23:59:60Z":      time.Date(1991, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"1990-12-31T15:59:60-08:00": time.Date(1990, time.December, 31, 16, 0, 0, 0, time.FixedZone("", -8*3600)),
+	}
+	for input, expected := range testCases {
+		value, err := ParseRFC3339(input)
+		require.NoError(t, err, input)
+		require.True(t, expected.Equal(value), "%s: %v", input, value)
+		_, expectedOffset := expected.Zone()
+		_, offset := value.Zone()
+		require.Equal(t, expectedOffset, offset, input)
+	}
+}
+
+func TestParseISODateTime(t *testing.T) {
+	testCases := map[string]time.Time{
+		"2026-10-17T12:00":          time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC),
+		"2026-W42-6T08:15:30,5":     time.Date(2026, time.October, 17, 8, 15, 30, 500000000, time.UTC),
+		"2026-290T23:59:59.999999Z": time.Date(2026, time.October, 17, 23, 59, 59, 999999000, time.UTC),
+	}
+	for input, expected := range testCases {
+		value, err := ParseISODateTime(input)
+		require.NoError(t, err, input)
+		require.True(t, expected.Equal(value), "%s: %v", input, value)
+	}
+}
+
+func TestParseISODate(t *testing.T) {
+	testCases := map[string]time.Time{
+		"2026-10-17": time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+		"2026-W42-6": time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+		"2026-290":   time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+		"2009-W01-1": time.Date(2008, time.December, 29, 0, 0, 0, 0, time.UTC),
+		"2009-W53-7": time.Date(2010, time.January, 3, 0, 0, 0, 0, time.UTC),
+		"2024-366":   time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for input, expected := range testCases {
+		value, err := ParseISODate(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, value, input)
+	}
+}
+
+func TestDateTimeErrors(t *testing.T) {
+	testCases := []struct {
+		parse   func(string) (time.Time, error)
+		input   string
+		col     int
+		message string
+	}{
+		{ParseRFC3339, "2026-13-17T12:00:00Z", 6, "datetime: month 13 is out of range 1..12"},
+		{ParseRFC3339, "2026-02-29T12:00:00Z", 9, "datetime: day 29 is out of range 1..28"},
+		{ParseRFC3339, "2026-10-17T24:00:00Z", 12, "datetime: hour 24 is out of range 0..23"},
+		{ParseRFC3339, "2026-10-17T12:60:00Z", 15, "datetime: minute 60 is out of range 0..59"},
+		{ParseRFC3339, "2026-10-17T12:00:61Z", 18, "datetime: second 61 is out of range 0..60"},
+		{ParseRFC3339, "2026-10-17T12:59:60Z", 18, "datetime: leap second is only allowed at 23:59:60 UTC at the end of a month"},
+		{ParseRFC3339, "2026-10-17T12:00:00", 20, "datetime: expected time offset"},
+		{ParseRFC3339, "2026-10-17T12:00", 17, `datetime: expected ":"`},
+		{ParseRFC3339, "2026-10-17X12:00:00Z", 11, "datetime: expected 'T'"},
+		{ParseRFC3339, "2026-290T12:00:00Z", 6, "datetime: month 29 is out of range 1..12"},
+		{ParseRFC3339, "2026-1-17T12:00:00Z", 6, "datetime: expected 2 digits of month"},
+		{ParseRFC3339, "2026-10-17T12:00:00Z+", 21, "datetime: unexpected character '+'"},
+		{ParseRFC3339, "2026-10-17T12:00:00,5Z", 20, "datetime: expected time offset"},
+		{ParseISODate, "2027-W53-1", 7, "datetime: week 53 is out of range 1..52"},
+		{ParseISODate, "2026-W42-8", 10, "datetime: weekday 8 is out of range 1..7"},
+		{ParseISODate, "2026-366", 6, "datetime: ordinal day 366 is out of range 1..365"},
+	}
+	for _, testCase := range testCases {
+		_, err := testCase.parse(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
+
+func TestParseISODuration(t *testing.T) {
+	testCases := map[string]Duration{
+		"P1Y2M10DT2H30M": {Years: 1, Months: 2, Days: 10, Hours: 2, Minutes: 30},
+		"P3W":            {Weeks: 3},
+		"PT0.5S":         {Nanoseconds: 500000000},
+		"-P1DT1.25S":     {Negative: true, Days: 1, Seconds: 1, Nanoseconds: 250000000},
+		"PT36H":          {Hours: 36},
+		"P0D":            {},
+	}
+	for input, expected := range testCases {
+		value, err := ParseISODuration(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, value, input)
+	}
+
+	d, err := ParseISODuration("P1Y2M10DT2H30M")
+	require.NoError(t, err)
+	require.Equal(t, "P1Y2M10DT2H30M", d.String())
+	require.Equal(t, time.Date(2027, time.December, 27, 14, 30, 0, 0, time.UTC),
+		d.AddTo(time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)))
+	require.Equal(t, "PT0S", Duration{}.String())
+	require.Equal(t, "-P1DT1.25S", Duration{Negative: true, Days: 1, Seconds: 1, Nanoseconds: 250000000}.String())
+}
+
+func TestParseISODurationErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		col     int
+		message string
+	}{
+		{"1Y", 1, "duration: expected 'P'"},
+		{"P", 2, "duration: expected component"},
+		{"P1DT", 5, "duration: expected time component"},
+		{"P1D2Y", 5, `duration: unexpected designator "Y"`},
+		{"PT1D", 4, `duration: unexpected designator "D"`},
+		{"P1.5Y", 2, "duration: fraction is only allowed in the seconds"},
+		{"P1", 3, "duration: expected designator"},
+		{"P99999999999999999999D", 2, "duration: 99999999999999999999 is out of range"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseISODuration(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
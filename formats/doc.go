@@ -0,0 +1,5 @@
+// Package formats holds ready-made parsers of widely used data formats, built from the parc combinators.
+//
+// Besides being usable on their own, the parsers are also reference examples of how to build
+// bigger grammars with the parc package, and most of them can be used as building blocks of other grammars.
+package formats
@@ -0,0 +1,245 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tombenke/parc"
+)
+
+// DotenvFile is the content of a .env file. The entries are kept in the order of the input.
+type DotenvFile struct {
+	// Entries holds the variable assignments of the file
+	Entries []DotenvEntry
+}
+
+// DotenvEntry is a variable assignment of a .env file
+type DotenvEntry struct {
+	// Key is the name of the variable
+	Key string
+	// Value is the value of the variable after the interpolation of the referenced variables
+	Value string
+	// Export is true if the assignment has an `export` prefix
+	Export bool
+	// Pos is the position of the key
+	Pos Position
+	// ValuePos is the position of the value
+	ValuePos Position
+}
+
+// Get returns with the value of the last assignment of the variable
+func (f DotenvFile) Get(key string) (string, bool) {
+	for i := len(f.Entries) - 1; i >= 0; i-- {
+		if f.Entries[i].Key == key {
+			return f.Entries[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Map returns with the variables of the file in a map
+func (f DotenvFile) Map() map[string]string {
+	values := make(map[string]string, len(f.Entries))
+	for _, entry := range f.Entries {
+		values[entry.Key] = entry.Value
+	}
+	return values
+}
+
+// dotenvAssignment is the result of parsing an assignment line, before the interpolation
+type dotenvAssignment struct {
+	key      Located
+	value    Located
+	parts    []parc.Result
+	isExport bool
+}
+
+// dotenvVariable is a reference to a variable in a value: $NAME, ${NAME} or ${NAME:-default}
+type dotenvVariable struct {
+	name         string
+	defaultValue string
+	hasDefault   bool
+}
+
+// DotenvLine is a parser of a single line of a .env file including its line terminator.
+// The quoted values may span multiple lines. Its result is nil in case of empty and comment lines.
+var DotenvLine = buildDotenvLineParser()
+
+// ParseDotenv parses the content of a .env file.
+// The comments start with `#`. The assignments may have an `export` prefix.
+// The values may be unquoted, single quoted or double quoted.
+// The `$NAME`, `${NAME}` and `${NAME:-default}` references in the unquoted and double quoted values are interpolated
+// with the value of the variables assigned earlier in the file. The variables not assigned in the file are looked up
+// by the lookup function, e.g. os.LookupEnv, if it is not nil, otherwise they are replaced by empty string.
+func ParseDotenv(input string, lookup func(string) (string, bool)) (DotenvFile, error) {
+	file := DotenvFile{}
+	values := map[string]string{}
+	state := parc.NewParserState(&input, nil, 0, nil)
+	for !state.AtTheEnd() {
+		state = DotenvLine.ParserFun(state)
+		if state.IsError {
+			return DotenvFile{}, syntaxErrorOf(state)
+		}
+		assignment, ok := state.Results.(dotenvAssignment)
+		if !ok {
+			continue
+		}
+
+		var sb strings.Builder
+		for _, part := range assignment.parts {
+			switch p := part.(type) {
+			case string:
+				sb.WriteString(p)
+			case dotenvVariable:
+				value, ok := values[p.name]
+				if !ok && lookup != nil {
+					value, ok = lookup(p.name)
+				}
+				if p.hasDefault && (!ok || value == "") {
+					value = p.defaultValue
+				}
+				sb.WriteString(value)
+			}
+		}
+
+		key := assignment.key.Value.(string)
+		values[key] = sb.String()
+		file.Entries = append(file.Entries, DotenvEntry{
+			Key:      key,
+			Value:    values[key],
+			Export:   assignment.isExport,
+			Pos:      assignment.key.Pos,
+			ValuePos: assignment.value.Pos,
+		})
+	}
+	return file, nil
+}
+
+// isDotenvKeyStart tests if rune can be the first character of a variable name: [A-Za-z_]
+func isDotenvKeyStart(r rune) bool {
+	return parc.IsAsciiLetter(r) || r == '_'
+}
+
+// isDotenvKeyChar tests if rune can be in a variable name: [A-Za-z0-9_.]
+func isDotenvKeyChar(r rune) bool {
+	return parc.IsAlphaNumeric(r) || r == '_' || r == '.'
+}
+
+// isDotenvReferenceChar tests if rune can be in the name of an unbraced $NAME reference: [A-Za-z0-9_],
+// so the reference ends at a `.` character, like in `$HOST.example.com`
+func isDotenvReferenceChar(r rune) bool {
+	return parc.IsAlphaNumeric(r) || r == '_'
+}
+
+// buildDotenvLineParser creates the parser of a single line of a .env file
+func buildDotenvLineParser() *parc.Parser {
+	ws := parc.CondMin(isBlank, 0)
+	comment := parc.SequenceOf(parc.Char("#"), parc.CondMin(isNotLineEnd, 0)).As("comment")
+	trailer := lineTrailer("dotenv", comment)
+
+	name := parc.SequenceOf(parc.Cond(isDotenvKeyStart), parc.CondMin(isDotenvKeyChar, 0)).Map(parc.JoinStrResults).As("variable name")
+
+	// The variable references
+	bracedVariable := parc.SequenceOf(
+		parc.Char("{"),
+		name,
+		parc.Optional(parc.SequenceOf(parc.Str(":-"), parc.CondMin(func(r rune) bool { return r != '}' }, 0)).Map(second)),
+		parc.Char("}").As("closing brace"),
+	).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		defaultValue, hasDefault := arr[2].(string)
+		return dotenvVariable{name: arr[1].(string), defaultValue: defaultValue, hasDefault: hasDefault}
+	})
+	referenceName := parc.SequenceOf(parc.Cond(isDotenvKeyStart), parc.CondMin(isDotenvReferenceChar, 0)).Map(parc.JoinStrResults).As("variable name")
+	simpleVariable := referenceName.Map(func(result parc.Result) parc.Result {
+		return dotenvVariable{name: result.(string)}
+	})
+	// A $ sign that is not followed by a variable name is kept as it is
+	dollar := parc.SequenceOf(
+		parc.Char("$"),
+		dispatch("variable", map[rune]*parc.Parser{'{': bracedVariable}, parc.Optional(simpleVariable)),
+	).Map(func(result parc.Result) parc.Result {
+		if variable := second(result); variable != nil {
+			return variable
+		}
+		return "$"
+	}).As("variable")
+
+	// The escape sequences of the double quoted values. These values are not parsed by parc.QuotedString,
+	// because they are interpolated, and the unknown escape sequences are kept as they are, like in the shells.
+	escapes := map[string]string{"n": "\n", "t": "\t", "r": "\r"}
+	escape := parc.SequenceOf(parc.Char(`\`), parc.AnyChar).Map(func(result parc.Result) parc.Result {
+		r := second(result).(string)
+		if escaped, ok := escapes[r]; ok {
+			return escaped
+		}
+		if r == `"` || r == `\` || r == "$" {
+			return r
+		}
+		return `\` + r
+	})
+	doubleQuoted := parc.SequenceOf(
+		parc.Char(`"`),
+		parc.ZeroOrMore(parc.Choice(
+			parc.CondMin(func(r rune) bool { return r != '"' && r != '\\' && r != '$' }, 1),
+			escape,
+			dollar,
+		)),
+		parc.Char(`"`).As("closing quote"),
+		trailer,
+	).Map(second)
+	singleQuoted := parc.SequenceOf(parc.QuotedString(parc.ShellSingleQuotedOptions).As("dotenv"), trailer).Map(func(result parc.Result) parc.Result {
+		return []parc.Result{first(result)}
+	})
+
+	// The unquoted values end at the end of the line or at a comment preceded by whitespace
+	blanks := parc.CondMin(isBlank, 1)
+	innerBlanks := parc.NewParser("blanks", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := blanks.ParserFun(parserState)
+		if newState.IsError {
+			return newState
+		}
+		r, _ := newState.NextRune()
+		if newState.AtTheEnd() || r == '#' || !isNotLineEnd(r) {
+			return parc.UpdateParserError(parserState, fmt.Errorf("blanks: trailing whitespace"))
+		}
+		return newState
+	})
+	unquoted := parc.ZeroOrMore(parc.Choice(
+		parc.CondMin(func(r rune) bool { return r != '$' && !isBlank(r) && isNotLineEnd(r) }, 1),
+		innerBlanks,
+		dollar,
+	))
+	unquotedValue := parc.SequenceOf(unquoted, trailer).Map(first)
+	empty := trailer.Map(func(parc.Result) parc.Result { return []parc.Result{} })
+
+	value := dispatch("value", map[rune]*parc.Parser{'"': doubleQuoted, '\'': singleQuoted, '#': empty}, unquotedValue)
+
+	assignment := parc.SequenceOf(
+		parc.Optional(parc.SequenceOf(parc.Str("export"), parc.CondMin(isBlank, 1))),
+		located(name),
+		ws,
+		parc.Char("=").As("equal sign"),
+		ws,
+		located(value),
+	).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		value := arr[5].(Located)
+		return dotenvAssignment{
+			key:      arr[1].(Located),
+			value:    value,
+			parts:    value.Value.([]parc.Result),
+			isExport: arr[0] != nil,
+		}
+	}).As("assignment")
+
+	ignored := parc.Map(parc.Choice(parc.SequenceOf(comment, lineEnd), lineEnd), func(parc.Result) parc.Result { return nil })
+
+	line := dispatch("line", map[rune]*parc.Parser{
+		'#': ignored, '\n': ignored, '\r': ignored, endOfInputRune: ignored,
+	}, assignment)
+	return parc.SequenceOf(ws, line).Map(second).As("dotenv line")
+}
@@ -0,0 +1,78 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	input := `# database settings
+DB_HOST=localhost
+export DB_PORT = 5432
+DB_URL="postgres://${DB_HOST}:$DB_PORT/app"
+PASSWORD='p@$$w0rd # not a comment'
+GREETING="Hello\nWorld" # comment
+MULTILINE="first
+second"
+PLAIN=some value with spaces   # comment
+FALLBACK=${UNDEFINED:-default}
+FROM_ENV=${HOME}
+PRICE=$ 5
+EMPTY=
+URL=$DB_HOST.example.com
+`
+	lookup := func(name string) (string, bool) {
+		if name == "HOME" {
+			return "/home/parc", true
+		}
+		return "", false
+	}
+
+	file, err := ParseDotenv(input, lookup)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"DB_HOST":   "localhost",
+		"DB_PORT":   "5432",
+		"DB_URL":    "postgres://localhost:5432/app",
+		"PASSWORD":  "p@$$w0rd # not a comment",
+		"GREETING":  "Hello\nWorld",
+		"MULTILINE": "first\nsecond",
+		"PLAIN":     "some value with spaces",
+		"FALLBACK":  "default",
+		"FROM_ENV":  "/home/parc",
+		"PRICE":     "$ 5",
+		"EMPTY":     "",
+		"URL":       "localhost.example.com",
+	}, file.Map())
+
+	require.False(t, file.Entries[0].Export)
+	require.True(t, file.Entries[1].Export)
+	require.Equal(t, Position{Offset: 45, Row: 3, Col: 8}, file.Entries[1].Pos)
+	require.Equal(t, Position{Offset: 55, Row: 3, Col: 18}, file.Entries[1].ValuePos)
+	require.Equal(t, 10, file.Entries[7].Pos.Row)
+
+	value, ok := file.Get("DB_PORT")
+	require.True(t, ok)
+	require.Equal(t, "5432", value)
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	testCases := map[string]int{
+		"A=1\n1B=2\n":             2,
+		"A=1\nB 2\n":              2,
+		"A=1\nB=\"unterminated\n": 2,
+		"A=1\nB=${C\n":            2,
+		"A=1\n\nB='x' y\n":        3,
+		"A=1\nB='unterminated\n":  2,
+	}
+	for input, row := range testCases {
+		_, err := ParseDotenv(input, nil)
+		var syntaxErr *SyntaxError
+		require.True(t, errors.As(err, &syntaxErr), input)
+		require.Equal(t, row, syntaxErr.Pos.Row, input)
+	}
+
+	_, err := ParseDotenv("A=1\nB='unterminated\n", nil)
+	require.Contains(t, err.Error(), "2:3: dotenv: unterminated literal")
+}
@@ -0,0 +1,114 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tombenke/parc"
+)
+
+// first returns with the first item of a sequence result
+func first(result parc.Result) parc.Result {
+	return result.([]parc.Result)[0]
+}
+
+// second returns with the second item of a sequence result
+func second(result parc.Result) parc.Result {
+	return result.([]parc.Result)[1]
+}
+
+// endOfInputRune is the key of the parser in the map of the dispatch parser, that is selected at the end of the input
+const endOfInputRune rune = -1
+
+// dispatch returns a parser that selects the parser to continue with by the next rune of the input.
+// At the end of the input the parser of the endOfInputRune key is selected.
+// If there is no parser for the next rune, it continues with the defaultParser, if it is not nil.
+// Unlike Choice, it keeps the error of the selected parser, so the errors are reported at their exact position.
+// In partial mode it needs more input at the end of the input, because the next rune is not known yet.
+func dispatch(name string, parsers map[rune]*parc.Parser, defaultParser *parc.Parser) *parc.Parser {
+	return parc.NewParser(name, func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		if parserState.IsPartial() && !utf8.FullRuneInString(parserState.Remaining()) {
+			return parc.UpdateParserIncomplete(parserState, name, 1)
+		}
+		r, _ := parserState.NextRune()
+		if parserState.AtTheEnd() {
+			r = endOfInputRune
+		}
+		if parser, ok := parsers[r]; ok {
+			return parser.ParserFun(parserState)
+		}
+		if defaultParser != nil {
+			return defaultParser.ParserFun(parserState)
+		}
+		if parserState.AtTheEnd() {
+			return parc.UpdateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", name))
+		}
+		return parc.UpdateParserError(parserState, fmt.Errorf("%s: unexpected character %q", name, r))
+	})
+}
+
+// separatedBy returns a parser that matches one or more items separated by the separator.
+// The results of the items are collected into an array. An item is mandatory after every separator,
+// so the error of a missing item is reported at its position. In partial mode it needs more input, if the separator needs more input.
+func separatedBy(item, separator *parc.Parser) *parc.Parser {
+	return parc.NewParser("separatedBy("+item.Name()+")", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		nextState := item.ParserFun(parserState)
+		if nextState.IsError {
+			return nextState
+		}
+		results := []parc.Result{nextState.Results}
+		for {
+			separatorState := separator.ParserFun(nextState)
+			if separatorState.IsIncomplete() {
+				return separatorState
+			}
+			if separatorState.IsError {
+				break
+			}
+			nextState = item.ParserFun(separatorState)
+			if nextState.IsError {
+				return nextState
+			}
+			results = append(results, nextState.Results)
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, parc.Result(results))
+	})
+}
+
+// sequence returns a parser that executes the parsers one after the other, and collects their results into an array.
+// Unlike SequenceOf, it keeps the error of the failed parser, so the errors are reported at their exact position.
+func sequence(parsers ...*parc.Parser) *parc.Parser {
+	return parc.NewParser("sequence", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		results := make([]parc.Result, 0, len(parsers))
+		nextState := parserState
+		for _, parser := range parsers {
+			nextState = parser.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			results = append(results, nextState.Results)
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, parc.Result(results))
+	})
+}
+
+// joinOptionalStrResults merges the string items of a sequence result, and skips the missing optional items
+func joinOptionalStrResults(result parc.Result) parc.Result {
+	var sb strings.Builder
+	for _, item := range result.([]parc.Result) {
+		if s, ok := item.(string); ok {
+			sb.WriteString(s)
+		}
+	}
+	return sb.String()
+}
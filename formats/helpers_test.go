@@ -0,0 +1,92 @@
+package formats
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/tombenke/parc"
+	"testing"
+)
+
+func TestDispatch(t *testing.T) {
+	parser := dispatch("sign", map[rune]*parc.Parser{
+		'+':            parc.Str("+1"),
+		'-':            parc.Str("-1"),
+		endOfInputRune: parc.EndOfInput().Map(func(parc.Result) parc.Result { return "end" }),
+	}, parc.Digits)
+
+	testCases := []parc.TestCase{
+		{Input: "+1", ExpectedResult: "+1"},
+		{Input: "-1", ExpectedResult: "-1"},
+		{Input: "", ExpectedResult: "end"},
+		{Input: "42", ExpectedResult: "42"},
+	}
+	for _, tc := range testCases {
+		newState := parser.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+	}
+
+	// The error of the selected parser is kept
+	input := "+2"
+	newState := parser.Parse(&input)
+	require.True(t, newState.IsError)
+	require.Equal(t, 0, newState.Index)
+
+	input = "x"
+	newState = dispatch("sign", map[rune]*parc.Parser{'+': parc.Char("+")}, nil).Parse(&input)
+	require.EqualError(t, newState.Err, `1:1: sign: unexpected character 'x'`)
+
+	// The next rune is not known at the end of the partial input
+	for _, input := range []string{"", "\xc3"} {
+		newState = parser.ParsePartial(&input)
+		require.True(t, newState.IsIncomplete(), newState.Err)
+	}
+}
+
+func TestSeparatedBy(t *testing.T) {
+	parser := separatedBy(parc.Digits, parc.Char(","))
+
+	testCases := []parc.TestCase{
+		{Input: "1", ExpectedResult: []parc.Result{"1"}},
+		{Input: "1,22,333", ExpectedResult: []parc.Result{"1", "22", "333"}},
+	}
+	for _, tc := range testCases {
+		newState := parser.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+		require.Equal(t, len(tc.Input), newState.Index, tc.Input)
+	}
+
+	// An item is mandatory after the separator
+	input := "1,2,"
+	newState := parser.Parse(&input)
+	require.True(t, newState.IsError)
+	require.Contains(t, newState.Err.Error(), "1:5:")
+
+	// The separator may follow in the next part of the input
+	input = "1,"
+	newState = separatedBy(parc.Digits, parc.Str(", ")).ParsePartial(&input)
+	require.True(t, newState.IsIncomplete(), newState.Err)
+}
+
+func TestSequence(t *testing.T) {
+	parser := sequence(parc.Letters, parc.Char("="), parc.ZeroOrOne(parc.Digits)).Map(joinOptionalStrResults)
+
+	testCases := []parc.TestCase{
+		{Input: "a=1", ExpectedResult: "a=1"},
+		{Input: "a=", ExpectedResult: "a="},
+	}
+	for _, tc := range testCases {
+		newState := parser.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+	}
+
+	// The error of the failed parser is kept
+	input := "a:1"
+	newState := parser.Parse(&input)
+	require.True(t, newState.IsError)
+	require.Contains(t, newState.Err.Error(), "1:2:")
+
+	require.Equal(t, "a", first([]parc.Result{"a", "b"}))
+	require.Equal(t, "b", second([]parc.Result{"a", "b"}))
+}
@@ -0,0 +1,373 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tombenke/parc"
+)
+
+// HTTPOptions holds the limits and the leniency of the HTTP message head parser
+type HTTPOptions struct {
+	// MaxLineLength is the maximum length of a line of the head without the line terminator. Its default value is 8192.
+	MaxLineLength int
+	// MaxHeaderCount is the maximum number of header fields. Its default value is 100.
+	MaxHeaderCount int
+	// MaxHeadSize is the maximum size of the whole head including the line terminators. Its default value is 65536.
+	MaxHeadSize int
+	// AllowBareLF accepts a single LF as line terminator besides CRLF, as RFC 9112 allows for the recipients
+	AllowBareLF bool
+}
+
+// withDefaults returns with the options where the zero limits are replaced by their default values
+func (o HTTPOptions) withDefaults() HTTPOptions {
+	if o.MaxLineLength == 0 {
+		o.MaxLineLength = 8192
+	}
+	if o.MaxHeaderCount == 0 {
+		o.MaxHeaderCount = 100
+	}
+	if o.MaxHeadSize == 0 {
+		o.MaxHeadSize = 65536
+	}
+	return o
+}
+
+// HTTPHeader is a header field of an HTTP message
+type HTTPHeader struct {
+	// Name is the field name as it is written in the message
+	Name string
+	// Value is the field value without the leading and trailing whitespace.
+	// The obsolete line foldings are replaced by a single space.
+	Value string
+	// Pos is the position of the field name
+	Pos Position
+}
+
+// HTTPHeaders holds the header fields of an HTTP message in the order of the message
+type HTTPHeaders []HTTPHeader
+
+// Get returns with the value of the first field with the given name. The field names are case-insensitive.
+func (h HTTPHeaders) Get(name string) (string, bool) {
+	for _, header := range h {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value, true
+		}
+	}
+	return "", false
+}
+
+// Values returns with the values of all fields with the given name
+func (h HTTPHeaders) Values(name string) []string {
+	values := []string{}
+	for _, header := range h {
+		if strings.EqualFold(header.Name, name) {
+			values = append(values, header.Value)
+		}
+	}
+	return values
+}
+
+// HTTPRequest is the head of an HTTP/1.1 request message
+type HTTPRequest struct {
+	// Method is the request method, e.g. GET
+	Method string
+	// Target is the request target, e.g. /index.html
+	Target string
+	// Version is the protocol version, e.g. HTTP/1.1
+	Version string
+	// Headers holds the header fields
+	Headers HTTPHeaders
+	// HeadLength is the length of the head including the empty line, that is the offset of the message body
+	HeadLength int
+}
+
+// HTTPResponse is the head of an HTTP/1.1 response message
+type HTTPResponse struct {
+	// Version is the protocol version, e.g. HTTP/1.1
+	Version string
+	// StatusCode is the three digit status code
+	StatusCode int
+	// Reason is the reason phrase
+	Reason string
+	// Headers holds the header fields
+	Headers HTTPHeaders
+	// HeadLength is the length of the head including the empty line, that is the offset of the message body
+	HeadLength int
+}
+
+var (
+	// HTTPToken is a parser of an RFC 9110 token, that is used in method names, field names and parameters.
+	// The result is the matched text.
+	HTTPToken = parc.CondMin(isTchar, 1).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("HTTP: expected token")
+	})
+
+	// HTTPQuotedString is a parser of an RFC 9110 quoted-string. The result is the unquoted text.
+	HTTPQuotedString = buildHTTPQuotedStringParser()
+)
+
+// HTTPRequestHead returns a parser of an HTTP/1.1 request head: the request line, the header fields and the empty line.
+// The result is an HTTPRequest value.
+func HTTPRequestHead(options HTTPOptions) *parc.Parser {
+	options = options.withDefaults()
+	method := HTTPToken
+	target := parc.CondMin(isVisibleASCII, 1).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("HTTP: expected request target")
+	})
+	requestLine := sequence(method, expected("HTTP", " "), target, expected("HTTP", " "), httpVersion).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		return HTTPRequest{Method: arr[0].(string), Target: arr[2].(string), Version: arr[4].(string)}
+	})
+	return httpHead("request", requestLine, options).Map(func(result parc.Result) parc.Result {
+		head := result.(httpMessageHead)
+		request := head.startLine.(HTTPRequest)
+		request.Headers, request.HeadLength = head.headers, head.length
+		return request
+	})
+}
+
+// HTTPResponseHead returns a parser of an HTTP/1.1 response head: the status line, the header fields and the empty line.
+// The result is an HTTPResponse value.
+func HTTPResponseHead(options HTTPOptions) *parc.Parser {
+	options = options.withDefaults()
+	statusCode := parc.CondMinMax(parc.IsDigit, 3, 3).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("HTTP: expected three digit status code")
+	})
+	reason := parc.CondMin(func(r rune) bool { return isFieldVChar(r) || isBlank(r) }, 0)
+	statusLine := sequence(httpVersion, expected("HTTP", " "), statusCode, expected("HTTP", " "), reason).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		code, _ := strconv.Atoi(arr[2].(string))
+		return HTTPResponse{Version: arr[0].(string), StatusCode: code, Reason: arr[4].(string)}
+	})
+	return httpHead("response", statusLine, options).Map(func(result parc.Result) parc.Result {
+		head := result.(httpMessageHead)
+		response := head.startLine.(HTTPResponse)
+		response.Headers, response.HeadLength = head.headers, head.length
+		return response
+	})
+}
+
+// ParseHTTPRequest parses the head of an HTTP/1.1 request. The message body starts at the HeadLength offset of the input.
+func ParseHTTPRequest(input string, options HTTPOptions) (HTTPRequest, error) {
+	state := HTTPRequestHead(options).Parse(&input)
+	if state.IsError {
+		return HTTPRequest{}, syntaxErrorOf(state)
+	}
+	return state.Results.(HTTPRequest), nil
+}
+
+// ParseHTTPResponse parses the head of an HTTP/1.1 response. The message body starts at the HeadLength offset of the input.
+func ParseHTTPResponse(input string, options HTTPOptions) (HTTPResponse, error) {
+	state := HTTPResponseHead(options).Parse(&input)
+	if state.IsError {
+		return HTTPResponse{}, syntaxErrorOf(state)
+	}
+	return state.Results.(HTTPResponse), nil
+}
+
+// ParseMediaType parses a media type with its parameters, like the value of a Content-Type header field,
+// e.g. `text/html; charset="utf-8"`. The type, subtype and parameter names are converted to lower case.
+func ParseMediaType(value string) (string, map[string]string, error) {
+	state := mediaType.Parse(&value)
+	if state.IsError {
+		return "", nil, syntaxErrorOf(state)
+	}
+	arr := state.Results.([]parc.Result)
+	return arr[0].(string), arr[1].(map[string]string), nil
+}
+
+// isTchar tests if rune can be in a token: ALPHA / DIGIT / "!" / "#" / "$" / "%" / "&" / "'" / "*" / "+" / "-" / "." /
+// "^" / "_" / "`" / "|" / "~"
+func isTchar(r rune) bool {
+	return parc.IsAlphaNumeric(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+// isVisibleASCII tests if rune is a visible US-ASCII character (VCHAR)
+func isVisibleASCII(r rune) bool {
+	return r >= 0x21 && r <= 0x7e
+}
+
+// isFieldVChar tests if rune is a visible character or an obsolete non US-ASCII text character of field values
+func isFieldVChar(r rune) bool {
+	return isVisibleASCII(r) || r >= 0x80
+}
+
+// httpVersion is a parser of the protocol version, e.g. HTTP/1.1
+var httpVersion = sequence(expected("HTTP", "HTTP/"), parc.Digit, expected("HTTP", "."), parc.Digit).Map(func(result parc.Result) parc.Result {
+	return "HTTP/" + parc.JoinStrResults(result.([]parc.Result)[1:]).(string)
+}).ErrorMap(func(parc.ParserState) error {
+	return fmt.Errorf("HTTP: expected protocol version")
+})
+
+// buildHTTPQuotedStringParser creates the parser of quoted strings
+func buildHTTPQuotedStringParser() *parc.Parser {
+	qdtext := parc.CondMin(func(r rune) bool { return r != '"' && r != '\\' && (isFieldVChar(r) || isBlank(r)) }, 1)
+	quotedPair := sequence(parc.Char(`\`), parc.Cond(func(r rune) bool { return isFieldVChar(r) || isBlank(r) })).Map(second)
+	closingQuote := parc.Char(`"`).ErrorMap(func(state parc.ParserState) error {
+		if state.AtTheEnd() {
+			return fmt.Errorf("HTTP: unterminated quoted string")
+		}
+		r, _ := state.NextRune()
+		return fmt.Errorf("HTTP: invalid character %q in quoted string", r)
+	})
+	return sequence(
+		parc.Char(`"`),
+		parc.ZeroOrMore(parc.Choice(qdtext, quotedPair)).Map(parc.JoinStrResults),
+		closingQuote,
+	).Map(second).As("quoted-string")
+}
+
+// mediaType is a parser of a media type with parameters
+var mediaType = buildMediaTypeParser()
+
+// buildMediaTypeParser creates the parser of media types
+func buildMediaTypeParser() *parc.Parser {
+	ows := parc.CondMin(isBlank, 0)
+	lower := func(result parc.Result) parc.Result { return strings.ToLower(result.(string)) }
+	typeName := sequence(HTTPToken, expected("HTTP", "/"), HTTPToken).Map(func(result parc.Result) parc.Result {
+		return strings.ToLower(parc.JoinStrResults(result).(string))
+	})
+	parameterValue := dispatch("parameter value", map[rune]*parc.Parser{'"': HTTPQuotedString}, HTTPToken)
+	parameter := sequence(ows, parc.Char(";"), ows, HTTPToken.Map(lower), expected("HTTP", "="), parameterValue)
+	return sequence(ows, typeName, parc.ZeroOrMore(parameter), ows, parc.EndOfInput().ErrorMap(func(state parc.ParserState) error {
+		r, _ := state.NextRune()
+		return fmt.Errorf("HTTP: unexpected character %q", r)
+	})).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		parameters := map[string]string{}
+		for _, item := range arr[2].([]parc.Result) {
+			p := item.([]parc.Result)
+			parameters[p[3].(string)] = p[5].(string)
+		}
+		return []parc.Result{arr[1], parameters}
+	})
+}
+
+// httpMessageHead is the result of parsing a message head, before it is converted into a request or a response
+type httpMessageHead struct {
+	startLine parc.Result
+	headers   HTTPHeaders
+	length    int
+}
+
+// httpHead returns a parser of a message head, that starts with the start line parsed by the startLine parser
+func httpHead(kind string, startLine *parc.Parser, options HTTPOptions) *parc.Parser {
+	lineEnd := parc.Crlf
+	if options.AllowBareLF {
+		lineEnd = parc.Choice(parc.Crlf, parc.Newline)
+	}
+	ows := parc.CondMin(isBlank, 0)
+	rws := parc.CondMin(isBlank, 1)
+	fieldContent := parc.CondMin(func(r rune) bool { return isFieldVChar(r) || isBlank(r) }, 0)
+
+	// endOfLine matches the line terminator, and reports the unexpected character otherwise
+	endOfLine := func(state parc.ParserState, what string) parc.ParserState {
+		endState := lineEnd.ParserFun(state)
+		if !endState.IsError {
+			return endState
+		}
+		if state.AtTheEnd() {
+			return parc.UpdateParserError(state, fmt.Errorf("HTTP: unexpected end of %s", kind))
+		}
+		r, _ := state.NextRune()
+		if r == '\n' || r == '\r' {
+			return parc.UpdateParserError(state, fmt.Errorf("HTTP: expected CRLF"))
+		}
+		return parc.UpdateParserError(state, fmt.Errorf("HTTP: invalid character %q in %s", r, what))
+	}
+
+	// checkLine checks the limits before the line at the state is parsed
+	checkLine := func(headStart, state parc.ParserState) error {
+		line := state.Remaining()
+		if end := strings.IndexByte(line, '\n'); end >= 0 {
+			line = strings.TrimSuffix(line[:end], "\r")
+		}
+		if len(line) > options.MaxLineLength {
+			return fmt.Errorf("HTTP: line is longer than %d bytes", options.MaxLineLength)
+		}
+		if state.Index-headStart.Index+len(line) > options.MaxHeadSize {
+			return fmt.Errorf("HTTP: %s head is larger than %d bytes", kind, options.MaxHeadSize)
+		}
+		return nil
+	}
+
+	return parc.NewParser("HTTP "+kind+" head", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		if err := checkLine(parserState, parserState); err != nil {
+			return parc.UpdateParserError(parserState, err)
+		}
+		nextState := startLine.ParserFun(parserState)
+		if nextState.IsError {
+			return nextState
+		}
+		head := httpMessageHead{startLine: nextState.Results, headers: HTTPHeaders{}}
+		nextState = endOfLine(nextState, map[string]string{"request": "request line", "response": "status line"}[kind])
+		if nextState.IsError {
+			return nextState
+		}
+
+		for {
+			if err := checkLine(parserState, nextState); err != nil {
+				return parc.UpdateParserError(nextState, err)
+			}
+			// The empty line terminates the head
+			if endState := lineEnd.ParserFun(nextState); !endState.IsError {
+				nextState = endState
+				break
+			}
+			if nextState.AtTheEnd() {
+				return parc.UpdateParserError(nextState, fmt.Errorf("HTTP: unexpected end of %s", kind))
+			}
+			if r, _ := nextState.NextRune(); isBlank(r) && len(head.headers) == 0 {
+				return parc.UpdateParserError(nextState, fmt.Errorf("HTTP: whitespace before the first header field"))
+			}
+			if len(head.headers) == options.MaxHeaderCount {
+				return parc.UpdateParserError(nextState, fmt.Errorf("HTTP: more than %d header fields", options.MaxHeaderCount))
+			}
+
+			header := HTTPHeader{Pos: positionOf(nextState)}
+			nextState = HTTPToken.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			header.Name = nextState.Results.(string)
+			switch r, _ := nextState.NextRune(); {
+			case isBlank(r):
+				return parc.UpdateParserError(nextState, fmt.Errorf("HTTP: whitespace between field name and colon"))
+			case nextState.AtTheEnd():
+				return parc.UpdateParserError(nextState, fmt.Errorf("HTTP: unexpected end of %s", kind))
+			case r != ':':
+				return parc.UpdateParserError(nextState, fmt.Errorf("HTTP: invalid character %q in field name", r))
+			}
+			_, nextState = nextState.NextRune()
+
+			lines := []string{}
+			nextState = ows.ParserFun(nextState)
+			for {
+				nextState = fieldContent.ParserFun(nextState)
+				lines = append(lines, strings.Trim(nextState.Results.(string), " \t"))
+				nextState = endOfLine(nextState, "field value")
+				if nextState.IsError {
+					return nextState
+				}
+				// The obsolete line folding continues the field value in the next line
+				foldState := rws.ParserFun(nextState)
+				if foldState.IsError {
+					break
+				}
+				if err := checkLine(parserState, nextState); err != nil {
+					return parc.UpdateParserError(nextState, err)
+				}
+				nextState = foldState
+			}
+			header.Value = strings.Join(lines, " ")
+			head.headers = append(head.headers, header)
+		}
+		head.length = nextState.Index - parserState.Index
+		return parc.UpdateParserState(nextState, nextState.Index, head)
+	})
+}
@@ -0,0 +1,122 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseHTTPRequest(t *testing.T) {
+	input := "GET /index.html?q=1 HTTP/1.1\r\n" +
+		"Host: www.example.com\r\n" +
+		"Accept:text/html,  \r\n" +
+		"  application/xhtml+xml\r\n" +
+		"X-Empty:\r\n" +
+		"Cookie: a=1\r\n" +
+		"cookie: b=2\r\n" +
+		"\r\n" +
+		"body"
+	request, err := ParseHTTPRequest(input, HTTPOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "GET", request.Method)
+	require.Equal(t, "/index.html?q=1", request.Target)
+	require.Equal(t, "HTTP/1.1", request.Version)
+	require.Equal(t, HTTPHeaders{
+		{Name: "Host", Value: "www.example.com", Pos: Position{Offset: 30, Row: 2, Col: 1}},
+		{Name: "Accept", Value: "text/html, application/xhtml+xml", Pos: Position{Offset: 53, Row: 3, Col: 1}},
+		{Name: "X-Empty", Value: "", Pos: Position{Offset: 99, Row: 5, Col: 1}},
+		{Name: "Cookie", Value: "a=1", Pos: Position{Offset: 109, Row: 6, Col: 1}},
+		{Name: "cookie", Value: "b=2", Pos: Position{Offset: 122, Row: 7, Col: 1}},
+	}, request.Headers)
+	require.Equal(t, "body", input[request.HeadLength:])
+
+	host, ok := request.Headers.Get("HOST")
+	require.True(t, ok)
+	require.Equal(t, "www.example.com", host)
+	require.Equal(t, []string{"a=1", "b=2"}, request.Headers.Values("Cookie"))
+	_, ok = request.Headers.Get("Content-Length")
+	require.False(t, ok)
+}
+
+func TestParseHTTPResponse(t *testing.T) {
+	input := "HTTP/1.1 404 Not Found\r\nContent-Type: text/plain\r\nContent-Length: 0\r\n\r\n"
+	response, err := ParseHTTPResponse(input, HTTPOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "HTTP/1.1", response.Version)
+	require.Equal(t, 404, response.StatusCode)
+	require.Equal(t, "Not Found", response.Reason)
+	require.Len(t, response.Headers, 2)
+	require.Equal(t, len(input), response.HeadLength)
+
+	// The reason phrase may be empty
+	response, err = ParseHTTPResponse("HTTP/1.0 204 \r\n\r\n", HTTPOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 204, response.StatusCode)
+	require.Equal(t, "", response.Reason)
+}
+
+func TestParseHTTPBareLF(t *testing.T) {
+	input := "GET / HTTP/1.1\nHost: example.com\n\n"
+	_, err := ParseHTTPRequest(input, HTTPOptions{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "HTTP: expected CRLF")
+
+	request, err := ParseHTTPRequest(input, HTTPOptions{AllowBareLF: true})
+	require.NoError(t, err)
+	require.Equal(t, HTTPHeaders{{Name: "Host", Value: "example.com", Pos: Position{Offset: 15, Row: 2, Col: 1}}}, request.Headers)
+}
+
+func TestParseHTTPRequestErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		options HTTPOptions
+		row     int
+		col     int
+		message string
+	}{
+		{"GET /  HTTP/1.1\r\n\r\n", HTTPOptions{}, 1, 7, "HTTP: expected protocol version"},
+		{"GET / HTTP/1.1 \r\n\r\n", HTTPOptions{}, 1, 15, `HTTP: invalid character ' ' in request line`},
+		{"G(T / HTTP/1.1\r\n\r\n", HTTPOptions{}, 1, 2, `HTTP: expected " "`},
+		{"GET / HTTP/1.1\r\nHost : a\r\n\r\n", HTTPOptions{}, 2, 5, "HTTP: whitespace between field name and colon"},
+		{"GET / HTTP/1.1\r\nHo(st: a\r\n\r\n", HTTPOptions{}, 2, 3, `HTTP: invalid character '(' in field name`},
+		{"GET / HTTP/1.1\r\nHost: a\x01b\r\n\r\n", HTTPOptions{}, 2, 8, `HTTP: invalid character '\x01' in field value`},
+		{"GET / HTTP/1.1\r\n Host: a\r\n\r\n", HTTPOptions{}, 2, 1, "HTTP: whitespace before the first header field"},
+		{"GET / HTTP/1.1\r\nHost: a\r\n", HTTPOptions{}, 3, 1, "HTTP: unexpected end of request"},
+		{"GET / HTTP/1.1\r\nHost: a\r\n: b\r\n\r\n", HTTPOptions{}, 3, 1, "HTTP: expected token"},
+		{"GET /" + strings.Repeat("a", 100) + " HTTP/1.1\r\n\r\n", HTTPOptions{MaxLineLength: 100}, 1, 1, "HTTP: line is longer than 100 bytes"},
+		{"GET / HTTP/1.1\r\nA: 1\r\nB: 2\r\nC: 3\r\n\r\n", HTTPOptions{MaxHeaderCount: 2}, 4, 1, "HTTP: more than 2 header fields"},
+		{"GET / HTTP/1.1\r\nA: 1\r\nB: 2\r\nC: 3\r\n\r\n", HTTPOptions{MaxHeadSize: 25}, 3, 1, "HTTP: request head is larger than 25 bytes"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseHTTPRequest(testCase.input, testCase.options)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.row, syntaxError.Pos.Row, testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
+
+func TestHTTPQuotedString(t *testing.T) {
+	input := `"a \"quoted\" \\ string" rest`
+	state := HTTPQuotedString.Parse(&input)
+	require.False(t, state.IsError)
+	require.Equal(t, `a "quoted" \ string`, state.Results)
+
+	input = `"unterminated`
+	state = HTTPQuotedString.Parse(&input)
+	require.True(t, state.IsError)
+	require.Contains(t, state.Err.Error(), "HTTP: unterminated quoted string")
+}
+
+func TestParseMediaType(t *testing.T) {
+	mediaType, parameters, err := ParseMediaType(`Text/HTML; Charset="utf-8" ;q=0.9`)
+	require.NoError(t, err)
+	require.Equal(t, "text/html", mediaType)
+	require.Equal(t, map[string]string{"charset": "utf-8", "q": "0.9"}, parameters)
+
+	_, _, err = ParseMediaType("text/html; charset")
+	require.Error(t, err)
+}
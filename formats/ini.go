@@ -0,0 +1,220 @@
+package formats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tombenke/parc"
+)
+
+// INIFile is the content of an INI file. The sections and entries are kept in the order of the input.
+type INIFile struct {
+	// Sections holds the sections of the file.
+	// The entries before the first section header belong to a section with empty name.
+	Sections []INISection
+}
+
+// INISection is a section of an INI file
+type INISection struct {
+	// Name is the name of the section
+	Name string
+	// Pos is the position of the section header
+	Pos Position
+	// Entries holds the key-value pairs of the section
+	Entries []INIEntry
+}
+
+// INIEntry is a key-value pair of an INI file
+type INIEntry struct {
+	// Key is the name of the entry
+	Key string
+	// Value is the value of the entry, without the quotes, inline comments and line continuations
+	Value string
+	// Pos is the position of the key
+	Pos Position
+	// ValuePos is the position of the value
+	ValuePos Position
+}
+
+// Section returns with the first section of the given name
+func (f INIFile) Section(name string) (INISection, bool) {
+	for _, section := range f.Sections {
+		if section.Name == name {
+			return section, true
+		}
+	}
+	return INISection{}, false
+}
+
+// Get returns with the value of the last entry of the given key
+func (s INISection) Get(key string) (string, bool) {
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		if s.Entries[i].Key == key {
+			return s.Entries[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// iniSectionHeader is the result of parsing a section header line
+type iniSectionHeader struct {
+	name string
+	pos  Position
+}
+
+// INILine is a parser of a single line of an INI file including its line terminator.
+// Its result is nil in case of empty and comment lines.
+var INILine = buildINILineParser()
+
+// ParseINI parses the content of an INI file.
+// The comments start with `;` or `#`. The values may be enclosed by double or single quotes.
+// The unquoted values may have inline comments preceded by whitespace,
+// and they are continued in the next line if they end with a backslash.
+func ParseINI(input string) (INIFile, error) {
+	file := INIFile{}
+	state := parc.NewParserState(&input, nil, 0, nil)
+	for !state.AtTheEnd() {
+		state = INILine.ParserFun(state)
+		if state.IsError {
+			return INIFile{}, syntaxErrorOf(state)
+		}
+		switch item := state.Results.(type) {
+		case iniSectionHeader:
+			file.Sections = append(file.Sections, INISection{Name: item.name, Pos: item.pos})
+		case INIEntry:
+			if len(file.Sections) == 0 {
+				file.Sections = append(file.Sections, INISection{Pos: Position{Row: 1, Col: 1}})
+			}
+			section := &file.Sections[len(file.Sections)-1]
+			section.Entries = append(section.Entries, item)
+		}
+	}
+	return file, nil
+}
+
+// isBlank tests if rune is space or tab
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// isNotLineEnd tests if rune is neither newline nor carriage return
+func isNotLineEnd(r rune) bool {
+	return r != '\n' && r != '\r'
+}
+
+// lineEnd matches the end of a line or the end of the input
+var lineEnd = parc.Choice(parc.Newline, parc.Crlf, parc.EndOfInput()).As("end of line")
+
+// lineTrailer returns a parser that matches the optional whitespace and comment at the end of a line, and the line terminator.
+// If anything else follows, it reports the unexpected character at its position.
+func lineTrailer(format string, comment *parc.Parser) *parc.Parser {
+	blanks := parc.CondMin(isBlank, 0)
+	trailer := parc.SequenceOf(blanks, parc.Optional(comment), lineEnd)
+	return parc.NewParser("line trailer", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := trailer.ParserFun(parserState)
+		if newState.IsError {
+			errState := blanks.ParserFun(parserState)
+			r, _ := errState.NextRune()
+			return parc.UpdateParserError(errState, fmt.Errorf("%s: unexpected character %q", format, r))
+		}
+		return parc.UpdateParserState(newState, newState.Index, nil)
+	})
+}
+
+// iniDoubleQuotedOptions are the options of the double quoted values
+var iniDoubleQuotedOptions = parc.QuotedStringOptions{
+	Quote:   '"',
+	Escapes: map[rune]string{'\\': `\`, '"': `"`, 'n': "\n", 't': "\t", 'r': "\r"},
+}
+
+// buildINILineParser creates the parser of a single line of an INI file
+func buildINILineParser() *parc.Parser {
+	ws := parc.CondMin(isBlank, 0)
+	restOfLine := parc.CondMin(isNotLineEnd, 0)
+	comment := parc.SequenceOf(parc.Choice(parc.Char(";"), parc.Char("#")), restOfLine).As("comment")
+	trailer := lineTrailer("INI", comment)
+
+	section := parc.SequenceOf(
+		located(parc.Char("[")),
+		ws,
+		parc.CondMin(func(r rune) bool { return r != ']' && isNotLineEnd(r) }, 1).As("section name"),
+		parc.Char("]").As("closing bracket"),
+		trailer,
+	).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		return iniSectionHeader{name: strings.TrimRight(arr[2].(string), " \t"), pos: arr[0].(Located).Pos}
+	}).As("section")
+
+	// The quoted values can not span multiple lines, and only the double quoted values have escape sequences
+	doubleQuoted := parc.SequenceOf(parc.QuotedString(iniDoubleQuotedOptions).As("INI"), trailer).Map(first)
+	singleQuoted := parc.SequenceOf(parc.QuotedString(parc.QuotedStringOptions{Quote: '\''}).As("INI"), trailer).Map(first)
+
+	// The unquoted values are terminated by an inline comment or the end of the line,
+	// and they are continued in the next line if they end with a backslash
+	continuation := parc.SequenceOf(lineEnd, ws)
+	unquoted := parc.NewParser("unquoted value", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		var sb strings.Builder
+		nextState := parserState
+		for {
+			nextState = restOfLine.ParserFun(nextState)
+			segment := stripInlineComment(nextState.Results.(string))
+			if !strings.HasSuffix(segment, `\`) {
+				sb.WriteString(strings.TrimRight(segment, " \t"))
+				break
+			}
+			sb.WriteString(strings.TrimSuffix(segment, `\`))
+			nextState = continuation.ParserFun(nextState)
+			if nextState.AtTheEnd() {
+				break
+			}
+		}
+		nextState = lineEnd.ParserFun(nextState)
+		return parc.UpdateParserState(nextState, nextState.Index, sb.String())
+	})
+
+	empty := trailer.Map(func(parc.Result) parc.Result { return "" })
+	value := dispatch("value", map[rune]*parc.Parser{'"': doubleQuoted, '\'': singleQuoted, ';': empty, '#': empty}, unquoted)
+
+	key := parc.CondMin(func(r rune) bool {
+		return r != '=' && r != ':' && r != ';' && r != '#' && r != '[' && isNotLineEnd(r)
+	}, 1).Map(func(result parc.Result) parc.Result {
+		return strings.TrimRight(result.(string), " \t")
+	}).As("key")
+
+	entry := parc.SequenceOf(
+		located(key),
+		parc.Choice(parc.Char("="), parc.Char(":")).As("separator"),
+		ws,
+		located(value),
+	).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		key := arr[0].(Located)
+		value := arr[3].(Located)
+		return INIEntry{Key: key.Value.(string), Value: value.Value.(string), Pos: key.Pos, ValuePos: value.Pos}
+	}).As("entry")
+
+	ignored := parc.Map(parc.Choice(parc.SequenceOf(comment, lineEnd), lineEnd), func(parc.Result) parc.Result { return nil })
+
+	line := dispatch("line", map[rune]*parc.Parser{
+		';': ignored, '#': ignored, '\n': ignored, '\r': ignored, endOfInputRune: ignored,
+		'[': section,
+	}, entry)
+	return parc.SequenceOf(ws, line).Map(second).As("INI line")
+}
+
+// stripInlineComment removes the comment from the end of an unquoted value.
+// The inline comments start with `;` or `#` preceded by whitespace.
+func stripInlineComment(s string) string {
+	for i := 1; i < len(s); i++ {
+		if (s[i] == ';' || s[i] == '#') && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimRight(s[:i], " \t")
+		}
+	}
+	return s
+}
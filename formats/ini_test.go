@@ -0,0 +1,85 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseINI(t *testing.T) {
+	input := `; global settings
+name = parc
+debug=true
+
+[database]
+host = "localhost" ; the host
+port: 5432
+password = 'se;cr#et'
+dsn = postgres://user@host \
+      /dbname ; inline comment
+escaped = "tab\there \"quoted\""
+empty =
+
+# the last section
+[ server ]
+listen = :8080`
+
+	file, err := ParseINI(input)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(file.Sections))
+
+	global := file.Sections[0]
+	require.Equal(t, "", global.Name)
+	require.Equal(t, []INIEntry{
+		{Key: "name", Value: "parc", Pos: Position{Offset: 18, Row: 2, Col: 1}, ValuePos: Position{Offset: 25, Row: 2, Col: 8}},
+		{Key: "debug", Value: "true", Pos: Position{Offset: 30, Row: 3, Col: 1}, ValuePos: Position{Offset: 36, Row: 3, Col: 7}},
+	}, global.Entries)
+
+	database, ok := file.Section("database")
+	require.True(t, ok)
+	require.Equal(t, Position{Offset: 42, Row: 5, Col: 1}, database.Pos)
+	expected := map[string]string{
+		"host":     "localhost",
+		"port":     "5432",
+		"password": "se;cr#et",
+		"dsn":      "postgres://user@host /dbname",
+		"escaped":  "tab\there \"quoted\"",
+		"empty":    "",
+	}
+	for key, value := range expected {
+		actual, ok := database.Get(key)
+		require.True(t, ok, key)
+		require.Equal(t, value, actual, key)
+	}
+	require.Equal(t, "port", database.Entries[1].Key)
+	require.Equal(t, 7, database.Entries[1].Pos.Row)
+
+	server, ok := file.Section("server")
+	require.True(t, ok)
+	listen, _ := server.Get("listen")
+	require.Equal(t, ":8080", listen)
+}
+
+func TestParseINIErrors(t *testing.T) {
+	testCases := map[string]int{
+		"[section\nkey=value":         1,
+		"key=value\n[ok]\nbad line\n": 3,
+		"a=1\nb=\"unterminated\nc=3":  2,
+		"a=1\nb=\"value\" trailing\n": 2,
+		"a=1\nb=\"bad \\q escape\"":   2,
+	}
+	for input, row := range testCases {
+		_, err := ParseINI(input)
+		var syntaxErr *SyntaxError
+		require.True(t, errors.As(err, &syntaxErr), input)
+		require.Equal(t, row, syntaxErr.Pos.Row, input)
+	}
+
+	_, err := ParseINI("a=1\nb=\"value\" trailing\n")
+	require.Contains(t, err.Error(), `2:11: INI: unexpected character 't'`)
+
+	_, err = ParseINI("a=1\nb=\"bad \\q escape\"")
+	require.Contains(t, err.Error(), `2:8: INI: invalid escape sequence \q`)
+	_, err = ParseINI("a='unterminated\n")
+	require.Contains(t, err.Error(), `1:16: INI: newline in literal`)
+}
@@ -0,0 +1,146 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tombenke/parc"
+)
+
+var (
+	// JSON is a parser of an RFC 8259 JSON text, that is a single JSON value surrounded by optional whitespace,
+	// that must consume the whole input.
+	// The result is a map[string]any, []any, string, float64, bool or nil value,
+	// like encoding/json produces when it decodes into an `any` value.
+	JSON *parc.Parser
+
+	// JSONValue is a parser of a single JSON value followed by optional whitespace.
+	// It can be used as a building block of other grammars.
+	JSONValue *parc.Parser
+
+	// JSONString is a parser of a JSON string literal. The result is the decoded string value.
+	// The lone surrogates of the \u escapes are replaced by the U+FFFD replacement character.
+	// It is built by parc.QuotedString from parc.JSONStringOptions, so it decodes the literals like parc.JSONString.
+	JSONString *parc.Parser
+
+	// JSONNumber is a parser of a JSON number literal. The result is a float64 value.
+	JSONNumber *parc.Parser
+)
+
+func init() {
+	JSON, JSONValue, JSONString, JSONNumber = buildJSONParser()
+}
+
+// ParseJSON parses the input as a JSON text and returns with the decoded value
+func ParseJSON(input string) (any, error) {
+	state := JSON.Parse(&input)
+	if state.IsError {
+		return nil, state.Err
+	}
+	return state.Results, nil
+}
+
+// isJSONWhitespace tests if rune is JSON whitespace: space, tab, line feed or carriage return
+func isJSONWhitespace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// isOneToNine tests if rune is a non-zero decimal digit
+func isOneToNine(r rune) bool {
+	return r >= '1' && r <= '9'
+}
+
+// buildJSONParser creates the parsers of the JSON grammar
+func buildJSONParser() (text, value, str, number *parc.Parser) {
+	var jsonValue parc.Parser
+
+	ws := parc.CondMin(isJSONWhitespace, 0).As("whitespace")
+	lexeme := func(p *parc.Parser) *parc.Parser {
+		return parc.SequenceOf(p, ws).Map(first)
+	}
+
+	// The string literal
+	str = parc.QuotedString(parc.JSONStringOptions).As("string")
+
+	// The number literal
+	numberText := parc.SequenceOf(
+		parc.Optional(parc.Char("-")),
+		parc.Choice(
+			parc.Char("0"),
+			parc.SequenceOf(parc.Cond(isOneToNine), parc.CondMin(parc.IsDigit, 0)).Map(parc.JoinStrResults),
+		),
+		parc.Optional(parc.SequenceOf(parc.Char("."), parc.Digits).Map(parc.JoinStrResults)),
+		parc.Optional(parc.SequenceOf(
+			parc.Choice(parc.Char("e"), parc.Char("E")),
+			parc.Optional(parc.Choice(parc.Char("+"), parc.Char("-"))),
+			parc.Digits,
+		).Map(joinOptionalStrResults)),
+	).Map(joinOptionalStrResults)
+	number = parc.NewParser("number", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := numberText.ParserFun(parserState)
+		if newState.IsError {
+			return newState
+		}
+		numberValue, err := strconv.ParseFloat(newState.Results.(string), 64)
+		if err != nil {
+			return parc.UpdateParserError(parserState, fmt.Errorf("number: %s is out of range", newState.Results))
+		}
+		return parc.UpdateParserState(newState, newState.Index, numberValue)
+	})
+
+	literal := func(s string, v any) *parc.Parser {
+		return parc.Str(s).Map(func(parc.Result) parc.Result { return v })
+	}
+
+	// The structured values
+	comma := lexeme(parc.Char(","))
+	array := parc.SequenceOf(
+		lexeme(parc.Char("[")),
+		dispatch("array", map[rune]*parc.Parser{']': parc.Char("]")},
+			parc.SequenceOf(separatedBy(&jsonValue, comma), parc.Char("]").As("end of array")).Map(first)),
+	).Map(func(result parc.Result) parc.Result {
+		values := []any{}
+		if items, ok := second(result).([]parc.Result); ok {
+			for _, item := range items {
+				values = append(values, item)
+			}
+		}
+		return values
+	}).As("array")
+
+	member := parc.SequenceOf(lexeme(str), lexeme(parc.Char(":")), &jsonValue)
+	object := parc.SequenceOf(
+		lexeme(parc.Char("{")),
+		dispatch("object", map[rune]*parc.Parser{'}': parc.Char("}")},
+			parc.SequenceOf(separatedBy(member, comma), parc.Char("}").As("end of object")).Map(first)),
+	).Map(func(result parc.Result) parc.Result {
+		values := map[string]any{}
+		if members, ok := second(result).([]parc.Result); ok {
+			for _, m := range members {
+				memberArr := m.([]parc.Result)
+				values[memberArr[0].(string)] = memberArr[2]
+			}
+		}
+		return values
+	}).As("object")
+
+	// The first character of a value determines its type,
+	// so the errors inside the nested values are reported at their exact position
+	jsonValue = *lexeme(dispatch("value", map[rune]*parc.Parser{
+		'{': object,
+		'[': array,
+		'"': str,
+		'-': number, '0': number, '1': number, '2': number, '3': number, '4': number,
+		'5': number, '6': number, '7': number, '8': number, '9': number,
+		't': literal("true", true),
+		'f': literal("false", false),
+		'n': literal("null", nil),
+	}, nil))
+	value = &jsonValue
+
+	text = parc.SequenceOf(ws, value, parc.EndOfInput()).Map(second).As("JSON")
+	return text, value, str, number
+}
@@ -0,0 +1,115 @@
+package formats
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/tombenke/parc"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	value, err := ParseJSON(` {"name": "parc", "version": 1.5, "tags": ["parser", "combinator"],
+		"nested": {"empty": {}, "list": [], "flags": [true, false, null]}, "exp": -1.5e-3} `)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"name":    "parc",
+		"version": 1.5,
+		"tags":    []any{"parser", "combinator"},
+		"nested": map[string]any{
+			"empty": map[string]any{},
+			"list":  []any{},
+			"flags": []any{true, false, nil},
+		},
+		"exp": -1.5e-3,
+	}, value)
+}
+
+func TestJSONString(t *testing.T) {
+	testCases := map[string]string{
+		`""`:                    "",
+		`"Hello World"`:         "Hello World",
+		`"\"\\\/\b\f\n\r\t"`:    "\"\\/\b\f\n\r\t",
+		`"éő"`:                  "éő",
+		`"𝄞"`:                   "𝄞",
+		`"\ud834 lone"`:         "� lone",
+		`"árvíztűrő tükörfúró"`: "árvíztűrő tükörfúró",
+	}
+	for input, expected := range testCases {
+		state := JSONString.Parse(&input)
+		require.False(t, state.IsError, input)
+		require.Equal(t, expected, state.Results, input)
+	}
+}
+
+func TestJSONNumber(t *testing.T) {
+	testCases := map[string]float64{
+		"0":          0,
+		"-0":         0,
+		"42":         42,
+		"-3.1415":    -3.1415,
+		"1e10":       1e10,
+		"2.5E-3":     2.5e-3,
+		"12.0e+2":    1200,
+		"0.00000001": 0.00000001,
+	}
+	for input, expected := range testCases {
+		state := JSONNumber.Parse(&input)
+		require.False(t, state.IsError, input)
+		require.Equal(t, expected, state.Results, input)
+		require.Equal(t, len(input), state.Index, input)
+	}
+
+	input := "1e400"
+	state := JSONNumber.Parse(&input)
+	require.True(t, state.IsError)
+	require.Equal(t, "1:1: number: 1e400 is out of range", state.Err.Error())
+}
+
+func TestJSONPartial(t *testing.T) {
+	pp := parc.NewPartialParser(JSON)
+	newState := pp.Feed(`{"name": "wi`)
+	require.True(t, newState.IsIncomplete(), newState.Err)
+
+	// The document is split at every position, also in the middle of the strings and their escape sequences
+	input := `{"name": "wi\u00e9", "tags": ["a\"b", "\ud834\udd1e"], "n": -1.5e3}`
+	for i := 1; i < len(input); i++ {
+		pp := parc.NewPartialParser(JSON)
+		newState := pp.Feed(input[:i])
+		require.True(t, newState.IsIncomplete(), "%q: %v", input[:i], newState.Err)
+		newState = pp.Feed(input[i:])
+		require.True(t, newState.IsIncomplete(), "%q: %v", input[i:], newState.Err)
+		newState = pp.Close()
+		require.False(t, newState.IsError, newState.Err)
+		require.Equal(t, map[string]any{"name": "wié", "tags": []any{`a"b`, "𝄞"}, "n": -1500.0}, newState.Results)
+	}
+}
+
+func TestJSONErrorPosition(t *testing.T) {
+	_, err := ParseJSON("{\n  \"a\": [1, 2,]\n}")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "2:14: value: unexpected character ']'")
+}
+
+// TestJSONTestSuite runs the test cases stored in the JSONTestSuite format:
+// the y_ files must be accepted, the n_ files must be rejected,
+// and the i_ files are implementation defined, so they must only be parsed without panic.
+func TestJSONTestSuite(t *testing.T) {
+	files, err := filepath.Glob("testdata/json/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		name := filepath.Base(file)
+		_, err = ParseJSON(string(content))
+		switch {
+		case strings.HasPrefix(name, "y_"):
+			require.NoError(t, err, name)
+		case strings.HasPrefix(name, "n_"):
+			require.Error(t, err, name)
+		}
+	}
+}
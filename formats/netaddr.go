@@ -0,0 +1,188 @@
+package formats
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/tombenke/parc"
+)
+
+var (
+	// IPv4Addr is a parser of an IPv4 address in dotted decimal format, e.g. `192.168.0.1`.
+	// The leading zeros of the octets are not allowed. Unlike IPv4Address, its result is a netip.Addr value.
+	IPv4Addr = IPv4Address.Map(toAddr).As("IPv4 address")
+
+	// IPv6Addr is a parser of an IPv6 address in the text format of RFC 4291, e.g. `2001:db8::1`.
+	// It supports the `::` compression, the embedded IPv4 address (`::ffff:192.0.2.1`)
+	// and the zone ID (`fe80::1%eth0`). Unlike IPv6Address, its result is a netip.Addr value.
+	IPv6Addr = buildIPv6AddrParser()
+
+	// IPAddress is a parser of an IPv4 or IPv6 address. The result is a netip.Addr value.
+	IPAddress = buildIPAddressParser()
+
+	// IPPrefix is a parser of a CIDR prefix, e.g. `10.0.0.0/8` or `2001:db8::/32`.
+	// The result is a netip.Prefix value, that is not masked, so it keeps the host bits of the address.
+	IPPrefix = buildIPPrefixParser()
+
+	// MACAddress is a parser of a MAC-48 or EUI-64 address, with colon (`00:1a:2b:3c:4d:5e`)
+	// or hyphen (`00-1A-2B-3C-4D-5E`) separated octets, or with dot separated groups of four hexadecimal digits
+	// (`001a.2b3c.4d5e`). The result is a net.HardwareAddr value.
+	MACAddress = buildMACAddressParser()
+)
+
+// ParseIPAddress parses an IPv4 or IPv6 address
+func ParseIPAddress(input string) (netip.Addr, error) {
+	state := sequence(IPAddress, endOfNetAddr).Parse(&input)
+	if state.IsError {
+		return netip.Addr{}, syntaxErrorOf(state)
+	}
+	return first(state.Results).(netip.Addr), nil
+}
+
+// ParseIPPrefix parses a CIDR prefix
+func ParseIPPrefix(input string) (netip.Prefix, error) {
+	state := sequence(IPPrefix, endOfNetAddr).Parse(&input)
+	if state.IsError {
+		return netip.Prefix{}, syntaxErrorOf(state)
+	}
+	return first(state.Results).(netip.Prefix), nil
+}
+
+// ParseMACAddress parses a MAC address
+func ParseMACAddress(input string) (net.HardwareAddr, error) {
+	state := sequence(MACAddress, endOfNetAddr).Parse(&input)
+	if state.IsError {
+		return nil, syntaxErrorOf(state)
+	}
+	return first(state.Results).(net.HardwareAddr), nil
+}
+
+// endOfNetAddr matches the end of the input, and reports the unexpected character otherwise
+var endOfNetAddr = parc.EndOfInput().ErrorMap(func(state parc.ParserState) error {
+	r, _ := state.NextRune()
+	return fmt.Errorf("address: unexpected character %q", r)
+})
+
+// toAddr converts the validated text of an IP address into a netip.Addr value
+func toAddr(result parc.Result) parc.Result {
+	return netip.MustParseAddr(result.(string))
+}
+
+// buildIPv6AddrParser creates the parser of IPv6 addresses with optional zone ID
+func buildIPv6AddrParser() *parc.Parser {
+	zoneID := parc.CondMin(isUnreserved, 1).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("IPv6address: expected zone ID")
+	})
+	zone := sequence(parc.Char("%"), zoneID).Map(parc.JoinStrResults)
+	return sequence(IPv6Address, dispatch("zone", map[rune]*parc.Parser{'%': zone}, empty)).Map(func(result parc.Result) parc.Result {
+		return toAddr(joinOptionalStrResults(result))
+	}).As("IPv6 address")
+}
+
+// buildIPAddressParser creates the parser of IPv4 and IPv6 addresses.
+// The text is parsed as an IPv6 address if a colon precedes the first character that can not be in an IP address.
+func buildIPAddressParser() *parc.Parser {
+	return parc.NewParser("IP address", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		remaining := parserState.Remaining()
+		if end := strings.IndexFunc(remaining, func(r rune) bool {
+			return !parc.IsHexadecimalDigit(r) && r != '.' && r != ':'
+		}); end >= 0 {
+			remaining = remaining[:end]
+		}
+		if strings.Contains(remaining, ":") {
+			return IPv6Addr.ParserFun(parserState)
+		}
+		return IPv4Addr.ParserFun(parserState)
+	})
+}
+
+// buildIPPrefixParser creates the parser of CIDR prefixes
+func buildIPPrefixParser() *parc.Parser {
+	bits := parc.CondMin(parc.IsDigit, 1)
+	slash := expected("prefix", "/")
+	return parc.NewParser("IP prefix", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		nextState := IPAddress.ParserFun(parserState)
+		if nextState.IsError {
+			return nextState
+		}
+		addr := nextState.Results.(netip.Addr)
+		if addr.Zone() != "" {
+			return parc.UpdateParserError(parserState, fmt.Errorf("prefix: address must not have zone ID"))
+		}
+		nextState = slash.ParserFun(nextState)
+		if nextState.IsError {
+			return nextState
+		}
+		bitsState := bits.ParserFun(nextState)
+		if bitsState.IsError {
+			return parc.UpdateParserError(nextState, fmt.Errorf("prefix: expected prefix length"))
+		}
+		length, err := strconv.Atoi(bitsState.Results.(string))
+		if err != nil || length > addr.BitLen() || len(bitsState.Results.(string)) > 1 && bitsState.Results.(string)[0] == '0' {
+			return parc.UpdateParserError(nextState, fmt.Errorf("prefix: invalid prefix length %s, expected 0..%d", bitsState.Results, addr.BitLen()))
+		}
+		return parc.UpdateParserState(bitsState, bitsState.Index, netip.PrefixFrom(addr, length))
+	})
+}
+
+// buildMACAddressParser creates the parser of MAC addresses
+func buildMACAddressParser() *parc.Parser {
+	octet := parc.CondMinMax(parc.IsHexadecimalDigit, 2, 2)
+	group := parc.CondMinMax(parc.IsHexadecimalDigit, 4, 4)
+	return parc.NewParser("MAC address", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		// The separator after the first 2 or 4 digits determines the format
+		remaining := parserState.Remaining()
+		part, separator := octet, ""
+		switch {
+		case len(remaining) > 2 && (remaining[2] == ':' || remaining[2] == '-'):
+			separator = remaining[2:3]
+		case len(remaining) > 4 && remaining[4] == '.':
+			part, separator = group, "."
+		default:
+			return parc.UpdateParserError(parserState, fmt.Errorf("MAC: expected hexadecimal digits followed by ':', '-' or '.'"))
+		}
+		sep := expected("MAC", separator)
+
+		var sb strings.Builder
+		nextState := parserState
+		for i := 0; ; i++ {
+			partState := part.ParserFun(nextState)
+			if partState.IsError {
+				return parc.UpdateParserError(nextState, fmt.Errorf("MAC: expected hexadecimal digits"))
+			}
+			sb.WriteString(partState.Results.(string))
+			nextState = partState
+			// A MAC-48 address has 6 octets and an EUI-64 address has 8 octets
+			if octets := sb.Len() / 2; octets == 8 || octets == 6 && !strings.HasPrefix(nextState.Remaining(), separator) {
+				break
+			}
+			nextState = sep.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+		}
+		addr, _ := net.ParseMAC(formatMAC(sb.String()))
+		return parc.UpdateParserState(nextState, nextState.Index, addr)
+	})
+}
+
+// formatMAC inserts colons between the octets of the hexadecimal digits
+func formatMAC(digits string) string {
+	octets := make([]string, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		octets = append(octets, digits[i:i+2])
+	}
+	return strings.Join(octets, ":")
+}
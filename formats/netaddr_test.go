@@ -0,0 +1,113 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tombenke/parc"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestParseIPAddress(t *testing.T) {
+	testCases := []string{
+		"0.0.0.0", "255.255.255.255", "192.168.0.1",
+		"::", "::1", "1::", "2001:db8::ff00:42:8329", "1:2:3:4:5:6:7:8", "::ffff:10.0.0.1", "1:2:3:4:5:6:1.2.3.4",
+		"fe80::1%eth0", "FE80::ABCD%en0.1",
+	}
+	for _, input := range testCases {
+		addr, err := ParseIPAddress(input)
+		require.NoError(t, err, input)
+		require.Equal(t, netip.MustParseAddr(input), addr, input)
+	}
+}
+
+func TestParseIPAddressErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		col     int
+		message string
+	}{
+		{"256.0.0.1", 1, "IPv4address: octet 256 is out of range 0..255"},
+		{"1.2.03.4", 5, "IPv4address: octet 03 has leading zero"},
+		{"1.2.3", 6, `IPv4address: expected "."`},
+		{"1.2.3.4.5", 8, "address: unexpected character '.'"},
+		{"1:2:3:4:5:6:7", 1, "IPv6address: wrong number of groups 7"},
+		{"1::2::3", 5, "IPv6address: multiple :: in address"},
+		{"1:2:3:4:5:6:7:8:9", 16, "address: unexpected character ':'"},
+		{"1:2:g::", 5, "IPv6address: expected hexadecimal digits"},
+		{"fe80::1%", 9, "IPv6address: expected zone ID"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseIPAddress(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
+
+func TestParseIPPrefix(t *testing.T) {
+	for _, input := range []string{"10.0.0.0/8", "192.168.1.17/24", "0.0.0.0/0", "2001:db8::/32", "::1/128"} {
+		prefix, err := ParseIPPrefix(input)
+		require.NoError(t, err, input)
+		require.Equal(t, netip.MustParsePrefix(input), prefix, input)
+	}
+
+	testCases := []struct {
+		input   string
+		col     int
+		message string
+	}{
+		{"10.0.0.0/33", 10, "prefix: invalid prefix length 33, expected 0..32"},
+		{"2001:db8::/129", 12, "prefix: invalid prefix length 129, expected 0..128"},
+		{"10.0.0.0/08", 10, "prefix: invalid prefix length 08, expected 0..32"},
+		{"10.0.0.0", 9, `prefix: expected "/"`},
+		{"10.0.0.0/", 10, "prefix: expected prefix length"},
+		{"fe80::1%eth0/64", 1, "prefix: address must not have zone ID"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseIPPrefix(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
+
+func TestParseMACAddress(t *testing.T) {
+	testCases := map[string]string{
+		"00:1a:2b:3c:4d:5e":       "00:1a:2b:3c:4d:5e",
+		"00-1A-2B-3C-4D-5E":       "00:1a:2b:3c:4d:5e",
+		"001a.2b3c.4d5e":          "00:1a:2b:3c:4d:5e",
+		"00:1a:2b:3c:4d:5e:6f:70": "00:1a:2b:3c:4d:5e:6f:70",
+		"001a.2b3c.4d5e.6f70":     "00:1a:2b:3c:4d:5e:6f:70",
+	}
+	for input, expected := range testCases {
+		addr, err := ParseMACAddress(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, addr.String(), input)
+	}
+
+	for _, input := range []string{"00:1a:2b:3c:4d", "00:1a-2b:3c:4d:5e", "00:1a:2b:3c:4d:5g", "001a2b3c4d5e", "00:1a:2b:3c:4d:5e:6f"} {
+		_, err := ParseMACAddress(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestNetAddrBuildingBlocks(t *testing.T) {
+	// The parsers can be used inside other grammars, e.g. in a line of a hosts file
+	hostsLine := sequence(IPAddress, parc.CondMin(isBlank, 1), parc.CondMin(parc.IsAnyChar, 1))
+	input := "::1   localhost"
+	state := hostsLine.Parse(&input)
+	require.False(t, state.IsError)
+	require.Equal(t, netip.IPv6Loopback(), first(state.Results))
+
+	input = "00:1a:2b:3c:4d:5e 10.0.0.1"
+	state = sequence(MACAddress, parc.Space, IPv4Addr).Parse(&input)
+	require.False(t, state.IsError)
+	require.Equal(t, net.HardwareAddr{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}, first(state.Results))
+	require.Equal(t, netip.MustParseAddr("10.0.0.1"), state.Results.([]parc.Result)[2])
+}
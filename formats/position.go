@@ -0,0 +1,85 @@
+package formats
+
+import (
+	"fmt"
+
+	"github.com/tombenke/parc"
+)
+
+// Position is a location in the input
+type Position struct {
+	// Offset is the byte offset from the beginning of the input
+	Offset int
+	// Row is the line number, starting from 1
+	Row int
+	// Col is the column number in bytes, starting from 1
+	Col int
+}
+
+// String returns with the row:col format of the position
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Row, p.Col)
+}
+
+// positionOf returns with the position of the index of the parser state
+func positionOf(state parc.ParserState) Position {
+	row, col := state.IndexRowCol()
+	return Position{Offset: state.Index, Row: row, Col: col}
+}
+
+// SyntaxError is the error of parsing an input, that holds the position of the error
+type SyntaxError struct {
+	// Pos is the position of the error
+	Pos Position
+	// Err is the error reported by the parser
+	Err error
+}
+
+// Error returns with the error message
+func (e *SyntaxError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns with the error reported by the parser
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// syntaxErrorOf creates a SyntaxError from the failed parser state
+func syntaxErrorOf(state parc.ParserState) *SyntaxError {
+	return &SyntaxError{Pos: positionOf(state), Err: state.Err}
+}
+
+// Located is the result of a parser wrapped by the located function, that holds the position of the match
+type Located struct {
+	// Pos is the position of the beginning of the match
+	Pos Position
+	// Value is the result of the wrapped parser
+	Value parc.Result
+}
+
+// located returns a parser that runs the parser, and wraps its result into a Located value
+func located(parser *parc.Parser) *parc.Parser {
+	return parc.NewParser(parser.Name(), func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := parser.ParserFun(parserState)
+		if newState.IsError {
+			return newState
+		}
+		return parc.UpdateParserState(newState, newState.Index, Located{Pos: positionOf(parserState), Value: newState.Results})
+	})
+}
+
+// errorAt returns with a failed parser state, that reports the error at the given position of the input
+func errorAt(state parc.ParserState, pos Position, err error) parc.ParserState {
+	return parc.UpdateParserError(parc.UpdateParserState(state, pos.Offset, nil), err)
+}
+
+// expected returns a parser that matches the literal, and reports an error of the format at its position if it is missing
+func expected(format, literal string) *parc.Parser {
+	return parc.Str(literal).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("%s: expected %q", format, literal)
+	})
+}
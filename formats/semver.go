@@ -0,0 +1,559 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tombenke/parc"
+)
+
+// Version is a semantic version according to Semantic Versioning 2.0.0
+type Version struct {
+	// Major is the major version number
+	Major uint64
+	// Minor is the minor version number
+	Minor uint64
+	// Patch is the patch version number
+	Patch uint64
+	// Prerelease holds the dot separated identifiers of the pre-release version
+	Prerelease []string
+	// Build holds the dot separated identifiers of the build metadata
+	Build []string
+}
+
+// String returns with the text format of the version
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare compares the precedence of the versions, and returns with -1 if v is lower than other,
+// 0 if they are equal, and +1 if v is higher than other. The build metadata is ignored.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]uint64{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A pre-release version has lower precedence than the normal version
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifiers(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.Prerelease) < len(other.Prerelease):
+		return -1
+	case len(v.Prerelease) > len(other.Prerelease):
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifiers compares two pre-release identifiers.
+// The numeric identifiers are compared numerically, and they have lower precedence than the alphanumeric ones.
+func compareIdentifiers(a, b string) int {
+	aIsNumeric, bIsNumeric := isNumericIdentifier(a), isNumericIdentifier(b)
+	switch {
+	case aIsNumeric && bIsNumeric:
+		// The numeric identifiers have no leading zeros, so the longer one is the bigger
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	case aIsNumeric:
+		return -1
+	case bIsNumeric:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// isNumericIdentifier tests if the identifier consists of digits only
+func isNumericIdentifier(identifier string) bool {
+	return strings.IndexFunc(identifier, func(r rune) bool { return !parc.IsDigit(r) }) < 0
+}
+
+// Comparator is a single constraint of a version range, e.g. `>=1.2.3`
+type Comparator struct {
+	// Operator is one of `=`, `<`, `<=`, `>` and `>=`
+	Operator string
+	// Version is the version the operator compares to
+	Version Version
+}
+
+// String returns with the text format of the comparator
+func (c Comparator) String() string {
+	return c.Operator + c.Version.String()
+}
+
+// Matches tests if the version satisfies the constraint of the comparator
+func (c Comparator) Matches(v Version) bool {
+	cmp := v.Compare(c.Version)
+	switch c.Operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return cmp == 0
+}
+
+// Range is a version range expression. A version satisfies the range if it satisfies
+// all the comparators of any of the comparator sets.
+type Range struct {
+	// Sets holds the comparator sets, that are separated by `||` in the expression
+	Sets [][]Comparator
+}
+
+// String returns with the text format of the range, where the operators are resolved into comparators
+func (r Range) String() string {
+	sets := make([]string, len(r.Sets))
+	for i, set := range r.Sets {
+		comparators := make([]string, len(set))
+		for j, comparator := range set {
+			comparators[j] = comparator.String()
+		}
+		sets[i] = strings.Join(comparators, " ")
+	}
+	return strings.Join(sets, " || ")
+}
+
+// Satisfies tests if the version is in the range.
+// Like npm, a pre-release version satisfies a comparator set only if a comparator of the set
+// has a pre-release version with the same major, minor and patch numbers.
+func (r Range) Satisfies(v Version) bool {
+	for _, set := range r.Sets {
+		if satisfiesSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// satisfiesSet tests if the version satisfies all the comparators of the set
+func satisfiesSet(set []Comparator, v Version) bool {
+	for _, comparator := range set {
+		if !comparator.Matches(v) {
+			return false
+		}
+	}
+	if len(v.Prerelease) == 0 {
+		return true
+	}
+	for _, comparator := range set {
+		c := comparator.Version
+		if len(c.Prerelease) > 0 && c.Major == v.Major && c.Minor == v.Minor && c.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// SemVer is a parser of a Semantic Versioning 2.0.0 version, e.g. `1.0.0-alpha.1+001`.
+	// The result is a Version value.
+	SemVer *parc.Parser
+
+	// VersionRange is a parser of a version range expression, e.g. `>=1.2.3 <2.0.0 || ^3.1`.
+	// It supports the npm style comparators (`<`, `<=`, `>`, `>=`, `=`), the caret (`^`) and tilde (`~`) ranges,
+	// the `x`, `X` and `*` wildcards, the hyphen ranges (`1.2 - 2.3.4`) and the `||` alternatives.
+	// The comparators may also be separated by commas, like in Cargo. Like in npm, a version without operator means
+	// an exact match, or the range of the wildcards if it is partial. The result is a Range value.
+	VersionRange *parc.Parser
+
+	// CargoVersionRange is a parser of a Cargo version requirement, e.g. `1.2, <1.5` or `~1.2.3`.
+	// It supports the same comparators, caret and tilde ranges and wildcards as VersionRange, but like in Cargo,
+	// a version without operator means a caret range, e.g. `1.2.3` means `^1.2.3`, and there are no hyphen ranges
+	// and `||` alternatives. The result is a Range value with a single comparator set.
+	CargoVersionRange *parc.Parser
+)
+
+func init() {
+	SemVer, VersionRange, CargoVersionRange = buildSemVerParsers()
+}
+
+// ParseVersion parses a semantic version
+func ParseVersion(input string) (Version, error) {
+	state := sequence(SemVer, endOfSemVer).Parse(&input)
+	if state.IsError {
+		return Version{}, syntaxErrorOf(state)
+	}
+	return first(state.Results).(Version), nil
+}
+
+// ParseRange parses a version range expression with the npm semantics
+func ParseRange(input string) (Range, error) {
+	return parseRange(VersionRange, input)
+}
+
+// ParseCargoRange parses a version requirement with the Cargo semantics
+func ParseCargoRange(input string) (Range, error) {
+	return parseRange(CargoVersionRange, input)
+}
+
+// parseRange parses the whole input by the range parser
+func parseRange(parser *parc.Parser, input string) (Range, error) {
+	state := sequence(parser, endOfSemVer).Parse(&input)
+	if state.IsError {
+		return Range{}, syntaxErrorOf(state)
+	}
+	return first(state.Results).(Range), nil
+}
+
+// endOfSemVer matches the end of the input, and reports the unexpected character otherwise
+var endOfSemVer = parc.EndOfInput().ErrorMap(func(state parc.ParserState) error {
+	r, _ := state.NextRune()
+	return fmt.Errorf("semver: unexpected character %q", r)
+})
+
+// isIdentifierChar tests if rune can be in a pre-release or build identifier: [0-9A-Za-z-]
+func isIdentifierChar(r rune) bool {
+	return parc.IsAlphaNumeric(r) || r == '-'
+}
+
+// isWildcard tests if rune is a wildcard of a partial version: x, X or *
+func isWildcard(r rune) bool {
+	return r == 'x' || r == 'X' || r == '*'
+}
+
+// partialVersion is a version of a range expression, that may have missing or wildcard parts
+type partialVersion struct {
+	// numbers holds the numeric parts before the first missing or wildcard part
+	numbers []uint64
+	version Version
+}
+
+// lower returns with the lowest version of the partial version, where the missing parts are zeros
+func (p partialVersion) lower() Version {
+	if len(p.numbers) == 3 {
+		return p.version
+	}
+	v := Version{}
+	for i, parts := range []*uint64{&v.Major, &v.Minor, &v.Patch}[:len(p.numbers)] {
+		*parts = p.numbers[i]
+	}
+	return v
+}
+
+// next returns with the lowest version, that is higher than the versions of the partial version.
+// The pre-release `0` excludes the pre-releases of the next version.
+func (p partialVersion) next() Version {
+	switch len(p.numbers) {
+	case 1:
+		return Version{Major: p.numbers[0] + 1, Prerelease: []string{"0"}}
+	case 2:
+		return Version{Major: p.numbers[0], Minor: p.numbers[1] + 1, Prerelease: []string{"0"}}
+	}
+	return Version{Major: p.numbers[0], Minor: p.numbers[1], Patch: p.numbers[2] + 1, Prerelease: []string{"0"}}
+}
+
+// anyVersion is the comparator that matches every normal version
+var anyVersion = Comparator{Operator: ">=", Version: Version{}}
+
+// xRange resolves the partial version into comparators, e.g. `1.2` and `1.2.x` means `>=1.2.0 <1.3.0-0`
+func xRange(p partialVersion) []Comparator {
+	switch len(p.numbers) {
+	case 0:
+		return []Comparator{anyVersion}
+	case 3:
+		return []Comparator{{Operator: "=", Version: p.version}}
+	}
+	return []Comparator{{Operator: ">=", Version: p.lower()}, {Operator: "<", Version: p.next()}}
+}
+
+// tildeRange resolves the tilde range into comparators, that allows patch level changes
+func tildeRange(p partialVersion) []Comparator {
+	switch len(p.numbers) {
+	case 0:
+		return []Comparator{anyVersion}
+	case 1:
+		return xRange(p)
+	}
+	return []Comparator{{Operator: ">=", Version: p.lower()}, {Operator: "<", Version: partialVersion{numbers: p.numbers[:2]}.next()}}
+}
+
+// caretRange resolves the caret range into comparators, that allows the changes that do not modify
+// the left-most non-zero number
+func caretRange(p partialVersion) []Comparator {
+	if len(p.numbers) == 0 {
+		return []Comparator{anyVersion}
+	}
+	// The significant part is the left-most non-zero number, or the last specified number
+	significant := 1
+	for significant < len(p.numbers) && p.numbers[significant-1] == 0 {
+		significant++
+	}
+	return []Comparator{{Operator: ">=", Version: p.lower()}, {Operator: "<", Version: partialVersion{numbers: p.numbers[:significant]}.next()}}
+}
+
+// primitiveRange resolves the comparison of a partial version into comparators
+func primitiveRange(operator string, p partialVersion) []Comparator {
+	if operator == "" {
+		operator = "="
+	}
+	if len(p.numbers) == 3 {
+		return []Comparator{{Operator: operator, Version: p.version}}
+	}
+	switch operator {
+	case ">":
+		if len(p.numbers) == 0 {
+			return []Comparator{{Operator: "<", Version: Version{Prerelease: []string{"0"}}}}
+		}
+		v := p.next()
+		v.Prerelease = nil
+		return []Comparator{{Operator: ">=", Version: v}}
+	case ">=":
+		return []Comparator{{Operator: ">=", Version: p.lower()}}
+	case "<":
+		v := p.lower()
+		v.Prerelease = []string{"0"}
+		return []Comparator{{Operator: "<", Version: v}}
+	case "<=":
+		if len(p.numbers) == 0 {
+			return []Comparator{anyVersion}
+		}
+		return []Comparator{{Operator: "<", Version: p.next()}}
+	}
+	return xRange(p)
+}
+
+// hyphenRange resolves the `lower - upper` range into comparators
+func hyphenRange(lower, upper partialVersion) []Comparator {
+	comparators := []Comparator{}
+	if len(lower.numbers) > 0 {
+		comparators = append(comparators, Comparator{Operator: ">=", Version: lower.lower()})
+	}
+	switch len(upper.numbers) {
+	case 0:
+	case 3:
+		comparators = append(comparators, Comparator{Operator: "<=", Version: upper.version})
+	default:
+		comparators = append(comparators, Comparator{Operator: "<", Version: upper.next()})
+	}
+	if len(comparators) == 0 {
+		comparators = append(comparators, anyVersion)
+	}
+	return comparators
+}
+
+// buildSemVerParsers creates the parsers of versions, npm version ranges and Cargo version requirements
+func buildSemVerParsers() (*parc.Parser, *parc.Parser, *parc.Parser) {
+	digits := parc.CondMin(parc.IsDigit, 1)
+	// numericIdentifier matches a number without leading zeros
+	numericIdentifier := parc.NewParser("numeric identifier", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := digits.ParserFun(parserState)
+		if newState.IsError {
+			return parc.UpdateParserError(parserState, fmt.Errorf("semver: expected number"))
+		}
+		number := newState.Results.(string)
+		if len(number) > 1 && number[0] == '0' {
+			return parc.UpdateParserError(parserState, fmt.Errorf("semver: number %s has leading zero", number))
+		}
+		value, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return parc.UpdateParserError(parserState, fmt.Errorf("semver: number %s is out of range", number))
+		}
+		return parc.UpdateParserState(newState, newState.Index, value)
+	})
+
+	identifierChars := parc.CondMin(isIdentifierChar, 1)
+	identifier := func(isPrerelease bool) *parc.Parser {
+		return parc.NewParser("identifier", func(parserState parc.ParserState) parc.ParserState {
+			if parserState.IsError {
+				return parserState
+			}
+			newState := identifierChars.ParserFun(parserState)
+			if newState.IsError {
+				return parc.UpdateParserError(parserState, fmt.Errorf("semver: expected identifier"))
+			}
+			identifier := newState.Results.(string)
+			if isPrerelease && len(identifier) > 1 && identifier[0] == '0' && isNumericIdentifier(identifier) {
+				return parc.UpdateParserError(parserState, fmt.Errorf("semver: number %s has leading zero", identifier))
+			}
+			return newState
+		})
+	}
+	toStrings := func(result parc.Result) parc.Result {
+		identifiers := []string{}
+		for _, item := range result.([]parc.Result) {
+			identifiers = append(identifiers, item.(string))
+		}
+		return identifiers
+	}
+	dot := parc.Char(".")
+	prerelease := sequence(parc.Char("-"), separatedBy(identifier(true), dot).Map(toStrings)).Map(second)
+	build := sequence(parc.Char("+"), separatedBy(identifier(false), dot).Map(toStrings)).Map(second)
+	qualifiers := sequence(
+		dispatch("pre-release", map[rune]*parc.Parser{'-': prerelease}, empty),
+		dispatch("build", map[rune]*parc.Parser{'+': build}, empty),
+	).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		v := Version{}
+		if arr[0] != nil {
+			v.Prerelease = arr[0].([]string)
+		}
+		if arr[1] != nil {
+			v.Build = arr[1].([]string)
+		}
+		return v
+	})
+
+	versionDot := expected("semver", ".")
+	version := sequence(numericIdentifier, versionDot, numericIdentifier, versionDot, numericIdentifier, qualifiers).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		v := arr[5].(Version)
+		v.Major, v.Minor, v.Patch = arr[0].(uint64), arr[2].(uint64), arr[4].(uint64)
+		return v
+	}).As("version")
+
+	// The parts of a partial version are numbers or wildcards, and the missing parts are handled like wildcards
+	wildcard := parc.Cond(isWildcard).Map(func(parc.Result) parc.Result { return nil })
+	part := dispatch("version part", map[rune]*parc.Parser{'x': wildcard, 'X': wildcard, '*': wildcard}, numericIdentifier)
+	partial := parc.NewParser("partial version", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		nextState := parserState
+		if r, _ := nextState.NextRune(); r == 'v' || r == '=' {
+			_, nextState = nextState.NextRune()
+		}
+		p := partialVersion{}
+		hasWildcard := false
+		for i := 0; i < 3; i++ {
+			if i > 0 {
+				dotState := dot.ParserFun(nextState)
+				if dotState.IsError {
+					break
+				}
+				nextState = dotState
+			}
+			nextState = part.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			if number, ok := nextState.Results.(uint64); ok && !hasWildcard {
+				p.numbers = append(p.numbers, number)
+			} else {
+				hasWildcard = true
+			}
+		}
+		if len(p.numbers) == 3 {
+			nextState = qualifiers.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			p.version = nextState.Results.(Version)
+			p.version.Major, p.version.Minor, p.version.Patch = p.numbers[0], p.numbers[1], p.numbers[2]
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, p)
+	})
+
+	blanks := parc.CondMin(isBlank, 0)
+	operator := parc.Optional(parc.Choice(parc.Str(">="), parc.Str("<="), parc.Char(">"), parc.Char("<"), parc.Char("="), parc.Char("~"), parc.Char("^")))
+	hyphen := parc.SequenceOf(parc.CondMin(isBlank, 1), parc.Char("-"), parc.CondMin(isBlank, 1))
+	separator := parc.Choice(parc.SequenceOf(blanks, parc.Char(","), blanks), parc.CondMin(isBlank, 1))
+	or := parc.SequenceOf(blanks, parc.Str("||"), blanks)
+	isRangeEnd := func(state parc.ParserState) bool {
+		return state.AtTheEnd() || strings.HasPrefix(state.Remaining(), "||")
+	}
+
+	// comparatorSet returns a parser of the comparators of a range between the `||` separators.
+	// In the Cargo mode a version without operator is a caret range, and there are no hyphen ranges.
+	comparatorSet := func(cargo bool) *parc.Parser {
+		return parc.NewParser("comparator set", func(parserState parc.ParserState) parc.ParserState {
+			if parserState.IsError {
+				return parserState
+			}
+			if isRangeEnd(parserState) {
+				return parc.UpdateParserState(parserState, parserState.Index, []Comparator{anyVersion})
+			}
+			comparators := []Comparator{}
+			nextState := parserState
+			for {
+				nextState = operator.ParserFun(nextState)
+				op, _ := nextState.Results.(string)
+				nextState = blanks.ParserFun(nextState)
+				nextState = partial.ParserFun(nextState)
+				if nextState.IsError {
+					return nextState
+				}
+				p := nextState.Results.(partialVersion)
+
+				if hyphenState := hyphen.ParserFun(nextState); !cargo && op == "" && len(comparators) == 0 && !hyphenState.IsError {
+					nextState = partial.ParserFun(hyphenState)
+					if nextState.IsError {
+						return nextState
+					}
+					comparators = hyphenRange(p, nextState.Results.(partialVersion))
+					break
+				}
+
+				if cargo && op == "" {
+					op = "^"
+				}
+				switch op {
+				case "~":
+					comparators = append(comparators, tildeRange(p)...)
+				case "^":
+					comparators = append(comparators, caretRange(p)...)
+				default:
+					comparators = append(comparators, primitiveRange(op, p)...)
+				}
+
+				separatorState := separator.ParserFun(nextState)
+				if separatorState.IsError || isRangeEnd(separatorState) {
+					break
+				}
+				if r, _ := separatorState.NextRune(); r == '|' {
+					break
+				}
+				nextState = separatorState
+			}
+			return parc.UpdateParserState(nextState, nextState.Index, comparators)
+		})
+	}
+
+	versionRange := sequence(blanks, separatedBy(comparatorSet(false), or), blanks).Map(func(result parc.Result) parc.Result {
+		r := Range{}
+		for _, set := range second(result).([]parc.Result) {
+			r.Sets = append(r.Sets, set.([]Comparator))
+		}
+		return r
+	}).As("version range")
+
+	cargoVersionRange := sequence(blanks, comparatorSet(true), blanks).Map(func(result parc.Result) parc.Result {
+		return Range{Sets: [][]Comparator{second(result).([]Comparator)}}
+	}).As("cargo version range")
+
+	return version, versionRange, cargoVersionRange
+}
@@ -0,0 +1,187 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	testCases := map[string]Version{
+		"1.2.3":                     {Major: 1, Minor: 2, Patch: 3},
+		"0.0.0":                     {},
+		"1.0.0-alpha":               {Major: 1, Prerelease: []string{"alpha"}},
+		"1.0.0-alpha.1":             {Major: 1, Prerelease: []string{"alpha", "1"}},
+		"1.0.0-0.3.7":               {Major: 1, Prerelease: []string{"0", "3", "7"}},
+		"1.0.0-x-y-z.--":            {Major: 1, Prerelease: []string{"x-y-z", "--"}},
+		"1.0.0+20130313144700":      {Major: 1, Build: []string{"20130313144700"}},
+		"1.0.0-beta+exp.sha.5114f8": {Major: 1, Prerelease: []string{"beta"}, Build: []string{"exp", "sha", "5114f8"}},
+		"1.0.0+001":                 {Major: 1, Build: []string{"001"}},
+	}
+	for input, expected := range testCases {
+		v, err := ParseVersion(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, v, input)
+		require.Equal(t, input, v.String(), input)
+	}
+}
+
+func TestParseVersionErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		col     int
+		message string
+	}{
+		{"1.2", 4, `semver: expected "."`},
+		{"01.2.3", 1, "semver: number 01 has leading zero"},
+		{"1.2.3-01", 7, "semver: number 01 has leading zero"},
+		{"1.2.3-alpha..1", 13, "semver: expected identifier"},
+		{"1.2.3+", 7, "semver: expected identifier"},
+		{"1.2.3 ", 6, "semver: unexpected character ' '"},
+		{"1.2.99999999999999999999", 5, "semver: number 99999999999999999999 is out of range"},
+		{"v1.2.3", 1, "semver: expected number"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseVersion(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// The versions in the order of precedence from the Semantic Versioning specification
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0", "2.1.0", "2.1.1",
+	}
+	versions := []Version{}
+	for i := len(ordered) - 1; i >= 0; i-- {
+		v, err := ParseVersion(ordered[i])
+		require.NoError(t, err)
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Compare(versions[j]) < 0 })
+	for i, v := range versions {
+		require.Equal(t, ordered[i], v.String())
+	}
+
+	a, _ := ParseVersion("1.0.0+build.1")
+	b, _ := ParseVersion("1.0.0+build.2")
+	require.Equal(t, 0, a.Compare(b))
+}
+
+func TestParseRange(t *testing.T) {
+	testCases := map[string]string{
+		">=1.2.3 <2.0.0 || ^3.1": ">=1.2.3 <2.0.0 || >=3.1.0 <4.0.0-0",
+		"":                       ">=0.0.0",
+		"*":                      ">=0.0.0",
+		"1.x":                    ">=1.0.0 <2.0.0-0",
+		"1.2.X":                  ">=1.2.0 <1.3.0-0",
+		"1.2.3":                  "=1.2.3",
+		"v1.2.3":                 "=1.2.3",
+		"~1.2.3":                 ">=1.2.3 <1.3.0-0",
+		"~1.2":                   ">=1.2.0 <1.3.0-0",
+		"~1":                     ">=1.0.0 <2.0.0-0",
+		"^1.2.3":                 ">=1.2.3 <2.0.0-0",
+		"^0.2.3":                 ">=0.2.3 <0.3.0-0",
+		"^0.0.3":                 ">=0.0.3 <0.0.4-0",
+		"^0.0.x":                 ">=0.0.0 <0.1.0-0",
+		"^0.x":                   ">=0.0.0 <1.0.0-0",
+		"^1.2.3-beta.2":          ">=1.2.3-beta.2 <2.0.0-0",
+		"1.2.3 - 2.3.4":          ">=1.2.3 <=2.3.4",
+		"1.2 - 2.3":              ">=1.2.0 <2.4.0-0",
+		"1.2.3 - 2":              ">=1.2.3 <3.0.0-0",
+		">1.2":                   ">=1.3.0",
+		"<1.2":                   "<1.2.0-0",
+		"<=1.2":                  "<1.3.0-0",
+		">= 1.2.3, < 1.5":        ">=1.2.3 <1.5.0-0",
+		" >1.0.0  ||  <0.1.0 ":   ">1.0.0 || <0.1.0",
+	}
+	for input, expected := range testCases {
+		r, err := ParseRange(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, r.String(), input)
+	}
+}
+
+func TestParseCargoRange(t *testing.T) {
+	testCases := map[string]string{
+		"1.2.3":         ">=1.2.3 <2.0.0-0",
+		"1.2":           ">=1.2.0 <2.0.0-0",
+		"0.2.3":         ">=0.2.3 <0.3.0-0",
+		"0":             ">=0.0.0 <1.0.0-0",
+		"*":             ">=0.0.0",
+		"1.*":           ">=1.0.0 <2.0.0-0",
+		"=1.2.3":        "=1.2.3",
+		"~1.2":          ">=1.2.0 <1.3.0-0",
+		">= 1.2, < 1.5": ">=1.2.0 <1.5.0-0",
+		"1.2, <1.4":     ">=1.2.0 <2.0.0-0 <1.4.0-0",
+	}
+	for input, expected := range testCases {
+		r, err := ParseCargoRange(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, r.String(), input)
+	}
+
+	// There are no alternatives and hyphen ranges in Cargo
+	for _, input := range []string{"1.2 || 2", "1.2.3 - 2.3.4"} {
+		_, err := ParseCargoRange(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestRangeSatisfies(t *testing.T) {
+	testCases := []struct {
+		rangeExpression string
+		version         string
+		satisfies       bool
+	}{
+		{">=1.2.3 <2.0.0 || ^3.1", "1.9.9", true},
+		{">=1.2.3 <2.0.0 || ^3.1", "2.0.0", false},
+		{">=1.2.3 <2.0.0 || ^3.1", "3.5.0", true},
+		{">=1.2.3 <2.0.0 || ^3.1", "4.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"~1.2.3", "1.3.0", false},
+		{"1.x", "1.99.0", true},
+		{"*", "1.0.0-beta", false},
+		{"^1.2.3-beta.2", "1.2.3-beta.4", true},
+		{"^1.2.3-beta.2", "1.2.4-beta.4", false},
+		{"^1.2.3-beta.2", "1.2.4", true},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3.4", "2.3.5", false},
+		{"<2.0.0", "2.0.0-alpha", false},
+	}
+	for _, testCase := range testCases {
+		r, err := ParseRange(testCase.rangeExpression)
+		require.NoError(t, err, testCase.rangeExpression)
+		v, err := ParseVersion(testCase.version)
+		require.NoError(t, err, testCase.version)
+		require.Equal(t, testCase.satisfies, r.Satisfies(v), "%s %s", testCase.rangeExpression, testCase.version)
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		col     int
+		message string
+	}{
+		{">=1.2.3 <2.0.0 |", 16, "semver: unexpected character '|'"},
+		{">=1.2.3 foo", 9, "semver: expected number"},
+		{">=1.2.3,", 8, "semver: unexpected character ','"},
+		{"^1.02", 4, "semver: number 02 has leading zero"},
+		{"1.2.3 - ", 9, "semver: expected number"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseRange(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
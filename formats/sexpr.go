@@ -0,0 +1,425 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tombenke/parc"
+)
+
+// SExprKind is the kind of an S-expression
+type SExprKind int
+
+const (
+	// SExprNil is the empty list `()`
+	SExprNil SExprKind = iota
+	// SExprSymbol is a symbol, e.g. `define` or `+`. Its value is the name of the symbol as a string.
+	SExprSymbol
+	// SExprInteger is an integer, e.g. `-42`. Its value is an int64.
+	SExprInteger
+	// SExprFloat is a floating point number, e.g. `3.14` or `1e-3`. Its value is a float64.
+	SExprFloat
+	// SExprString is a string literal, e.g. `"hello\n"`. Its value is the decoded string.
+	SExprString
+	// SExprCons is a pair, that is a cell of a list
+	SExprCons
+)
+
+// String returns with the name of the kind
+func (k SExprKind) String() string {
+	switch k {
+	case SExprNil:
+		return "nil"
+	case SExprSymbol:
+		return "symbol"
+	case SExprInteger:
+		return "integer"
+	case SExprFloat:
+		return "float"
+	case SExprString:
+		return "string"
+	case SExprCons:
+		return "cons"
+	default:
+		return fmt.Sprintf("SExprKind(%d)", int(k))
+	}
+}
+
+// Span is a range of the input
+type Span struct {
+	// Start is the position of the first character
+	Start Position
+	// End is the position right after the last character
+	End Position
+}
+
+// SExpr is a node of an S-expression. The lists are chains of cons cells, that end with the empty list,
+// so `(a b)` is read as the `(a . (b . ()))` pairs.
+type SExpr struct {
+	// Kind is the kind of the node
+	Kind SExprKind
+	// Value is the value of the atoms, see the kinds for their types
+	Value any
+	// Car is the first item of a cons cell
+	Car *SExpr
+	// Cdr is the second item of a cons cell, that is the rest of the list
+	Cdr *SExpr
+	// Span is the range of the input the node was read from.
+	// The span of a cons cell lasts from its car to the end of the list.
+	Span Span
+}
+
+// IsAtom tests if the node is not a cons cell
+func (e *SExpr) IsAtom() bool {
+	return e.Kind != SExprCons
+}
+
+// List returns with the items of a proper list, that ends with the empty list.
+// It returns false if the node is not a proper list.
+func (e *SExpr) List() ([]*SExpr, bool) {
+	items := []*SExpr{}
+	for ; e.Kind == SExprCons; e = e.Cdr {
+		items = append(items, e.Car)
+	}
+	return items, e.Kind == SExprNil
+}
+
+// String returns with the textual form of the S-expression, that can be read back by the parser.
+// The quote shorthands are written in their list form, e.g. `(quote x)`.
+func (e *SExpr) String() string {
+	switch e.Kind {
+	case SExprNil:
+		return "()"
+	case SExprSymbol:
+		return e.Value.(string)
+	case SExprInteger:
+		return strconv.FormatInt(e.Value.(int64), 10)
+	case SExprFloat:
+		text := strconv.FormatFloat(e.Value.(float64), 'g', -1, 64)
+		if !strings.ContainsAny(text, ".eIN") {
+			text += ".0"
+		}
+		return text
+	case SExprString:
+		return strconv.Quote(e.Value.(string))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(")
+	for ; e.Kind == SExprCons; e = e.Cdr {
+		sb.WriteString(e.Car.String())
+		if e.Cdr.Kind == SExprCons {
+			sb.WriteString(" ")
+		}
+	}
+	if e.Kind != SExprNil {
+		sb.WriteString(" . ")
+		sb.WriteString(e.String())
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+var (
+	// SExpression is a parser of a single S-expression surrounded by optional whitespace and comments.
+	// The atoms are symbols, integers, floats and string literals, and the lists may be dotted, e.g. `(a b . c)`.
+	// The 'x, `x, ,x and ,@x shorthands are read as the `(quote x)`, `(quasiquote x)`,
+	// `(unquote x)` and `(unquote-splicing x)` lists.
+	// The line comments start with `;`, and the block comments are enclosed by `#|` and `|#`, and they may be nested.
+	// The string literals may contain the escape sequences of Go string literals except the octal ones.
+	// The result is an *SExpr value.
+	SExpression *parc.Parser
+
+	// SExpressions is a parser of a sequence of S-expressions surrounded by optional whitespace and comments,
+	// e.g. the content of a source file. The result is an []*SExpr value.
+	SExpressions *parc.Parser
+)
+
+func init() {
+	SExpression, SExpressions = buildSExprParsers()
+}
+
+// ParseSExpr parses the input as a single S-expression
+func ParseSExpr(input string) (*SExpr, error) {
+	state := sequence(SExpression, endOfSExpr).Parse(&input)
+	if state.IsError {
+		return nil, syntaxErrorOf(state)
+	}
+	return first(state.Results).(*SExpr), nil
+}
+
+// ParseSExprs parses the input as a sequence of S-expressions
+func ParseSExprs(input string) ([]*SExpr, error) {
+	state := sequence(SExpressions, endOfSExpr).Parse(&input)
+	if state.IsError {
+		return nil, syntaxErrorOf(state)
+	}
+	return first(state.Results).([]*SExpr), nil
+}
+
+// endOfSExpr matches the end of the input, and reports the unexpected character otherwise
+var endOfSExpr = parc.EndOfInput().ErrorMap(func(state parc.ParserState) error {
+	r, _ := state.NextRune()
+	return fmt.Errorf("sexpr: unexpected character %q", r)
+})
+
+// isSExprDelimiter tests if the rune terminates a symbol or a number
+func isSExprDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()";'`+"`,", r)
+}
+
+// sexprQuotes holds the symbols of the quote shorthands
+var sexprQuotes = map[string]string{"'": "quote", "`": "quasiquote", ",": "unquote", ",@": "unquote-splicing"}
+
+// sexprStringOptions are the options of the string literals, that are the Go string literals without the octal escapes,
+// and they may span multiple lines
+var sexprStringOptions = parc.QuotedStringOptions{
+	Quote:              '"',
+	Escapes:            map[rune]string{'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v", '\\': `\`, '"': `"`, '\'': "'"},
+	HexEscapes:         true,
+	UnicodeEscapes:     true,
+	LongUnicodeEscapes: true,
+	ByteEscapes:        true,
+	Multiline:          true,
+}
+
+// sexprSpace is a parser of whitespace and comments, that fails only on unterminated block comments.
+// It keeps the result of the previous parser, so it can follow the parsed items.
+var sexprSpace = parc.NewParser("whitespace", func(parserState parc.ParserState) parc.ParserState {
+	if parserState.IsError {
+		return parserState
+	}
+	nextState := parserState
+	for {
+		remaining := nextState.Remaining()
+		r, size := utf8.DecodeRuneInString(remaining)
+		switch {
+		case size > 0 && unicode.IsSpace(r):
+			nextState = nextState.Consume(size)
+		case r == ';':
+			if end := strings.IndexByte(remaining, '\n'); end >= 0 {
+				nextState = nextState.Consume(end + 1)
+			} else {
+				nextState = nextState.Consume(len(remaining))
+			}
+		case strings.HasPrefix(remaining, "#|"):
+			length := blockCommentLength(remaining)
+			if length < 0 {
+				return parc.UpdateParserError(nextState, fmt.Errorf("sexpr: unterminated block comment"))
+			}
+			nextState = nextState.Consume(length)
+		default:
+			return nextState
+		}
+	}
+})
+
+// blockCommentLength returns with the length of the nested block comment at the beginning of the text,
+// or -1 if it is unterminated
+func blockCommentLength(text string) int {
+	depth := 0
+	for i := 0; i+1 < len(text); i++ {
+		switch text[i : i+2] {
+		case "#|":
+			depth++
+			i++
+		case "|#":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// buildSExprParsers creates the parsers of S-expressions
+func buildSExprParsers() (expression, expressions *parc.Parser) {
+	var datum parc.Parser
+
+	// The numbers are the tokens that match the number grammar as a whole, the other tokens are symbols
+	token := parc.CondMin(func(r rune) bool { return !isSExprDelimiter(r) }, 1)
+	sign := parc.Optional(parc.Cond(func(r rune) bool { return r == '+' || r == '-' }))
+	integerText := sequence(sign, parc.Digits, parc.EndOfInput())
+	exponent := sequence(parc.AnyChar, sign, parc.Digits)
+	floatText := sequence(
+		sign,
+		parc.Choice(
+			sequence(parc.Digits, parc.Char("."), parc.CondMin(parc.IsDigit, 0)),
+			sequence(parc.Char("."), parc.Digits),
+			parc.Digits,
+		),
+		dispatch("exponent", map[rune]*parc.Parser{'e': exponent, 'E': exponent}, empty),
+		parc.EndOfInput(),
+	)
+	atom := parc.NewParser("atom", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := token.ParserFun(parserState)
+		if newState.IsError {
+			r, _ := parserState.NextRune()
+			return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: unexpected character %q", r))
+		}
+		text := newState.Results.(string)
+		expr := &SExpr{Kind: SExprSymbol, Value: text}
+		switch {
+		case text == ".":
+			return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: unexpected '.'"))
+		case !integerText.Parse(&text).IsError:
+			value, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: integer %s is out of range", text))
+			}
+			expr = &SExpr{Kind: SExprInteger, Value: value}
+		case strings.ContainsAny(text, ".eE") && !floatText.Parse(&text).IsError:
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: float %s is out of range", text))
+			}
+			expr = &SExpr{Kind: SExprFloat, Value: value}
+		}
+		return parc.UpdateParserState(newState, newState.Index, expr)
+	})
+
+	// The unterminated strings are reported by the message of the S-expression reader, not by the one of parc.QuotedString
+	quotedString := parc.QuotedString(sexprStringOptions).As("sexpr")
+	str := parc.NewParser("string", func(parserState parc.ParserState) parc.ParserState {
+		newState := quotedString.ParserFun(parserState)
+		if newState.IsError && !newState.IsIncomplete() && strings.HasSuffix(newState.Err.Error(), "sexpr: unterminated literal") {
+			return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: unterminated string"))
+		}
+		if newState.IsError {
+			return newState
+		}
+		return parc.UpdateParserState(newState, newState.Index, &SExpr{Kind: SExprString, Value: newState.Results.(string)})
+	})
+
+	quoted := parc.NewParser("quoted", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		shorthand := parserState.Remaining()[:1]
+		if strings.HasPrefix(parserState.Remaining(), ",@") {
+			shorthand = ",@"
+		}
+		quoteState := parserState.Consume(len(shorthand))
+		nextState := datum.ParserFun(sexprSpace.ParserFun(quoteState))
+		if nextState.IsError {
+			return nextState
+		}
+		quote := &SExpr{Kind: SExprSymbol, Value: sexprQuotes[shorthand], Span: Span{positionOf(parserState), positionOf(quoteState)}}
+		end := positionOf(nextState)
+		expr := nextState.Results.(*SExpr)
+		list := &SExpr{Kind: SExprCons, Car: expr, Cdr: &SExpr{Kind: SExprNil, Span: Span{end, end}}, Span: Span{expr.Span.Start, end}}
+		return parc.UpdateParserState(nextState, nextState.Index, &SExpr{Kind: SExprCons, Car: quote, Cdr: list, Span: Span{quote.Span.Start, end}})
+	})
+
+	isDot := func(state parc.ParserState) bool {
+		remaining := state.Remaining()
+		if !strings.HasPrefix(remaining, ".") {
+			return false
+		}
+		r, size := utf8.DecodeRuneInString(remaining[1:])
+		return size == 0 || isSExprDelimiter(r)
+	}
+	closing := expected("sexpr", ")")
+	list := parc.NewParser("list", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		items := []*SExpr{}
+		var tail *SExpr
+		nextState := sexprSpace.ParserFun(parserState.Consume(1))
+		for !nextState.IsError && !strings.HasPrefix(nextState.Remaining(), ")") {
+			if nextState.AtTheEnd() {
+				return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: unclosed list"))
+			}
+			if isDot(nextState) && len(items) > 0 {
+				// The dotted tail must be the last item of the list
+				tailState := datum.ParserFun(sexprSpace.ParserFun(nextState.Consume(1)))
+				if tailState.IsError {
+					return tailState
+				}
+				tail = tailState.Results.(*SExpr)
+				nextState = sexprSpace.ParserFun(tailState)
+				break
+			}
+			itemState := datum.ParserFun(nextState)
+			if itemState.IsError {
+				return itemState
+			}
+			items = append(items, itemState.Results.(*SExpr))
+			nextState = sexprSpace.ParserFun(itemState)
+		}
+		if nextState.IsError {
+			return nextState
+		}
+		endState := closing.ParserFun(nextState)
+		if endState.IsError {
+			return endState
+		}
+
+		end := positionOf(endState)
+		if tail == nil {
+			tail = &SExpr{Kind: SExprNil, Span: Span{positionOf(nextState), end}}
+		}
+		for i := len(items) - 1; i >= 0; i-- {
+			tail = &SExpr{Kind: SExprCons, Car: items[i], Cdr: tail, Span: Span{items[i].Span.Start, end}}
+		}
+		tail.Span.Start = positionOf(parserState)
+		return parc.UpdateParserState(endState, endState.Index, tail)
+	})
+
+	unexpectedClosing := parc.NewParser("unexpected closing", func(parserState parc.ParserState) parc.ParserState {
+		return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: unexpected ')'"))
+	})
+	unexpectedEnd := parc.NewParser("unexpected end", func(parserState parc.ParserState) parc.ParserState {
+		return parc.UpdateParserError(parserState, fmt.Errorf("sexpr: unexpected end of input"))
+	})
+	node := dispatch("S-expression", map[rune]*parc.Parser{
+		'(': list, ')': unexpectedClosing, '"': str,
+		'\'': quoted, '`': quoted, ',': quoted,
+		endOfInputRune: unexpectedEnd,
+	}, atom)
+	datum = *parc.NewParser("S-expression", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := node.ParserFun(parserState)
+		if newState.IsError {
+			return newState
+		}
+		// The span of the atoms is set here, the lists and the quoted forms set their own spans
+		if expr := newState.Results.(*SExpr); expr.Kind != SExprCons && expr.Kind != SExprNil {
+			expr.Span = Span{positionOf(parserState), positionOf(newState)}
+		}
+		return newState
+	})
+
+	expression = sequence(sexprSpace, &datum, sexprSpace).Map(second).As("S-expression")
+	expressions = parc.NewParser("S-expressions", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		result := []*SExpr{}
+		nextState := sexprSpace.ParserFun(parserState)
+		for !nextState.IsError && !nextState.AtTheEnd() {
+			itemState := datum.ParserFun(nextState)
+			if itemState.IsError {
+				return itemState
+			}
+			result = append(result, itemState.Results.(*SExpr))
+			nextState = sexprSpace.ParserFun(itemState)
+		}
+		if nextState.IsError {
+			return nextState
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, result)
+	})
+	return expression, expressions
+}
@@ -0,0 +1,146 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseSExprAtoms(t *testing.T) {
+	testCases := []struct {
+		input string
+		kind  SExprKind
+		value any
+	}{
+		{"foo", SExprSymbol, "foo"},
+		{"+", SExprSymbol, "+"},
+		{"-", SExprSymbol, "-"},
+		{"...", SExprSymbol, "..."},
+		{"set-car!", SExprSymbol, "set-car!"},
+		{"1+", SExprSymbol, "1+"},
+		{"#t", SExprSymbol, "#t"},
+		{"42", SExprInteger, int64(42)},
+		{"-17", SExprInteger, int64(-17)},
+		{"+5", SExprInteger, int64(5)},
+		{"3.14", SExprFloat, 3.14},
+		{"-.5", SExprFloat, -0.5},
+		{"1.", SExprFloat, 1.0},
+		{"6.02e23", SExprFloat, 6.02e23},
+		{"1E-3", SExprFloat, 0.001},
+		{"1e", SExprSymbol, "1e"},
+		{`"hello"`, SExprString, "hello"},
+		{`"a\"b\\c\n\t\x41é\U0001F600"`, SExprString, "a\"b\\c\n\tAé😀"},
+		{`""`, SExprString, ""},
+	}
+	for _, testCase := range testCases {
+		expr, err := ParseSExpr(testCase.input)
+		require.NoError(t, err, testCase.input)
+		require.Equal(t, testCase.kind, expr.Kind, testCase.input)
+		require.Equal(t, testCase.value, expr.Value, testCase.input)
+		require.Equal(t, Span{Position{0, 1, 1}, Position{len(testCase.input), 1, len(testCase.input) + 1}}, expr.Span, testCase.input)
+	}
+}
+
+func TestParseSExprLists(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"()", "()"},
+		{"( )", "()"},
+		{"(a b c)", "(a b c)"},
+		{"(define (square x)\n\t(* x x))", "(define (square x) (* x x))"},
+		{"(a . b)", "(a . b)"},
+		{"(a b . c)", "(a b . c)"},
+		{"(a . (b . (c . ())))", "(a b c)"},
+		{"(a .b)", "(a .b)"},
+		{"'x", "(quote x)"},
+		{"'(1 2)", "(quote (1 2))"},
+		{"`(a ,b ,@c)", "(quasiquote (a (unquote b) (unquote-splicing c)))"},
+		{`(print "hi" 1.5 2.0)`, `(print "hi" 1.5 2.0)`},
+		{"  ; leading comment\n(a ; inline comment\n b) ; trailing", "(a b)"},
+		{"(a #| block #| nested |# comment |# b)", "(a b)"},
+	}
+	for _, testCase := range testCases {
+		expr, err := ParseSExpr(testCase.input)
+		require.NoError(t, err, testCase.input)
+		require.Equal(t, testCase.expected, expr.String(), testCase.input)
+
+		reread, err := ParseSExpr(expr.String())
+		require.NoError(t, err, testCase.input)
+		require.Equal(t, testCase.expected, reread.String(), testCase.input)
+	}
+}
+
+func TestSExprStructure(t *testing.T) {
+	expr, err := ParseSExpr("(a\n  (b 1) . \"c\")")
+	require.NoError(t, err)
+	require.Equal(t, SExprCons, expr.Kind)
+	require.Equal(t, Span{Position{0, 1, 1}, Position{17, 2, 15}}, expr.Span)
+
+	require.Equal(t, "a", expr.Car.Value)
+	require.Equal(t, Span{Position{1, 1, 2}, Position{2, 1, 3}}, expr.Car.Span)
+
+	inner := expr.Cdr.Car
+	require.Equal(t, Span{Position{5, 2, 3}, Position{10, 2, 8}}, inner.Span)
+	items, ok := inner.List()
+	require.True(t, ok)
+	require.Len(t, items, 2)
+	require.Equal(t, int64(1), items[1].Value)
+
+	require.Equal(t, SExprString, expr.Cdr.Cdr.Kind)
+	require.Equal(t, Position{13, 2, 11}, expr.Cdr.Cdr.Span.Start)
+	_, ok = expr.List()
+	require.False(t, ok)
+	require.False(t, expr.IsAtom())
+	require.True(t, expr.Car.IsAtom())
+}
+
+func TestParseSExprs(t *testing.T) {
+	exprs, err := ParseSExprs("; program\n(define x 1)\n(display x) 'done\n")
+	require.NoError(t, err)
+	require.Len(t, exprs, 3)
+	require.Equal(t, "(define x 1)", exprs[0].String())
+	require.Equal(t, 2, exprs[0].Span.Start.Row)
+	require.Equal(t, "(display x)", exprs[1].String())
+	require.Equal(t, "(quote done)", exprs[2].String())
+
+	exprs, err = ParseSExprs(" ; nothing but a comment ")
+	require.NoError(t, err)
+	require.Empty(t, exprs)
+}
+
+func TestParseSExprErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		row     int
+		col     int
+		message string
+	}{
+		{"", 1, 1, "sexpr: unexpected end of input"},
+		{"(a b", 1, 1, "sexpr: unclosed list"},
+		{"(a\n (b c)", 1, 1, "sexpr: unclosed list"},
+		{"(a b))", 1, 6, "sexpr: unexpected character ')'"},
+		{")", 1, 1, "sexpr: unexpected ')'"},
+		{"(. a)", 1, 2, "sexpr: unexpected '.'"},
+		{"(a . b c)", 1, 8, `sexpr: expected ")"`},
+		{"(a . )", 1, 6, "sexpr: unexpected ')'"},
+		{`"abc`, 1, 1, "sexpr: unterminated string"},
+		{`"a\qb"`, 1, 3, `sexpr: invalid escape sequence \q`},
+		{`"a\x4"`, 1, 3, `sexpr: invalid escape sequence \x`},
+		{"99999999999999999999", 1, 1, "sexpr: integer 99999999999999999999 is out of range"},
+		{"1e999", 1, 1, "sexpr: float 1e999 is out of range"},
+		{"(a #| b)", 1, 4, "sexpr: unterminated block comment"},
+		{"'", 1, 2, "sexpr: unexpected end of input"},
+		{"a b", 1, 3, "sexpr: unexpected character 'b'"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseSExpr(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.row, syntaxError.Pos.Row, testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
@@ -0,0 +1,315 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tombenke/parc"
+)
+
+// SyslogMessage is a message of the syslog protocol
+type SyslogMessage struct {
+	// Facility is the facility code of the PRI part, in the range of 0..23
+	Facility int
+	// Severity is the severity code of the PRI part, in the range of 0..7
+	Severity int
+	// Version is the version of the protocol, that is 1 for RFC 5424 and 0 for RFC 3164 messages
+	Version int
+	// Timestamp is the time of the message, or the zero time if it is missing
+	Timestamp time.Time
+	// Hostname is the name or the address of the originating host
+	Hostname string
+	// AppName is the name of the originating application, that is the TAG of RFC 3164 messages
+	AppName string
+	// ProcID is the process ID of the originating application
+	ProcID string
+	// MsgID is the type of the message
+	MsgID string
+	// StructuredData holds the SD-ELEMENT parts of RFC 5424 messages
+	StructuredData []SDElement
+	// Message is the free-form message, without the leading byte order mark
+	Message string
+}
+
+// SDElement is a structured data element of an RFC 5424 syslog message, e.g. `[exampleSDID@32473 iut="3"]`
+type SDElement struct {
+	// ID is the SD-ID of the element
+	ID string
+	// Params are the parameters of the element in their order of appearance
+	Params []SDParam
+}
+
+// SDParam is a parameter of a structured data element
+type SDParam struct {
+	Name  string
+	Value string
+}
+
+// RFC5424Syslog is a parser of a syslog message in the format of RFC 5424,
+// e.g. `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3"] message`.
+// The missing values (`-`) result in empty fields. The message lasts until the end of the line.
+// The result is a SyslogMessage value.
+var RFC5424Syslog = buildRFC5424SyslogParser()
+
+// RFC3164Syslog returns a parser of a syslog message in the BSD format of RFC 3164,
+// e.g. `<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed`.
+// The timestamp of these messages does not contain the year and the time zone,
+// so the timestamp is taken in the given year in UTC. The message lasts until the end of the line.
+// The result is a SyslogMessage value.
+func RFC3164Syslog(year int) *parc.Parser {
+	timestamp := parc.NewParser("RFC 3164 timestamp", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		remaining := parserState.Remaining()
+		if len(remaining) < len(time.Stamp) {
+			return parc.UpdateParserError(parserState, fmt.Errorf("syslog: expected timestamp"))
+		}
+		t, err := time.Parse(time.Stamp, remaining[:len(time.Stamp)])
+		if err != nil {
+			return parc.UpdateParserError(parserState, fmt.Errorf("syslog: invalid timestamp %q", remaining[:len(time.Stamp)]))
+		}
+		t = time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+		return parc.UpdateParserState(parserState, parserState.Index+len(time.Stamp), t)
+	})
+	hostname := parc.CondMin(func(r rune) bool { return r != ' ' && isNotLineEnd(r) }, 1).ErrorMap(func(parc.ParserState) error {
+		return fmt.Errorf("syslog: expected HOSTNAME")
+	})
+	// The TAG is optional, and the message starts right after the header without it
+	isTagChar := func(r rune) bool { return r != ':' && r != '[' && r != ' ' && isNotLineEnd(r) }
+	procID := sequence(parc.Char("["), parc.CondMin(func(r rune) bool { return r != ']' && isNotLineEnd(r) }, 1), parc.Char("]")).Map(second)
+	tag := parc.Choice(
+		sequence(parc.CondMin(isTagChar, 1), dispatch("PID", map[rune]*parc.Parser{'[': procID}, empty), parc.Char(":"), parc.Optional(parc.Char(" "))),
+		empty,
+	)
+	message := parc.CondMin(isNotLineEnd, 0)
+
+	return sequence(syslogPri, timestamp, syslogSpace, hostname, syslogSpace, tag, message).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		msg := SyslogMessage{
+			Facility:  arr[0].(int) / 8,
+			Severity:  arr[0].(int) % 8,
+			Timestamp: arr[1].(time.Time),
+			Hostname:  arr[3].(string),
+			Message:   arr[6].(string),
+		}
+		if tag, ok := arr[5].([]parc.Result); ok {
+			msg.AppName = tag[0].(string)
+			if tag[1] != nil {
+				msg.ProcID = tag[1].(string)
+			}
+		}
+		return msg
+	}).As("RFC 3164 syslog message")
+}
+
+// ParseSyslog parses a single syslog message, followed by an optional line end.
+// The message is parsed as an RFC 5424 message if a version number follows the PRI part,
+// otherwise as an RFC 3164 message. The timestamps of the RFC 3164 messages have no year, so they are taken
+// in the year of the reference time, e.g. the time of receiving the message, or in the previous year,
+// if the timestamp would be more than a day later than the reference time, like a December message received in January.
+func ParseSyslog(input string, reference time.Time) (SyslogMessage, error) {
+	reference = reference.UTC()
+	if end := strings.IndexByte(input, '>'); end >= 0 && end+1 < len(input) && parc.IsDigit(rune(input[end+1])) {
+		return parseSyslogMessage(RFC5424Syslog, input)
+	}
+	msg, err := parseSyslogMessage(RFC3164Syslog(reference.Year()), input)
+	if err == nil && msg.Timestamp.After(reference.Add(24*time.Hour)) {
+		return parseSyslogMessage(RFC3164Syslog(reference.Year()-1), input)
+	}
+	return msg, err
+}
+
+// parseSyslogMessage parses the whole input by the syslog message parser
+func parseSyslogMessage(parser *parc.Parser, input string) (SyslogMessage, error) {
+	state := sequence(parser, parc.Optional(parc.Choice(parc.Newline, parc.Crlf)), endOfSyslog).Parse(&input)
+	if state.IsError {
+		return SyslogMessage{}, syntaxErrorOf(state)
+	}
+	return first(state.Results).(SyslogMessage), nil
+}
+
+// endOfSyslog matches the end of the input, and reports the unexpected character otherwise
+var endOfSyslog = parc.EndOfInput().ErrorMap(func(state parc.ParserState) error {
+	r, _ := state.NextRune()
+	return fmt.Errorf("syslog: unexpected character %q", r)
+})
+
+// syslogSpace is the separator of the header fields
+var syslogSpace = expected("syslog", " ")
+
+// syslogPri is a parser of the PRI part, e.g. `<165>`. The result is the PRIVAL as an int value.
+var syslogPri = buildSyslogPriParser()
+
+// buildSyslogPriParser creates the parser of the PRI part
+func buildSyslogPriParser() *parc.Parser {
+	digits := parc.CondMinMax(parc.IsDigit, 1, 3)
+	prival := parc.NewParser("PRIVAL", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := digits.ParserFun(parserState)
+		if newState.IsError {
+			return parc.UpdateParserError(parserState, fmt.Errorf("syslog: expected PRI value"))
+		}
+		text := newState.Results.(string)
+		value, _ := strconv.Atoi(text)
+		if len(text) > 1 && text[0] == '0' || value > 191 {
+			return parc.UpdateParserError(parserState, fmt.Errorf("syslog: invalid PRI value %s, expected 0..191", text))
+		}
+		return parc.UpdateParserState(newState, newState.Index, value)
+	})
+	return sequence(expected("syslog", "<"), prival, expected("syslog", ">")).Map(second)
+}
+
+// isPrintUSASCII checks if the rune is a printable US-ASCII character other than space
+func isPrintUSASCII(r rune) bool {
+	return r >= 33 && r <= 126
+}
+
+// syslogField returns a parser of a header field with at most max characters. The NILVALUE results in an empty string.
+func syslogField(name string, max int) *parc.Parser {
+	chars := parc.CondMin(isPrintUSASCII, 1)
+	return parc.NewParser(name, func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := chars.ParserFun(parserState)
+		if newState.IsError {
+			return parc.UpdateParserError(parserState, fmt.Errorf("syslog: expected %s", name))
+		}
+		value := newState.Results.(string)
+		if len(value) > max {
+			return parc.UpdateParserError(parserState, fmt.Errorf("syslog: %s is longer than %d characters", name, max))
+		}
+		if value == "-" {
+			value = ""
+		}
+		return parc.UpdateParserState(newState, newState.Index, value)
+	})
+}
+
+// buildRFC5424SyslogParser creates the parser of RFC 5424 syslog messages
+func buildRFC5424SyslogParser() *parc.Parser {
+	version := parc.CondMinMax(parc.IsDigit, 1, 3).Map(func(result parc.Result) parc.Result {
+		version, _ := strconv.Atoi(result.(string))
+		return version
+	})
+	nilValue := parc.Char("-").Map(func(parc.Result) parc.Result { return nil })
+	// RFC3339 is only set in init, so the parser of the timestamp is created here
+	timestamp := dispatch("TIMESTAMP", map[rune]*parc.Parser{'-': nilValue}, buildDateTimeParser(true))
+	structuredData := dispatch("STRUCTURED-DATA", map[rune]*parc.Parser{'-': nilValue, '[': sdElements}, parc.NewParser("STRUCTURED-DATA", func(parserState parc.ParserState) parc.ParserState {
+		return parc.UpdateParserError(parserState, fmt.Errorf("syslog: expected STRUCTURED-DATA"))
+	}))
+	message := sequence(parc.Char(" "), parc.CondMin(isNotLineEnd, 0)).Map(func(result parc.Result) parc.Result {
+		return strings.TrimPrefix(second(result).(string), "\uFEFF")
+	})
+
+	return sequence(
+		syslogPri, version, syslogSpace,
+		timestamp, syslogSpace,
+		syslogField("HOSTNAME", 255), syslogSpace,
+		syslogField("APP-NAME", 48), syslogSpace,
+		syslogField("PROCID", 128), syslogSpace,
+		syslogField("MSGID", 32), syslogSpace,
+		structuredData,
+		dispatch("MSG", map[rune]*parc.Parser{' ': message}, empty),
+	).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		msg := SyslogMessage{
+			Facility: arr[0].(int) / 8,
+			Severity: arr[0].(int) % 8,
+			Version:  arr[1].(int),
+			Hostname: arr[5].(string),
+			AppName:  arr[7].(string),
+			ProcID:   arr[9].(string),
+			MsgID:    arr[11].(string),
+		}
+		if arr[3] != nil {
+			msg.Timestamp = arr[3].(time.Time)
+		}
+		if arr[13] != nil {
+			msg.StructuredData = arr[13].([]SDElement)
+		}
+		if arr[14] != nil {
+			msg.Message = arr[14].(string)
+		}
+		return msg
+	}).As("RFC 5424 syslog message")
+}
+
+// sdElements is a parser of one or more structured data elements. The result is an []SDElement value.
+var sdElements = buildSDElementsParser()
+
+// buildSDElementsParser creates the parser of structured data elements
+func buildSDElementsParser() *parc.Parser {
+	isSDNameChar := func(r rune) bool { return isPrintUSASCII(r) && r != '=' && r != ']' && r != '"' }
+	nameChars := parc.CondMin(isSDNameChar, 1)
+	sdName := func(name string) *parc.Parser {
+		return parc.NewParser(name, func(parserState parc.ParserState) parc.ParserState {
+			if parserState.IsError {
+				return parserState
+			}
+			newState := nameChars.ParserFun(parserState)
+			if newState.IsError {
+				return parc.UpdateParserError(parserState, fmt.Errorf("syslog: expected %s", name))
+			}
+			if len(newState.Results.(string)) > 32 {
+				return parc.UpdateParserError(parserState, fmt.Errorf("syslog: %s is longer than 32 characters", name))
+			}
+			return newState
+		})
+	}
+
+	// The `"`, `\` and `]` characters are escaped by a backslash, and the other backslashes are kept as they are
+	escape := sequence(parc.Char(`\`), parc.AnyChar).Map(func(result parc.Result) parc.Result {
+		if r := second(result).(string); r == `"` || r == `\` || r == "]" {
+			return r
+		}
+		return parc.JoinStrResults(result)
+	})
+	unescaped := parc.CondMin(func(r rune) bool { return r != '"' && r != '\\' }, 1)
+	paramValue := parc.ZeroOrMore(parc.Choice(unescaped, escape)).Map(parc.JoinStrResults)
+	quote := expected("syslog", `"`)
+	param := sequence(sdName("PARAM-NAME"), expected("syslog", "="), quote, paramValue, quote).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		return SDParam{Name: arr[0].(string), Value: arr[3].(string)}
+	})
+	spaceAndParam := sequence(parc.Char(" "), param).Map(second)
+	params := parc.NewParser("SD-PARAMs", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		result := []SDParam{}
+		nextState := parserState
+		for strings.HasPrefix(nextState.Remaining(), " ") {
+			nextState = spaceAndParam.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			result = append(result, nextState.Results.(SDParam))
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, result)
+	})
+	element := sequence(parc.Char("["), sdName("SD-ID"), params, expected("syslog", "]")).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		return SDElement{ID: arr[1].(string), Params: arr[2].([]SDParam)}
+	})
+	return parc.NewParser("STRUCTURED-DATA", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		result := []SDElement{}
+		nextState := parserState
+		for len(result) == 0 || strings.HasPrefix(nextState.Remaining(), "[") {
+			nextState = element.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			result = append(result, nextState.Results.(SDElement))
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, result)
+	})
+}
@@ -0,0 +1,112 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// received is the reference time of parsing the syslog messages
+var received = time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+func TestRFC5424Syslog(t *testing.T) {
+	input := `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] ` + "\uFEFF" + `An application event log entry`
+	msg, err := ParseSyslog(input, received)
+	require.NoError(t, err)
+	require.Equal(t, 20, msg.Facility)
+	require.Equal(t, 5, msg.Severity)
+	require.Equal(t, 1, msg.Version)
+	require.True(t, time.Date(2003, time.October, 11, 22, 14, 15, 3000000, time.UTC).Equal(msg.Timestamp))
+	require.Equal(t, "mymachine.example.com", msg.Hostname)
+	require.Equal(t, "evntslog", msg.AppName)
+	require.Equal(t, "", msg.ProcID)
+	require.Equal(t, "ID47", msg.MsgID)
+	require.Equal(t, []SDElement{
+		{ID: "exampleSDID@32473", Params: []SDParam{{"iut", "3"}, {"eventSource", "Application"}, {"eventID", "1011"}}},
+		{ID: "examplePriority@32473", Params: []SDParam{{"class", "high"}}},
+	}, msg.StructuredData)
+	require.Equal(t, "An application event log entry", msg.Message)
+}
+
+func TestRFC5424SyslogNilValues(t *testing.T) {
+	msg, err := ParseSyslog("<34>1 - - - - - -\n", received)
+	require.NoError(t, err)
+	require.Equal(t, SyslogMessage{Facility: 4, Severity: 2, Version: 1}, msg)
+
+	input := `<13>1 2026-10-17T12:00:00+02:00 host app 42 - [meta path="C:\\temp\]" quote="say \"hi\"" other="a\b" empty=""]`
+	state := RFC5424Syslog.Parse(&input)
+	require.False(t, state.IsError, state.Err)
+	msg = state.Results.(SyslogMessage)
+	require.Equal(t, "42", msg.ProcID)
+	require.Equal(t, []SDParam{{"path", `C:\temp]`}, {"quote", `say "hi"`}, {"other", `a\b`}, {"empty", ""}}, msg.StructuredData[0].Params)
+	require.Equal(t, "", msg.Message)
+}
+
+func TestRFC3164Syslog(t *testing.T) {
+	input := `<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8`
+	state := RFC3164Syslog(2025).Parse(&input)
+	require.False(t, state.IsError, state.Err)
+	require.Equal(t, SyslogMessage{
+		Facility:  4,
+		Severity:  2,
+		Timestamp: time.Date(2025, time.October, 11, 22, 14, 15, 0, time.UTC),
+		Hostname:  "mymachine",
+		AppName:   "su",
+		Message:   "'su root' failed for lonvick on /dev/pts/8",
+	}, state.Results)
+
+	msg, err := ParseSyslog("<13>Feb  5 17:32:18 10.0.0.99 sshd[4721]: Accepted publickey\r\n", received)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, time.February, 5, 17, 32, 18, 0, time.UTC), msg.Timestamp)
+	require.Equal(t, "10.0.0.99", msg.Hostname)
+	require.Equal(t, "sshd", msg.AppName)
+	require.Equal(t, "4721", msg.ProcID)
+	require.Equal(t, "Accepted publickey", msg.Message)
+
+	msg, err = ParseSyslog("<13>Feb  5 17:32:18 host message without tag", received)
+	require.NoError(t, err)
+	require.Equal(t, "", msg.AppName)
+	require.Equal(t, "message without tag", msg.Message)
+
+	// The messages later than the reference time are from the previous year
+	msg, err = ParseSyslog("<13>Dec 31 23:59:58 host happy new year", time.Date(2026, time.January, 1, 0, 0, 2, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, time.December, 31, 23, 59, 58, 0, time.UTC), msg.Timestamp)
+
+	msg, err = ParseSyslog("<13>Jan  1 00:00:03 host clock skew", time.Date(2026, time.January, 1, 0, 0, 2, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 2026, msg.Timestamp.Year())
+}
+
+func TestParseSyslogErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		col     int
+		message string
+	}{
+		{"34>1 - - - - - -", 1, `syslog: expected "<"`},
+		{"<192>1 - - - - - -", 2, "syslog: invalid PRI value 192, expected 0..191"},
+		{"<034>1 - - - - - -", 2, "syslog: invalid PRI value 034, expected 0..191"},
+		{"<34>1 2026-13-01T00:00:00Z - - - - -", 12, "datetime: month 13 is out of range 1..12"},
+		{"<34>1 - - - - ID47", 19, `syslog: expected " "`},
+		{"<34>1 - - - - - x", 17, "syslog: expected STRUCTURED-DATA"},
+		{"<34>1 - - - - - [id a=b]", 23, `syslog: expected "\""`},
+		{"<34>1 - - - - - [id a=\"b\"", 26, `syslog: expected "]"`},
+		{"<34>1 - - - - - [ a=\"b\"]", 18, "syslog: expected SD-ID"},
+		{"<34>1 - - - - - - msg\nmore", 1, `syslog: unexpected character 'm'`},
+		{"<34>Okt 11 22:14:15 host msg", 5, `syslog: invalid timestamp "Okt 11 22:14:15"`},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseSyslog(testCase.input, received)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+
+	_, err := ParseSyslog("<34>1 - host applicationnamethatislongerthanfortyeightcharacters - - -", received)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "syslog: APP-NAME is longer than 48 characters")
+}
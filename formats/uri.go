@@ -0,0 +1,409 @@
+package formats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tombenke/parc"
+)
+
+// HostType is the type of the host component of a URI
+type HostType int
+
+const (
+	// NoHost means the URI has no authority component
+	NoHost HostType = iota
+	// RegNameHost is a registered name, e.g. a domain name
+	RegNameHost
+	// IPv4Host is an IPv4 address in dotted decimal format
+	IPv4Host
+	// IPv6Host is an IPv6 address enclosed in square brackets
+	IPv6Host
+	// IPvFutureHost is a future IP version literal enclosed in square brackets
+	IPvFutureHost
+)
+
+// URI holds the components of a URI or a relative reference according to RFC 3986.
+// The components are kept in their percent-encoded form. Use the PercentDecode function to decode them.
+type URI struct {
+	// Scheme is the scheme of the URI. It is empty in case of relative references.
+	Scheme string
+	// HasAuthority is true if the URI has an authority component, that is introduced by `//`
+	HasAuthority bool
+	// Userinfo is the user information of the authority
+	Userinfo string
+	// HasUserinfo is true if the authority has a userinfo subcomponent, even if it is empty
+	HasUserinfo bool
+	// Host is the host of the authority. The IP literals are kept without the square brackets and zone ID.
+	Host string
+	// HostType is the type of the host
+	HostType HostType
+	// Zone is the zone identifier of an IPv6 literal (RFC 6874), without the `%25` prefix
+	Zone string
+	// Port is the port of the authority. It may be empty even if the authority has a `:` port separator.
+	Port string
+	// Path is the path component
+	Path string
+	// Query is the query component without the leading `?`
+	Query string
+	// HasQuery is true if the URI has a query component, even if it is empty
+	HasQuery bool
+	// Fragment is the fragment component without the leading `#`
+	Fragment string
+	// HasFragment is true if the URI has a fragment component, even if it is empty
+	HasFragment bool
+}
+
+// IsAbsolute returns true if the URI has a scheme
+func (u URI) IsAbsolute() bool {
+	return u.Scheme != ""
+}
+
+// String recomposes the URI from its components according to RFC 3986 section 5.3
+func (u URI) String() string {
+	var sb strings.Builder
+	if u.Scheme != "" {
+		sb.WriteString(u.Scheme + ":")
+	}
+	if u.HasAuthority {
+		sb.WriteString("//")
+		if u.HasUserinfo {
+			sb.WriteString(u.Userinfo + "@")
+		}
+		switch u.HostType {
+		case IPv6Host:
+			sb.WriteString("[" + u.Host)
+			if u.Zone != "" {
+				sb.WriteString("%25" + u.Zone)
+			}
+			sb.WriteString("]")
+		case IPvFutureHost:
+			sb.WriteString("[" + u.Host + "]")
+		default:
+			sb.WriteString(u.Host)
+		}
+		if u.Port != "" {
+			sb.WriteString(":" + u.Port)
+		}
+	}
+	sb.WriteString(u.Path)
+	if u.HasQuery {
+		sb.WriteString("?" + u.Query)
+	}
+	if u.HasFragment {
+		sb.WriteString("#" + u.Fragment)
+	}
+	return sb.String()
+}
+
+var (
+	// URIReference is a parser of an RFC 3986 URI-reference, that is either a URI or a relative reference.
+	// It matches as long prefix of the input as possible, and its result is a URI value.
+	URIReference = buildURIReferenceParser()
+
+	// IPv4Address is a parser of an IPv4 address in dotted decimal format, without leading zeros.
+	// Its result is the matched text.
+	IPv4Address = buildIPv4TextParser()
+
+	// IPv6Address is a parser of an IPv6 address in the text format of RFC 4291, including the `::` compression
+	// and the embedded IPv4 address. Its result is the matched text.
+	IPv6Address = buildIPv6TextParser()
+)
+
+// ParseURI parses an absolute URI, that must have a scheme
+func ParseURI(input string) (URI, error) {
+	uri, err := ParseURIReference(input)
+	if err != nil {
+		return URI{}, err
+	}
+	if !uri.IsAbsolute() {
+		state := parc.NewParserState(&input, nil, 0, nil)
+		return URI{}, syntaxErrorOf(parc.UpdateParserError(state, fmt.Errorf("URI: missing scheme")))
+	}
+	return uri, nil
+}
+
+// ParseURIReference parses a URI or a relative reference.
+// The error points at the first character that does not fit into the grammar.
+func ParseURIReference(input string) (URI, error) {
+	state := URIReference.Parse(&input)
+	if state.IsError {
+		return URI{}, syntaxErrorOf(state)
+	}
+	if !state.AtTheEnd() {
+		return URI{}, syntaxErrorOf(unexpectedURICharacter(state))
+	}
+	return state.Results.(URI), nil
+}
+
+// PercentDecode decodes the percent-encoded octets of a URI component
+func PercentDecode(s string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) || !parc.IsHexadecimalDigit(rune(s[i+1])) || !parc.IsHexadecimalDigit(rune(s[i+2])) {
+			return "", fmt.Errorf("URI: invalid percent-encoding at offset %d", i)
+		}
+		b, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		sb.WriteByte(byte(b))
+		i += 2
+	}
+	return sb.String(), nil
+}
+
+// isUnreserved tests if rune is an unreserved character of URIs: ALPHA / DIGIT / "-" / "." / "_" / "~"
+func isUnreserved(r rune) bool {
+	return parc.IsAlphaNumeric(r) || r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+// isSubDelim tests if rune is a sub-delimiter of URIs: "!" / "$" / "&" / "'" / "(" / ")" / "*" / "+" / "," / ";" / "="
+func isSubDelim(r rune) bool {
+	return strings.ContainsRune("!$&'()*+,;=", r)
+}
+
+// isPchar tests if rune is a path character of URIs, except the percent-encoded octets
+func isPchar(r rune) bool {
+	return isUnreserved(r) || isSubDelim(r) || r == ':' || r == '@'
+}
+
+// isSchemeChar tests if rune can follow the first letter of a scheme: ALPHA / DIGIT / "+" / "-" / "."
+func isSchemeChar(r rune) bool {
+	return parc.IsAlphaNumeric(r) || r == '+' || r == '-' || r == '.'
+}
+
+// uriChars returns a parser that matches zero or more allowed characters and percent-encoded octets
+func uriChars(isAllowed func(rune) bool) *parc.Parser {
+	pctEncoded := parc.SequenceOf(parc.Char("%"), parc.CondMinMax(parc.IsHexadecimalDigit, 2, 2)).Map(parc.JoinStrResults)
+	return parc.ZeroOrMore(parc.Choice(parc.CondMin(isAllowed, 1), pctEncoded)).Map(parc.JoinStrResults)
+}
+
+// unexpectedURICharacter returns with the error of the character that does not fit into the URI grammar
+func unexpectedURICharacter(parserState parc.ParserState) parc.ParserState {
+	if parserState.AtTheEnd() {
+		return parc.UpdateParserError(parserState, fmt.Errorf("URI: unexpected end of input"))
+	}
+	r, _ := parserState.NextRune()
+	if r == '%' {
+		return parc.UpdateParserError(parserState, fmt.Errorf("URI: invalid percent-encoding"))
+	}
+	return parc.UpdateParserError(parserState, fmt.Errorf("URI: unexpected character %q", r))
+}
+
+// buildURIReferenceParser creates the parser of URI references
+func buildURIReferenceParser() *parc.Parser {
+	scheme := parc.SequenceOf(parc.Cond(parc.IsAsciiLetter), parc.CondMin(isSchemeChar, 0), parc.Char(":")).Map(func(result parc.Result) parc.Result {
+		arr := result.([]parc.Result)
+		return arr[0].(string) + arr[1].(string)
+	})
+	doubleSlash := parc.Str("//")
+	userinfo := parc.SequenceOf(
+		uriChars(func(r rune) bool { return isUnreserved(r) || isSubDelim(r) || r == ':' }),
+		parc.Char("@"),
+	).Map(first)
+	regName := uriChars(func(r rune) bool { return isUnreserved(r) || isSubDelim(r) })
+	zoneID := parc.SequenceOf(parc.Str("%25"), uriChars(isUnreserved)).Map(second)
+	ipvFuture := parc.SequenceOf(
+		parc.Cond(func(r rune) bool { return r == 'v' || r == 'V' }),
+		parc.CondMin(parc.IsHexadecimalDigit, 1),
+		parc.Char("."),
+		parc.CondMin(func(r rune) bool { return isUnreserved(r) || isSubDelim(r) || r == ':' }, 1),
+	).Map(parc.JoinStrResults).As("IPvFuture")
+	openingBracket := parc.Char("[")
+	closingBracket := parc.Char("]")
+	colon := parc.Char(":")
+	port := parc.CondMin(parc.IsDigit, 0)
+	path := uriChars(func(r rune) bool { return isPchar(r) || r == '/' })
+	noSchemeSegment := uriChars(func(r rune) bool { return isPchar(r) && r != ':' })
+	slash := parc.Char("/")
+	query := parc.SequenceOf(parc.Char("?"), uriChars(func(r rune) bool { return isPchar(r) || r == '/' || r == '?' })).Map(second)
+	fragment := parc.SequenceOf(parc.Char("#"), uriChars(func(r rune) bool { return isPchar(r) || r == '/' || r == '?' })).Map(second)
+
+	// host parses the host subcomponent of the authority into the uri
+	host := func(parserState parc.ParserState, uri *URI) parc.ParserState {
+		nextState := openingBracket.ParserFun(parserState)
+		if nextState.IsError {
+			nextState = regName.ParserFun(parserState)
+			uri.Host = nextState.Results.(string)
+			uri.HostType = RegNameHost
+			if state := IPv4Address.ParserFun(parc.NewParserState(&uri.Host, nil, 0, nil)); !state.IsError && state.AtTheEnd() {
+				uri.HostType = IPv4Host
+			}
+			return nextState
+		}
+
+		if state := ipvFuture.ParserFun(nextState); !state.IsError {
+			uri.HostType = IPvFutureHost
+			nextState = state
+		} else if r, _ := nextState.NextRune(); r == 'v' || r == 'V' {
+			return unexpectedURICharacter(nextState)
+		} else {
+			nextState = IPv6Address.ParserFun(nextState)
+			if nextState.IsError {
+				return nextState
+			}
+			uri.HostType = IPv6Host
+		}
+		uri.Host = nextState.Results.(string)
+		if uri.HostType == IPv6Host {
+			if state := zoneID.ParserFun(nextState); !state.IsError {
+				if uri.Zone = state.Results.(string); uri.Zone == "" {
+					return unexpectedURICharacter(state)
+				}
+				nextState = state
+			}
+		}
+		if state := closingBracket.ParserFun(nextState); !state.IsError {
+			return state
+		}
+		return unexpectedURICharacter(nextState)
+	}
+
+	return parc.NewParser("URI-reference", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		uri := URI{}
+		nextState := parserState
+		if state := scheme.ParserFun(nextState); !state.IsError {
+			uri.Scheme = state.Results.(string)
+			nextState = state
+		}
+
+		if state := doubleSlash.ParserFun(nextState); !state.IsError {
+			uri.HasAuthority = true
+			nextState = state
+			if state := userinfo.ParserFun(nextState); !state.IsError {
+				uri.Userinfo = state.Results.(string)
+				uri.HasUserinfo = true
+				nextState = state
+			}
+			nextState = host(nextState, &uri)
+			if nextState.IsError {
+				return nextState
+			}
+			if state := colon.ParserFun(nextState); !state.IsError {
+				nextState = port.ParserFun(state)
+				uri.Port = nextState.Results.(string)
+			}
+			// The path must be empty or start with a slash after the authority
+			if state := slash.ParserFun(nextState); !state.IsError {
+				nextState = path.ParserFun(nextState)
+				uri.Path = nextState.Results.(string)
+			}
+		} else if uri.Scheme == "" {
+			// The first segment of a relative path must not contain a colon,
+			// that would be mistaken for a scheme
+			nextState = noSchemeSegment.ParserFun(nextState)
+			uri.Path = nextState.Results.(string)
+			if state := slash.ParserFun(nextState); !state.IsError {
+				nextState = path.ParserFun(nextState)
+				uri.Path += nextState.Results.(string)
+			}
+		} else {
+			nextState = path.ParserFun(nextState)
+			uri.Path = nextState.Results.(string)
+		}
+
+		if state := query.ParserFun(nextState); !state.IsError {
+			uri.Query = state.Results.(string)
+			uri.HasQuery = true
+			nextState = state
+		}
+		if state := fragment.ParserFun(nextState); !state.IsError {
+			uri.Fragment = state.Results.(string)
+			uri.HasFragment = true
+			nextState = state
+		}
+		return parc.UpdateParserState(nextState, nextState.Index, uri)
+	}).As("URI-reference")
+}
+
+// buildIPv4TextParser creates the parser of dotted decimal IPv4 addresses
+func buildIPv4TextParser() *parc.Parser {
+	digits := parc.CondMinMax(parc.IsDigit, 1, 3)
+	octet := parc.NewParser("octet", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := digits.ParserFun(parserState)
+		if newState.IsError {
+			return parc.UpdateParserError(parserState, fmt.Errorf("IPv4address: expected decimal digits"))
+		}
+		octet := newState.Results.(string)
+		if len(octet) > 1 && octet[0] == '0' {
+			return parc.UpdateParserError(parserState, fmt.Errorf("IPv4address: octet %s has leading zero", octet))
+		}
+		if value, _ := strconv.Atoi(octet); value > 255 {
+			return parc.UpdateParserError(parserState, fmt.Errorf("IPv4address: octet %s is out of range 0..255", octet))
+		}
+		return newState
+	})
+	dot := expected("IPv4address", ".")
+	return sequence(octet, dot, octet, dot, octet, dot, octet).Map(parc.JoinStrResults).As("IPv4address")
+}
+
+// buildIPv6TextParser creates the parser of IPv6 addresses.
+// The address consists of eight groups of hexadecimal digits, and one run of zero groups can be replaced by `::`.
+func buildIPv6TextParser() *parc.Parser {
+	h16 := parc.CondMinMax(parc.IsHexadecimalDigit, 1, 4)
+	colon := parc.Char(":")
+	doubleColon := parc.Str("::")
+	return parc.NewParser("IPv6address", func(parserState parc.ParserState) parc.ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		groups := 0
+		compressed := false
+		// A group is optional only right after the `::`
+		groupIsOptional := false
+		nextState := parserState
+		if state := doubleColon.ParserFun(nextState); !state.IsError {
+			compressed = true
+			groupIsOptional = true
+			nextState = state
+		}
+		for groups < 8 {
+			// The last 32 bits may be written as an IPv4 address
+			if groups <= 6 {
+				if state := IPv4Address.ParserFun(nextState); !state.IsError {
+					groups += 2
+					nextState = state
+					break
+				}
+			}
+			state := h16.ParserFun(nextState)
+			if state.IsError {
+				if groupIsOptional {
+					break
+				}
+				return parc.UpdateParserError(nextState, fmt.Errorf("IPv6address: expected hexadecimal digits"))
+			}
+			groups++
+			nextState = state
+			if state := doubleColon.ParserFun(nextState); !state.IsError {
+				if compressed {
+					return parc.UpdateParserError(nextState, fmt.Errorf("IPv6address: multiple :: in address"))
+				}
+				compressed = true
+				groupIsOptional = true
+				nextState = state
+				continue
+			}
+			if state := colon.ParserFun(nextState); !state.IsError && groups < 8 {
+				groupIsOptional = false
+				nextState = state
+				continue
+			}
+			break
+		}
+		if !compressed && groups != 8 || compressed && groups > 7 {
+			return parc.UpdateParserError(parserState, fmt.Errorf("IPv6address: wrong number of groups %d", groups))
+		}
+		text := parserState.Remaining()[:nextState.Index-parserState.Index]
+		return parc.UpdateParserState(nextState, nextState.Index, text)
+	})
+}
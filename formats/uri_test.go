@@ -0,0 +1,150 @@
+package formats
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	testCases := map[string]URI{
+		"https://john.doe@www.example.com:1234/forum/questions/?tag=networking&order=newest#top": {
+			Scheme: "https", HasAuthority: true, Userinfo: "john.doe", HasUserinfo: true,
+			Host: "www.example.com", HostType: RegNameHost, Port: "1234", Path: "/forum/questions/",
+			Query: "tag=networking&order=newest", HasQuery: true, Fragment: "top", HasFragment: true,
+		},
+		"ldap://[2001:db8::7]/c=GB?objectClass?one": {
+			Scheme: "ldap", HasAuthority: true, Host: "2001:db8::7", HostType: IPv6Host, Path: "/c=GB",
+			Query: "objectClass?one", HasQuery: true,
+		},
+		"http://[fe80::1%25eth0]:8080": {
+			Scheme: "http", HasAuthority: true, Host: "fe80::1", HostType: IPv6Host, Zone: "eth0", Port: "8080",
+		},
+		"http://[::ffff:192.0.2.128]/": {
+			Scheme: "http", HasAuthority: true, Host: "::ffff:192.0.2.128", HostType: IPv6Host, Path: "/",
+		},
+		"http://[v7.fe:80]/": {
+			Scheme: "http", HasAuthority: true, Host: "v7.fe:80", HostType: IPvFutureHost, Path: "/",
+		},
+		"telnet://192.0.2.16:80/": {
+			Scheme: "telnet", HasAuthority: true, Host: "192.0.2.16", HostType: IPv4Host, Port: "80", Path: "/",
+		},
+		"http://192.0.2.256/": {
+			Scheme: "http", HasAuthority: true, Host: "192.0.2.256", HostType: RegNameHost, Path: "/",
+		},
+		"mailto:John.Doe@example.com": {
+			Scheme: "mailto", Path: "John.Doe@example.com",
+		},
+		"urn:oasis:names:specification:docbook:dtd:xml:4.1.2": {
+			Scheme: "urn", Path: "oasis:names:specification:docbook:dtd:xml:4.1.2",
+		},
+		"file:///etc/hosts": {
+			Scheme: "file", HasAuthority: true, HostType: RegNameHost, Path: "/etc/hosts",
+		},
+		"news:comp.infosystems.www.servers.unix": {
+			Scheme: "news", Path: "comp.infosystems.www.servers.unix",
+		},
+		"http://example.com/a%20b?q=%C3%A9#": {
+			Scheme: "http", HasAuthority: true, Host: "example.com", HostType: RegNameHost, Path: "/a%20b",
+			Query: "q=%C3%A9", HasQuery: true, HasFragment: true,
+		},
+	}
+	for input, expected := range testCases {
+		uri, err := ParseURI(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, uri, input)
+		require.Equal(t, input, uri.String(), input)
+	}
+}
+
+func TestParseURIReference(t *testing.T) {
+	testCases := map[string]URI{
+		"":                  {},
+		"g":                 {Path: "g"},
+		"./g:h":             {Path: "./g:h"},
+		"/g":                {Path: "/g"},
+		"//g":               {HasAuthority: true, Host: "g", HostType: RegNameHost},
+		"?y":                {Query: "y", HasQuery: true},
+		"g?y#s":             {Path: "g", Query: "y", HasQuery: true, Fragment: "s", HasFragment: true},
+		"#s":                {Fragment: "s", HasFragment: true},
+		"../../g":           {Path: "../../g"},
+		"//user:pw@host:8":  {HasAuthority: true, Userinfo: "user:pw", HasUserinfo: true, Host: "host", HostType: RegNameHost, Port: "8"},
+		"g;x=1/../y?a/b?c#": {Path: "g;x=1/../y", Query: "a/b?c", HasQuery: true, HasFragment: true},
+	}
+	for input, expected := range testCases {
+		uri, err := ParseURIReference(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, uri, input)
+		require.False(t, uri.IsAbsolute(), input)
+		require.Equal(t, input, uri.String(), input)
+	}
+
+	// The empty port is removed by the normalization
+	uri, err := ParseURIReference("//host:")
+	require.NoError(t, err)
+	require.Equal(t, URI{HasAuthority: true, Host: "host", HostType: RegNameHost}, uri)
+	require.Equal(t, "//host", uri.String())
+}
+
+func TestParseURIErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		col     int
+		message string
+	}{
+		{"http://example.com/a b", 21, `URI: unexpected character ' '`},
+		{"http://example.com/%2x", 20, "URI: invalid percent-encoding"},
+		{"http://example.com:80x/", 22, `URI: unexpected character 'x'`},
+		{"http://exa^mple.com/", 11, `URI: unexpected character '^'`},
+		{"http://[2001:db8::7/", 20, `URI: unexpected character '/'`},
+		{"http://[2001:db8:::7]/", 19, `URI: unexpected character ':'`},
+		{"http://[1::2::3]/", 13, "IPv6address: multiple :: in address"},
+		{"http://[1:2:3:4:5:6:7]/", 9, "IPv6address: wrong number of groups 7"},
+		{"http://[fe80::1%25]/", 19, `URI: unexpected character ']'`},
+		{"1http://example.com/", 6, `URI: unexpected character ':'`},
+		{"//example.com/", 1, "URI: missing scheme"},
+		{"http://example.com/#a#b", 22, `URI: unexpected character '#'`},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseURI(testCase.input)
+		require.Error(t, err, testCase.input)
+		var syntaxError *SyntaxError
+		require.True(t, errors.As(err, &syntaxError), testCase.input)
+		require.Equal(t, 1, syntaxError.Pos.Row, testCase.input)
+		require.Equal(t, testCase.col, syntaxError.Pos.Col, testCase.input)
+		require.Contains(t, err.Error(), testCase.message, testCase.input)
+	}
+}
+
+func TestIPAddresses(t *testing.T) {
+	for _, input := range []string{"0.0.0.0", "255.255.255.255", "192.168.0.1"} {
+		state := IPv4Address.Parse(&input)
+		require.False(t, state.IsError, input)
+		require.Equal(t, input, state.Results, input)
+		require.True(t, state.AtTheEnd(), input)
+	}
+	for _, input := range []string{"256.0.0.1", "01.2.3.4", "1.2.3", "a.b.c.d"} {
+		state := IPv4Address.Parse(&input)
+		require.True(t, state.IsError, input)
+	}
+
+	for _, input := range []string{"::", "::1", "1::", "2001:db8::ff00:42:8329", "1:2:3:4:5:6:7:8", "::ffff:10.0.0.1", "1:2:3:4:5:6:1.2.3.4"} {
+		state := IPv6Address.Parse(&input)
+		require.False(t, state.IsError, input)
+		require.Equal(t, input, state.Results, input)
+		require.True(t, state.AtTheEnd(), input)
+	}
+	for _, input := range []string{"1:2:3:4:5:6:7", "1::2::3", ":1", "1:2:3:4:5:6:7:8:9", "1:2:3:4:5:6:7::8"} {
+		state := IPv6Address.Parse(&input)
+		require.False(t, !state.IsError && state.AtTheEnd(), input)
+	}
+}
+
+func TestPercentDecode(t *testing.T) {
+	decoded, err := PercentDecode("a%20b%C3%A9")
+	require.NoError(t, err)
+	require.Equal(t, "a bé", decoded)
+
+	_, err = PercentDecode("a%2")
+	require.Error(t, err)
+}
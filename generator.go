@@ -0,0 +1,345 @@
+package parc
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultAlphabet holds the runes the Generator tries with the condition functions of the Cond parsers by default
+var DefaultAlphabet = []rune(" \t\n!\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~éőű€日")
+
+// Generator produces random input strings that match the parsers.
+// It walks through the graph of the parsers, that is described by the ParserSpec of each parser,
+// and produces a string for each primitive parser, that is built by Str, Char, Cond, RegExp, etc.
+// The generated strings can be used, for example, as seeds of fuzz tests:
+//
+//	gen := parc.NewGenerator(42)
+//	for i := 0; i < 100; i++ {
+//		if input, err := gen.Generate(parser); err == nil {
+//			f.Add(input)
+//		}
+//	}
+//
+// The parsers created by NewParser with a custom parser function can not be used for generation.
+type Generator struct {
+	// MaxDepth is the depth of the parser graph, beyond that the generator selects the shortest ways to finish the input
+	MaxDepth int
+	// MaxRepeat is the maximum number of extra occurences the generator adds to the minimum of an unbounded repetition
+	MaxRepeat int
+	// MaxLength is the length of the input in bytes, beyond that the repetitions produce only their minimum number of occurences
+	MaxLength int
+	// MaxAttempts is the number of attempts to generate an input that the parser accepts
+	MaxAttempts int
+	// Alphabet holds the runes to try with the condition functions
+	Alphabet []rune
+
+	rand    *rand.Rand
+	heights map[*Parser]int
+}
+
+// NewGenerator creates a new Generator with default budgets.
+// The seed makes the sequence of generated inputs reproducible.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		MaxDepth:    20,
+		MaxRepeat:   5,
+		MaxLength:   1024,
+		MaxAttempts: 100,
+		Alphabet:    DefaultAlphabet,
+		rand:        rand.New(rand.NewSource(seed)),
+		heights:     map[*Parser]int{},
+	}
+}
+
+// Generate produces a random input string that the parser accepts.
+// It returns error if the parser graph contains a parser that the generator can not handle,
+// or it could not produce an acceptable input within MaxAttempts attempts.
+func (g *Generator) Generate(parser *Parser) (string, error) {
+	g.computeHeights(parser)
+
+	var lastErr error
+	for attempt := 0; attempt < g.MaxAttempts; attempt++ {
+		var sb strings.Builder
+		if err := g.generate(parser, &sb, 0); err != nil {
+			return "", err
+		}
+		input := sb.String()
+		state := parser.Parse(&input)
+		if !state.IsError {
+			return input, nil
+		}
+		lastErr = state.Err
+	}
+	return "", fmt.Errorf("Generate: could not generate an acceptable input for %s in %d attempts: %w", parser.Name(), g.MaxAttempts, lastErr)
+}
+
+// generate writes a random input for the parser into the builder
+func (g *Generator) generate(parser *Parser, sb *strings.Builder, depth int) error {
+	spec := parser.Spec()
+	switch spec.Kind {
+	case StartOfInputKind, EndOfInputKind:
+		return nil
+
+	case StrKind, CharKind:
+		sb.WriteString(spec.Literal)
+		return nil
+
+	case RestKind:
+		for i := g.repeat(0, Unbounded, sb, depth); i > 0; i-- {
+			sb.WriteRune(g.Alphabet[g.rand.Intn(len(g.Alphabet))])
+		}
+		return nil
+
+	case RegExpKind:
+		re, err := syntax.Parse(spec.Literal, syntax.Perl)
+		if err != nil {
+			return fmt.Errorf("Generate: %s: %w", parser.Name(), err)
+		}
+		return g.generateRegexp(re.Simplify(), sb, depth)
+
+	case CondKind, CondMinKind, CondMinMaxKind:
+		for i := g.repeat(spec.Min, spec.Max, sb, depth); i > 0; i-- {
+			r, ok := g.pickRune(spec.Condition)
+			if !ok {
+				return fmt.Errorf("Generate: %s: none of the runes of the alphabet satisfies the condition", parser.Name())
+			}
+			sb.WriteRune(r)
+		}
+		return nil
+
+	case SequenceOfKind:
+		for _, p := range spec.Parsers {
+			if err := g.generate(p, sb, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ChoiceKind:
+		return g.generate(g.pickAlternative(spec.Parsers, depth), sb, depth+1)
+
+	case CountKind, CountMinKind, CountMinMaxKind, ZeroOrOneKind, ZeroOrMoreKind, OneOrMoreKind:
+		for i := g.repeat(spec.Min, spec.Max, sb, depth); i > 0; i-- {
+			if err := g.generate(spec.Parsers[0], sb, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case MapKind, ErrorMapKind, MemoKind:
+		return g.generate(spec.Parsers[0], sb, depth+1)
+
+	case ChainKind:
+		var first strings.Builder
+		if err := g.generate(spec.Parsers[0], &first, depth+1); err != nil {
+			return err
+		}
+		input := first.String()
+		state := spec.Parsers[0].Parse(&input)
+		if state.IsError {
+			return fmt.Errorf("Generate: %s: %w", parser.Name(), state.Err)
+		}
+		sb.WriteString(input)
+		nextParser := spec.ChainFn(state.Results)
+		g.computeHeights(nextParser)
+		return g.generate(nextParser, sb, depth+1)
+	}
+
+	return fmt.Errorf("Generate: can not generate input for the custom parser %s", parser.Name())
+}
+
+// repeat returns with a random number of occurences between min and max, taking into account the budgets
+func (g *Generator) repeat(min, max int, sb *strings.Builder, depth int) int {
+	if depth >= g.MaxDepth || sb.Len() >= g.MaxLength {
+		return min
+	}
+	if max == Unbounded {
+		max = min + g.MaxRepeat
+	}
+	if max <= min {
+		return min
+	}
+	return min + g.rand.Intn(max-min+1)
+}
+
+// pickRune returns with a random rune of the alphabet that satisfies the condition
+func (g *Generator) pickRune(conditionFn func(rune) bool) (rune, bool) {
+	for i := 0; i < 2*len(g.Alphabet); i++ {
+		r := g.Alphabet[g.rand.Intn(len(g.Alphabet))]
+		if conditionFn(r) {
+			return r, true
+		}
+	}
+	for _, r := range g.Alphabet {
+		if conditionFn(r) {
+			return r, true
+		}
+	}
+	return utf8.RuneError, false
+}
+
+// pickAlternative selects an alternative of a Choice parser.
+// Beyond MaxDepth it selects one of the alternatives that can be finished within the shortest way.
+func (g *Generator) pickAlternative(parsers []*Parser, depth int) *Parser {
+	if depth < g.MaxDepth {
+		return parsers[g.rand.Intn(len(parsers))]
+	}
+	minHeight := math.MaxInt
+	shortest := make([]*Parser, 0, len(parsers))
+	for _, p := range parsers {
+		h := g.heights[p]
+		if h < minHeight {
+			minHeight = h
+			shortest = shortest[:0]
+		}
+		if h == minHeight {
+			shortest = append(shortest, p)
+		}
+	}
+	return shortest[g.rand.Intn(len(shortest))]
+}
+
+// computeHeights calculates the minimum depth of the sub-graph of every parser reachable from the root parser,
+// that is needed to finish the generation. The recursive parsers are resolved by fixpoint iteration.
+func (g *Generator) computeHeights(root *Parser) {
+	var parsers []*Parser
+	visited := map[*Parser]bool{}
+	var collect func(p *Parser)
+	collect = func(p *Parser) {
+		if visited[p] {
+			return
+		}
+		visited[p] = true
+		if _, ok := g.heights[p]; !ok {
+			g.heights[p] = math.MaxInt
+			parsers = append(parsers, p)
+		}
+		for _, child := range p.Spec().Parsers {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	for changed := true; changed; {
+		changed = false
+		for _, p := range parsers {
+			h := g.height(p)
+			if h < g.heights[p] {
+				g.heights[p] = h
+				changed = true
+			}
+		}
+	}
+}
+
+// height calculates the height of a parser from the actual heights of its sub-parsers
+func (g *Generator) height(p *Parser) int {
+	spec := p.Spec()
+	children := spec.Parsers
+	switch spec.Kind {
+	case ChoiceKind:
+		h := math.MaxInt
+		for _, child := range children {
+			h = min(h, g.heights[child])
+		}
+		return plusOne(h)
+	case CountKind, CountMinKind, CountMinMaxKind, ZeroOrOneKind, ZeroOrMoreKind, OneOrMoreKind:
+		if spec.Min == 0 {
+			return 1
+		}
+	}
+	h := 0
+	for _, child := range children {
+		h = max(h, g.heights[child])
+	}
+	return plusOne(h)
+}
+
+// plusOne increments the height unless it is infinite
+func plusOne(h int) int {
+	if h == math.MaxInt {
+		return h
+	}
+	return h + 1
+}
+
+// generateRegexp writes a random string that matches the regular expression into the builder
+func (g *Generator) generateRegexp(re *syntax.Regexp, sb *strings.Builder, depth int) error {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && g.rand.Intn(2) == 0 {
+				r = flipCase(r)
+			}
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		r, ok := g.pickRune(func(r rune) bool { return inRanges(r, re.Rune) })
+		if !ok && len(re.Rune) > 0 {
+			r, ok = re.Rune[0], true
+		}
+		if !ok {
+			return fmt.Errorf("Generate: empty character class in regular expression %s", re)
+		}
+		sb.WriteRune(r)
+	case syntax.OpAnyCharNotNL:
+		r, _ := g.pickRune(func(r rune) bool { return r != '\n' })
+		sb.WriteRune(r)
+	case syntax.OpAnyChar:
+		sb.WriteRune(g.Alphabet[g.rand.Intn(len(g.Alphabet))])
+	case syntax.OpCapture:
+		return g.generateRegexp(re.Sub[0], sb, depth+1)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, Unbounded
+		case syntax.OpPlus:
+			min, max = 1, Unbounded
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		for i := g.repeat(min, max, sb, depth); i > 0; i-- {
+			if err := g.generateRegexp(re.Sub[0], sb, depth+1); err != nil {
+				return err
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.generateRegexp(sub, sb, depth+1); err != nil {
+				return err
+			}
+		}
+	case syntax.OpAlternate:
+		return g.generateRegexp(re.Sub[g.rand.Intn(len(re.Sub))], sb, depth+1)
+	case syntax.OpNoMatch:
+		return fmt.Errorf("Generate: the regular expression %s never matches", re)
+	}
+	// The empty matches and the assertions, like ^, $, \b do not produce any output
+	return nil
+}
+
+// inRanges tests if the rune is in one of the [lo, hi] pairs of a character class
+func inRanges(r rune, ranges []rune) bool {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if r >= ranges[i] && r <= ranges[i+1] {
+			return true
+		}
+	}
+	return false
+}
+
+// flipCase returns with the other case variant of an ASCII letter
+func flipCase(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return r - 'a' + 'A'
+	case r >= 'A' && r <= 'Z':
+		return r - 'A' + 'a'
+	}
+	return r
+}
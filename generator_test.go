@@ -0,0 +1,76 @@
+package parc
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGenerator(t *testing.T) {
+	gen := NewGenerator(42)
+	for i := 0; i < 20; i++ {
+		input, err := gen.Generate(flightIdentifier)
+		require.NoError(t, err)
+		newState := flightIdentifier.Parse(&input)
+		require.False(t, newState.IsError)
+		require.Equal(t, len(input), newState.Index)
+	}
+}
+
+func TestGeneratorRecursive(t *testing.T) {
+	var expr Parser
+	operator := Choice(Char("+"), Char("-"), Char("*"), Char("/"))
+	operation := SequenceOf(Char("("), operator, Space, &expr, Space, &expr, Char(")"))
+	expr = *Choice(Digits, operation)
+	parser := SequenceOf(&expr, EndOfInput())
+
+	gen := NewGenerator(1)
+	gen.MaxDepth = 8
+	for i := 0; i < 20; i++ {
+		input, err := gen.Generate(parser)
+		require.NoError(t, err)
+		require.False(t, parser.Parse(&input).IsError)
+	}
+}
+
+func TestGeneratorRegExpAndChain(t *testing.T) {
+	parser := SequenceOf(RegExp("^[a-f]{2,4}(x|yz)?[0-9]+"), EndOfInput())
+	gen := NewGenerator(7)
+	for i := 0; i < 20; i++ {
+		input, err := gen.Generate(parser)
+		require.NoError(t, err)
+		require.Regexp(t, "^[a-f]{2,4}(x|yz)?[0-9]+$", input)
+	}
+
+	chain := Chain(SequenceOf(Letters, Char(":")), func(result Result) *Parser {
+		if result.([]Result)[0] == "x" {
+			return Digits
+		}
+		return Letters
+	})
+	input, err := gen.Generate(chain)
+	require.NoError(t, err)
+	require.False(t, chain.Parse(&input).IsError)
+}
+
+func TestGeneratorReproducible(t *testing.T) {
+	generateAll := func(seed int64) []string {
+		gen := NewGenerator(seed)
+		inputs := []string{}
+		for i := 0; i < 10; i++ {
+			input, err := gen.Generate(RealNumber)
+			require.NoError(t, err)
+			inputs = append(inputs, input)
+		}
+		return inputs
+	}
+	require.Equal(t, generateAll(3), generateAll(3))
+	require.NotEqual(t, generateAll(3), generateAll(4))
+}
+
+func TestGeneratorCustomParser(t *testing.T) {
+	custom := NewParser("custom", func(parserState ParserState) ParserState {
+		return parserState
+	})
+	_, err := NewGenerator(1).Generate(SequenceOf(Str("a"), custom))
+	require.Error(t, err)
+}
@@ -0,0 +1,416 @@
+// Package grammar builds parsers from grammars declared by annotated Go structs.
+//
+// The grammar of a struct is the sequence of the `parc` tags of its fields, e.g.
+//
+//	type Call struct {
+//		Name string `parc:"@Ident"`
+//		Args []*Arg `parc:"'(' (@@ (',' @@)*)? ')'"`
+//	}
+//
+// The tag grammar has the following elements:
+//
+//   - `'text'` or `"text"` matches the literal text. The literals made of letters and digits match whole words only.
+//   - `Ident`, `Int`, `Float` and `String` match the tokens of the identifiers, integer and floating point numbers,
+//     and the Go double-quoted strings.
+//   - `/regexp/` matches the regular expression.
+//   - `@x` captures the text matched by x into the field of the tag. The captured String tokens are unquoted.
+//   - `@@` parses the struct type of the field, and captures it into the field.
+//   - `x y` matches x then y, `x | y` matches x or y, and `(x)` groups the elements.
+//   - `x*`, `x+` and `x?` match x zero or more times, one or more times and optionally.
+//
+// The whitespace is skipped before the tokens. An alternative may continue in the tag of the next field,
+// e.g. `parc:"@Int"` followed by `parc:"| @String"`. The fields without tag are not the part of the grammar.
+//
+// The captured text is converted to the type of the field by parc.Decode, so it can be a string, number or
+// encoding.TextUnmarshaler. A captured bool field is set to true if its element matched. The slice fields get
+// an item from each capture. The fields of `@@` must be structs, pointers to structs or slices of them.
+package grammar
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"unicode"
+
+	"github.com/tombenke/parc"
+)
+
+// Grammar is the parser of the grammar declared by the T struct type
+type Grammar[T any] struct {
+	parser *parc.Parser
+	rule   *parc.Parser
+}
+
+// Build creates the parser of the grammar declared by the tags of the T struct type, and validates the tags
+func Build[T any]() (*Grammar[T], error) {
+	structType := reflect.TypeFor[T]()
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("grammar: %s is not a struct", structType)
+	}
+	c := &compiler{parsers: map[reflect.Type]*parc.Parser{}, nullable: map[reflect.Type]bool{}}
+	parser, err := c.structParser(structType)
+	if err != nil {
+		return nil, err
+	}
+	root := parc.SequenceOf(parser, whitespace, parc.EndOfInput()).Map(func(result parc.Result) parc.Result {
+		return result.([]parc.Result)[0]
+	}).As(structType.Name())
+	return &Grammar[T]{parser: root, rule: parser}, nil
+}
+
+// MustBuild is like Build, but it panics if the tags are invalid, e.g. to initialize package variables
+func MustBuild[T any]() *Grammar[T] {
+	g, err := Build[T]()
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Parser returns with the parser of the whole input, that results in a *T value
+func (g *Grammar[T]) Parser() *parc.Parser {
+	return g.parser
+}
+
+// Rule returns with the parser of the struct, that results in a *T value. Unlike Parser, it does not skip the trailing
+// whitespace and it does not require the end of the input, so it can be used as a part of other parsers.
+func (g *Grammar[T]) Rule() *parc.Parser {
+	return g.rule
+}
+
+// Parse parses the input and returns with the populated struct
+func (g *Grammar[T]) Parse(input string) (*T, error) {
+	newState := g.parser.Parse(&input)
+	if newState.IsError {
+		return nil, newState.Err
+	}
+	return newState.Results.(*T), nil
+}
+
+// whitespace matches the optional whitespace before the tokens
+var whitespace = parc.CondMin(unicode.IsSpace, 0)
+
+// tokens holds the parsers of the named token classes
+var tokens = map[string]*parc.Parser{
+	"Ident":  parc.RegExp(`^[\pL_][\pL\pN_]*`).As("Ident"),
+	"Int":    parc.RegExp(`^[-+]?[0-9]+`).As("Int"),
+	"Float":  parc.RegExp(`^[-+]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?`).As("Float"),
+	"String": parc.GoString,
+}
+
+// capturedValue is the result of a capture, that is stored into the field of the index.
+// The value is a parc.Captured value of the text in case of `@`, or a pointer to the struct in case of `@@`.
+type capturedValue struct {
+	field int
+	value any
+}
+
+// compiler builds the parsers of the struct types
+type compiler struct {
+	// parsers holds the parsers of the struct types, including the ones being built for the recursive types
+	parsers map[reflect.Type]*parc.Parser
+	// nullable tells if the grammar of the struct type can match empty input
+	nullable map[reflect.Type]bool
+}
+
+// structParser returns with the parser of the struct type, that results in a pointer to a new struct value
+func (c *compiler) structParser(structType reflect.Type) (*parc.Parser, error) {
+	if parser, ok := c.parsers[structType]; ok {
+		return parser, nil
+	}
+	// The placeholder is set to the parser at the end, so the recursive references can point to it
+	placeholder := &parc.Parser{}
+	c.parsers[structType] = placeholder
+
+	tagTokens := []tagLexeme{}
+	for i := 0; i < structType.NumField(); i++ {
+		tag, ok := structType.Field(i).Tag.Lookup("parc")
+		if !ok {
+			continue
+		}
+		var err error
+		if tagTokens, err = tokenizeTag(tag, i, tagTokens); err != nil {
+			return nil, c.tagError(structType, err)
+		}
+	}
+	if len(tagTokens) == 0 {
+		return nil, fmt.Errorf("grammar: %s has no tagged fields", structType)
+	}
+	root, err := parseTags(tagTokens)
+	if err != nil {
+		return nil, c.tagError(structType, err)
+	}
+	body, err := c.compile(structType, root)
+	if err != nil {
+		return nil, c.tagError(structType, err)
+	}
+	c.nullable[structType] = c.isNullable(root, structType)
+
+	parser := body.TryMap(func(result parc.Result) (parc.Result, error) {
+		value := reflect.New(structType)
+		if err := assignCaptures(value.Elem(), result); err != nil {
+			return nil, err
+		}
+		return value.Interface(), nil
+	}).As(structType.Name())
+	*placeholder = *parser
+	return placeholder, nil
+}
+
+// tagError returns with the error of the tag of the struct type prefixed by the name of the field
+func (c *compiler) tagError(structType reflect.Type, err error) error {
+	if e, ok := err.(*tagError); ok {
+		return fmt.Errorf("grammar: %s.%s: %w", structType, structType.Field(e.field).Name, err)
+	}
+	return err
+}
+
+// compile creates the parser of the node of the tag grammar of the struct type
+func (c *compiler) compile(structType reflect.Type, node *tagNode) (*parc.Parser, error) {
+	fail := func(format string, args ...any) error {
+		return &tagError{field: node.field, offset: node.offset, msg: fmt.Sprintf(format, args...)}
+	}
+	switch node.kind {
+	case tagSequence, tagChoice:
+		parsers := []*parc.Parser{}
+		for _, child := range node.children {
+			parser, err := c.compile(structType, child)
+			if err != nil {
+				return nil, err
+			}
+			parsers = append(parsers, parser)
+		}
+		if node.kind == tagChoice {
+			return parc.Choice(parsers...), nil
+		}
+		return parc.SequenceOf(parsers...), nil
+
+	case tagRepeat:
+		// The child is compiled first, so it is validated before its nullability is checked
+		parser, err := c.compile(structType, node.children[0])
+		if err != nil {
+			return nil, err
+		}
+		if node.op != '?' && c.isNullable(node.children[0], structType) {
+			return nil, fail("repetition of an expression, that can match empty input")
+		}
+		switch node.op {
+		case '*':
+			return parc.ZeroOrMore(parser), nil
+		case '+':
+			return parc.OneOrMore(parser), nil
+		}
+		return parc.Optional(parser), nil
+
+	case tagLiteral:
+		if isWord(node.text) {
+			return token(parc.RegExp(`^` + regexp.QuoteMeta(node.text) + `\b`).As("'" + node.text + "'")), nil
+		}
+		return token(parc.Str(node.text)), nil
+
+	case tagToken:
+		parser, ok := tokens[node.text]
+		if !ok {
+			return nil, fail("unknown token %s", node.text)
+		}
+		return token(parser), nil
+
+	case tagRegexp:
+		if _, err := regexp.Compile(node.text); err != nil {
+			return nil, fail("invalid regular expression: %v", err)
+		}
+		return token(parc.RegExp(`^(?:` + node.text + `)`)), nil
+
+	case tagCapture:
+		if containsCapture(node.children[0]) {
+			return nil, fail("nested capture")
+		}
+		field := structType.Field(node.field)
+		if !isTextTarget(field.Type) {
+			return nil, fail("cannot capture text into %s", field.Type)
+		}
+		parser, err := c.compile(structType, node.children[0])
+		if err != nil {
+			return nil, err
+		}
+		// The whitespace is skipped before the capture, so it is not the part of the span
+		return parc.SequenceOf(whitespace, parc.Capture(field.Name, parser)).Map(func(result parc.Result) parc.Result {
+			captured := result.([]parc.Result)[1].(parc.Captured)
+			captured.Value = joinText(captured.Value)
+			return capturedValue{field: node.field, value: captured}
+		}), nil
+
+	case tagStruct:
+		field := structType.Field(node.field)
+		elemType := field.Type
+		if elemType.Kind() == reflect.Slice {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct {
+			return nil, fail("cannot parse %s by @@", field.Type)
+		}
+		parser, err := c.structParser(elemType)
+		if err != nil {
+			return nil, err
+		}
+		return parser.Map(func(result parc.Result) parc.Result {
+			return capturedValue{field: node.field, value: result}
+		}), nil
+	}
+	return nil, fail("unknown node")
+}
+
+// isNullable tells if the node can match empty input
+func (c *compiler) isNullable(node *tagNode, structType reflect.Type) bool {
+	switch node.kind {
+	case tagSequence:
+		for _, child := range node.children {
+			if !c.isNullable(child, structType) {
+				return false
+			}
+		}
+		return true
+	case tagChoice:
+		for _, child := range node.children {
+			if c.isNullable(child, structType) {
+				return true
+			}
+		}
+		return false
+	case tagRepeat:
+		return node.op != '+' || c.isNullable(node.children[0], structType)
+	case tagCapture:
+		return c.isNullable(node.children[0], structType)
+	case tagStruct:
+		elemType := structType.Field(node.field).Type
+		for elemType.Kind() == reflect.Slice || elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		// The struct types being built are considered not nullable
+		return c.nullable[elemType]
+	case tagRegexp:
+		// The invalid regular expressions are reported by compile
+		re, err := regexp.Compile(`^(?:` + node.text + `)$`)
+		return err == nil && re.MatchString("")
+	}
+	return false
+}
+
+// token returns with a parser of the token, that skips the whitespace before it
+func token(parser *parc.Parser) *parc.Parser {
+	return parc.SequenceOf(whitespace, parser).Map(func(result parc.Result) parc.Result {
+		return result.([]parc.Result)[1]
+	}).As(parser.Name())
+}
+
+// isWord tests if the literal is made of letters, digits and underscores
+func isWord(text string) bool {
+	for _, r := range text {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsCapture tests if the node contains a capture
+func containsCapture(node *tagNode) bool {
+	if node.kind == tagCapture || node.kind == tagStruct {
+		return true
+	}
+	for _, child := range node.children {
+		if containsCapture(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// textUnmarshalerType is the type of the encoding.TextUnmarshaler interface
+var textUnmarshalerType = reflect.TypeFor[interface{ UnmarshalText([]byte) error }]()
+
+// isTextTarget tests if the captured text can be stored into the type
+func isTextTarget(fieldType reflect.Type) bool {
+	if reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch fieldType.Kind() {
+	case reflect.Slice, reflect.Pointer:
+		return isTextTarget(fieldType.Elem())
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// joinText returns with the concatenated text of the string results
+func joinText(result parc.Result) string {
+	switch r := result.(type) {
+	case string:
+		return r
+	case []parc.Result:
+		text := ""
+		for _, item := range r {
+			text += joinText(item)
+		}
+		return text
+	}
+	return ""
+}
+
+// assignCaptures stores the captured values of the result into the fields of the struct
+func assignCaptures(value reflect.Value, result parc.Result) error {
+	switch r := result.(type) {
+	case capturedValue:
+		return assign(value.Field(r.field), value.Type().Field(r.field).Name, r.value)
+	case []parc.Result:
+		for _, item := range r {
+			if err := assignCaptures(value, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// assign stores the captured value into the field. The slices get a new item.
+func assign(field reflect.Value, name string, value any) error {
+	captured, isText := value.(parc.Captured)
+	switch {
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8:
+		item := reflect.New(field.Type().Elem()).Elem()
+		if err := assign(item, name, value); err != nil {
+			return err
+		}
+		field.Set(reflect.Append(field, item))
+	case !isText:
+		// The result of @@ is a pointer to the struct
+		if field.Kind() == reflect.Pointer {
+			field.Set(reflect.ValueOf(value))
+		} else {
+			field.Set(reflect.ValueOf(value).Elem())
+		}
+	case field.Kind() == reflect.Pointer && !field.Type().Implements(textUnmarshalerType):
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return assign(field.Elem(), name, value)
+	case field.Kind() == reflect.Bool:
+		field.SetBool(true)
+	default:
+		if err := parc.Decode(captured, field.Addr().Interface()); err != nil {
+			var decodeError *parc.DecodeError
+			if errors.As(err, &decodeError) {
+				decodeError.Field = name
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package grammar
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/tombenke/parc"
+	"testing"
+)
+
+type call struct {
+	Name string `parc:"@Ident"`
+	Args []*arg `parc:"'(' (@@ (',' @@)*)? ')'"`
+}
+
+type arg struct {
+	Number *float64 `parc:"@Float"`
+	Text   *string  `parc:"| @String"`
+	Call   *call    `parc:"| @@"`
+}
+
+func TestBuild(t *testing.T) {
+	g, err := Build[call]()
+	require.NoError(t, err)
+
+	actual, err := g.Parse(`print(1.5, "a\tb", max( 2 ,3 ), now())`)
+	require.NoError(t, err)
+	require.Equal(t, &call{Name: "print", Args: []*arg{
+		{Number: parc.Ref(1.5)},
+		{Text: parc.Ref("a\tb")},
+		{Call: &call{Name: "max", Args: []*arg{{Number: parc.Ref(2.0)}, {Number: parc.Ref(3.0)}}}},
+		{Call: &call{Name: "now"}},
+	}}, actual)
+
+	_, err = g.Parse("print(1,")
+	require.Error(t, err)
+
+	// The parser of the whole input cannot be repeated, but the rule can be used as a part of other parsers
+	input := "f(1) g(2)"
+	newState := parc.OneOrMore(g.Parser()).Parse(&input)
+	require.True(t, newState.IsError)
+	newState = parc.OneOrMore(g.Rule()).Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []parc.Result{
+		&call{Name: "f", Args: []*arg{{Number: parc.Ref(1.0)}}},
+		&call{Name: "g", Args: []*arg{{Number: parc.Ref(2.0)}}},
+	}, newState.Results)
+	require.Equal(t, len(input), newState.Index)
+}
+
+type declaration struct {
+	Const  bool     `parc:"@'const'?"`
+	Name   string   `parc:"@Ident"`
+	Type   *string  `parc:"(':' @Ident)?"`
+	Values []int8   `parc:"'=' @Int (',' @Int)*"`
+	Tags   []string `parc:"('#' @/[a-z]+(-[a-z]+)*/)*"`
+	// Comment is not the part of the grammar
+	Comment string
+}
+
+func TestBuild_Declaration(t *testing.T) {
+	g := MustBuild[declaration]()
+
+	actual, err := g.Parse("const answer: int = 42, -1 #final #read-only")
+	require.NoError(t, err)
+	require.Equal(t, &declaration{Const: true, Name: "answer", Type: parc.Ref("int"), Values: []int8{42, -1}, Tags: []string{"final", "read-only"}}, actual)
+
+	// The keyword literals match whole words only
+	actual, err = g.Parse("constant = 1")
+	require.NoError(t, err)
+	require.Equal(t, &declaration{Name: "constant", Values: []int8{1}}, actual)
+
+	_, err = g.Parse("x = 1, 300")
+	// The decoding error is reported at the position of the field
+	require.EqualError(t, err, "1:1: SequenceOf(): 1:8: Decode: field Values [7:10]: strconv.ParseInt: parsing \"300\": value out of range")
+
+	_, err = g.Parse("const x = 1,\n  2, -129")
+	require.ErrorContains(t, err, "2:6: Decode: field Values [18:22]:")
+}
+
+type (
+	unterminated struct {
+		A string `parc:"'(' @Ident"`
+		B string `parc:"')"`
+	}
+	unknownToken struct {
+		A string `parc:"@Idnt"`
+	}
+	missingParen struct {
+		A string `parc:"('(' @Ident"`
+	}
+	structOfString struct {
+		A string `parc:"@@"`
+	}
+	captureIntoMap struct {
+		A map[string]string `parc:"@Ident"`
+	}
+	nullableRepetition struct {
+		A []string `parc:"(@Ident?)*"`
+	}
+	nestedCapture struct {
+		A string `parc:"@('a' @Ident)"`
+	}
+	invalidRegexp struct {
+		A string `parc:"@/[a-z/"`
+	}
+	invalidRepeatedRegexp struct {
+		X []string `parc:"@/[a-/*"`
+	}
+	invalidGroupedRegexp struct {
+		X []string `parc:"(@/[a-/)+"`
+	}
+	noFields struct {
+		A string
+	}
+	invalidNested struct {
+		A *unknownToken `parc:"@@"`
+	}
+)
+
+func TestBuild_Errors(t *testing.T) {
+	for _, test := range []struct {
+		build func() error
+		err   string
+	}{
+		{func() error { _, err := Build[unterminated](); return err }, "grammar: grammar.unterminated.B: unterminated ' at offset 0"},
+		{func() error { _, err := Build[unknownToken](); return err }, "grammar: grammar.unknownToken.A: unknown token Idnt at offset 1"},
+		{func() error { _, err := Build[missingParen](); return err }, "grammar: grammar.missingParen.A: expected ')' at offset 11"},
+		{func() error { _, err := Build[structOfString](); return err }, "grammar: grammar.structOfString.A: cannot parse string by @@ at offset 0"},
+		{func() error { _, err := Build[captureIntoMap](); return err }, "grammar: grammar.captureIntoMap.A: cannot capture text into map[string]string at offset 0"},
+		{func() error { _, err := Build[nullableRepetition](); return err }, "grammar: grammar.nullableRepetition.A: repetition of an expression, that can match empty input at offset 1"},
+		{func() error { _, err := Build[nestedCapture](); return err }, "grammar: grammar.nestedCapture.A: nested capture at offset 0"},
+		{func() error { _, err := Build[invalidRegexp](); return err }, "grammar: grammar.invalidRegexp.A: invalid regular expression: error parsing regexp: missing closing ]: `[a-z` at offset 1"},
+		{func() error { _, err := Build[invalidRepeatedRegexp](); return err }, "grammar: grammar.invalidRepeatedRegexp.X: invalid regular expression: error parsing regexp: missing closing ]: `[a-` at offset 1"},
+		{func() error { _, err := Build[invalidGroupedRegexp](); return err }, "grammar: grammar.invalidGroupedRegexp.X: invalid regular expression: error parsing regexp: missing closing ]: `[a-` at offset 2"},
+		{func() error { _, err := Build[noFields](); return err }, "grammar: grammar.noFields has no tagged fields"},
+		{func() error { _, err := Build[invalidNested](); return err }, "grammar: grammar.unknownToken.A: unknown token Idnt at offset 1"},
+		{func() error { _, err := Build[int](); return err }, "grammar: int is not a struct"},
+	} {
+		require.EqualError(t, test.build(), test.err)
+	}
+	require.Panics(t, func() { MustBuild[noFields]() })
+}
@@ -0,0 +1,234 @@
+package grammar
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tagKind is the kind of a node of the tag grammar
+type tagKind int
+
+const (
+	// tagSequence matches its children one after the other
+	tagSequence tagKind = iota
+	// tagChoice matches the first matching child
+	tagChoice
+	// tagRepeat matches its child according to the op: `*`, `+` or `?`
+	tagRepeat
+	// tagLiteral matches the text, e.g. `'('`
+	tagLiteral
+	// tagToken matches a token of the named class, e.g. `Ident`
+	tagToken
+	// tagRegexp matches the regular expression of the text, e.g. `/[a-z]+/`
+	tagRegexp
+	// tagCapture stores the text matched by its child into the field, e.g. `@Ident`
+	tagCapture
+	// tagStruct parses the struct type of the field and stores it into the field, e.g. `@@`
+	tagStruct
+)
+
+// tagNode is a node of the syntax tree of the tag grammar
+type tagNode struct {
+	kind     tagKind
+	text     string
+	op       byte
+	children []*tagNode
+	// field is the index of the struct field, whose tag holds the node
+	field int
+	// offset is the byte offset of the node in the tag
+	offset int
+}
+
+// tagLexeme is a lexical item of the tags of a struct
+type tagLexeme struct {
+	// text is the text of the token, with the quotes and slashes of the literals and regular expressions
+	text string
+	// field is the index of the struct field, whose tag holds the token
+	field int
+	// offset is the byte offset of the token in the tag
+	offset int
+}
+
+// tagError is a syntax error of a tag
+type tagError struct {
+	field  int
+	offset int
+	msg    string
+}
+
+// Error returns with the error message
+func (e *tagError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.msg, e.offset)
+}
+
+// tokenizeTag appends the tokens of the tag of the field to the tokens
+func tokenizeTag(tag string, field int, tokens []tagLexeme) ([]tagLexeme, error) {
+	for i := 0; i < len(tag); {
+		start := i
+		switch c := tag[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+			continue
+		case c == '@' && strings.HasPrefix(tag[i:], "@@"):
+			i += 2
+		case strings.IndexByte("@()|*+?", c) >= 0:
+			i++
+		case c == '\'' || c == '"' || c == '/':
+			i++
+			for i < len(tag) && tag[i] != c {
+				if tag[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(tag) {
+				return nil, &tagError{field: field, offset: start, msg: fmt.Sprintf("unterminated %c", c)}
+			}
+			i++
+		case c == '_' || unicode.IsLetter(rune(c)):
+			for i < len(tag) && (tag[i] == '_' || unicode.IsLetter(rune(tag[i])) || unicode.IsDigit(rune(tag[i]))) {
+				i++
+			}
+		default:
+			return nil, &tagError{field: field, offset: start, msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+		tokens = append(tokens, tagLexeme{text: tag[start:i], field: field, offset: start})
+	}
+	return tokens, nil
+}
+
+// tagParser is a recursive descent parser of the tokens of the tags of a struct
+type tagParser struct {
+	tokens []tagLexeme
+	pos    int
+}
+
+// parseTags parses the tokens into a syntax tree
+func parseTags(tokens []tagLexeme) (*tagNode, error) {
+	p := &tagParser{tokens: tokens}
+	node, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return node, nil
+}
+
+// peek returns with the text of the next token, or an empty string at the end
+func (p *tagParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].text
+	}
+	return ""
+}
+
+// errorf returns with a syntax error at the next token, or at the end of the last token
+func (p *tagParser) errorf(format string, args ...any) error {
+	if p.pos < len(p.tokens) {
+		token := p.tokens[p.pos]
+		return &tagError{field: token.field, offset: token.offset, msg: fmt.Sprintf(format, args...)}
+	}
+	last := p.tokens[len(p.tokens)-1]
+	return &tagError{field: last.field, offset: last.offset + len(last.text), msg: fmt.Sprintf(format, args...)}
+}
+
+// expression := sequence ('|' sequence)*
+func (p *tagParser) expression() (*tagNode, error) {
+	alternatives := []*tagNode{}
+	for {
+		sequence, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, sequence)
+		if p.peek() != "|" {
+			break
+		}
+		p.pos++
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return &tagNode{kind: tagChoice, children: alternatives, field: alternatives[0].field, offset: alternatives[0].offset}, nil
+}
+
+// sequence := term+
+func (p *tagParser) sequence() (*tagNode, error) {
+	terms := []*tagNode{}
+	for p.peek() != "" && p.peek() != "|" && p.peek() != ")" {
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return nil, p.errorf("expected an expression")
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return &tagNode{kind: tagSequence, children: terms, field: terms[0].field, offset: terms[0].offset}, nil
+}
+
+// term := atom ('*' | '+' | '?')?
+func (p *tagParser) term() (*tagNode, error) {
+	atom, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peek(); op == "*" || op == "+" || op == "?" {
+		p.pos++
+		return &tagNode{kind: tagRepeat, op: op[0], children: []*tagNode{atom}, field: atom.field, offset: atom.offset}, nil
+	}
+	return atom, nil
+}
+
+// atom := '@@' | '@' atom | '(' expression ')' | literal | regexp | token
+func (p *tagParser) atom() (*tagNode, error) {
+	if p.pos == len(p.tokens) {
+		return nil, p.errorf("expected an expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch c := token.text[0]; {
+	case token.text == "@@":
+		return &tagNode{kind: tagStruct, field: token.field, offset: token.offset}, nil
+	case c == '@':
+		atom, err := p.atom()
+		if err != nil {
+			return nil, err
+		}
+		if atom.kind == tagCapture || atom.kind == tagStruct {
+			p.pos--
+			return nil, p.errorf("nested capture")
+		}
+		return &tagNode{kind: tagCapture, children: []*tagNode{atom}, field: token.field, offset: token.offset}, nil
+	case c == '(':
+		expression, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return expression, nil
+	case c == '\'' || c == '"':
+		text := strings.ReplaceAll(token.text[1:len(token.text)-1], "\\"+string(c), string(c))
+		if text == "" {
+			p.pos--
+			return nil, p.errorf("empty literal")
+		}
+		return &tagNode{kind: tagLiteral, text: text, field: token.field, offset: token.offset}, nil
+	case c == '/':
+		return &tagNode{kind: tagRegexp, text: strings.ReplaceAll(token.text[1:len(token.text)-1], `\/`, "/"), field: token.field, offset: token.offset}, nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		return &tagNode{kind: tagToken, text: token.text, field: token.field, offset: token.offset}, nil
+	}
+	p.pos--
+	return nil, p.errorf("unexpected %q", token.text)
+}
@@ -0,0 +1,64 @@
+package grammar
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTokenizeTag(t *testing.T) {
+	tokens, err := tokenizeTag(`@Ident ('it\'s' | @@)* /a\/b/`, 1, nil)
+	require.NoError(t, err)
+	texts := []string{}
+	for _, token := range tokens {
+		require.Equal(t, 1, token.field)
+		texts = append(texts, token.text)
+	}
+	require.Equal(t, []string{"@", "Ident", "(", `'it\'s'`, "|", "@@", ")", "*", `/a\/b/`}, texts)
+	require.Equal(t, 7, tokens[2].offset)
+
+	_, err = tokenizeTag("@Ident ; @Int", 0, nil)
+	require.EqualError(t, err, "unexpected character ';' at offset 7")
+	_, err = tokenizeTag(`'a' "b`, 0, nil)
+	require.EqualError(t, err, `unterminated " at offset 4`)
+}
+
+func TestParseTags(t *testing.T) {
+	tokens, err := tokenizeTag(`@Ident ('it\'s' | @@)* /a\/b/`, 0, nil)
+	require.NoError(t, err)
+	node, err := parseTags(tokens)
+	require.NoError(t, err)
+
+	require.Equal(t, tagSequence, node.kind)
+	require.Len(t, node.children, 3)
+	require.Equal(t, tagCapture, node.children[0].kind)
+	require.Equal(t, tagToken, node.children[0].children[0].kind)
+	require.Equal(t, "Ident", node.children[0].children[0].text)
+
+	repeat := node.children[1]
+	require.Equal(t, tagRepeat, repeat.kind)
+	require.Equal(t, byte('*'), repeat.op)
+	require.Equal(t, tagChoice, repeat.children[0].kind)
+	require.Equal(t, tagLiteral, repeat.children[0].children[0].kind)
+	require.Equal(t, "it's", repeat.children[0].children[0].text)
+	require.Equal(t, tagStruct, repeat.children[0].children[1].kind)
+
+	require.Equal(t, tagRegexp, node.children[2].kind)
+	require.Equal(t, "a/b", node.children[2].text)
+	require.Equal(t, 23, node.children[2].offset)
+
+	for _, test := range []struct {
+		tag string
+		err string
+	}{
+		{"@ @@", "nested capture at offset 2"},
+		{"'a' |", "expected an expression at offset 5"},
+		{"'a' ''", "empty literal at offset 4"},
+		{"'a' )", `unexpected ")" at offset 4`},
+		{"@*", `unexpected "*" at offset 1`},
+	} {
+		tokens, err := tokenizeTag(test.tag, 0, nil)
+		require.NoError(t, err)
+		_, err = parseTags(tokens)
+		require.EqualError(t, err, test.err, test.tag)
+	}
+}
@@ -0,0 +1,306 @@
+package parc
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextEdit describes a change of the input, e.g. a keystroke in an editor
+type TextEdit struct {
+	// Offset is the byte offset of the change in the original input
+	Offset int
+	// Deleted is the number of bytes deleted from the offset
+	Deleted int
+	// Inserted is the text inserted at the offset
+	Inserted string
+}
+
+// Apply returns with the input changed by the edit
+func (e TextEdit) Apply(input string) (string, error) {
+	if e.Offset < 0 || e.Deleted < 0 || e.Offset+e.Deleted > len(input) {
+		return input, fmt.Errorf("TextEdit: invalid range %d+%d of input of length %d", e.Offset, e.Deleted, len(input))
+	}
+	return input[:e.Offset] + e.Inserted + input[e.Offset+e.Deleted:], nil
+}
+
+// IncrementalStats holds the statistics of the last parsing of an IncrementalParser
+type IncrementalStats struct {
+	// Reused is the number of the Memo parser results reused from the previous parsing
+	Reused int
+	// Parsed is the number of the Memo parser results computed by running the parser
+	Parsed int
+}
+
+// IncrementalParser parses an input, that changes by small edits between the parsings, e.g. the buffer of an editor.
+// After an edit it reuses the results of the Memo parsers of the previous parsing, whose examined input was not touched by the edit,
+// and it runs only the parsers of the affected regions. The result is the same as the result of the full parsing of the new input.
+//
+// The parsers between the edit and the end of its line are always run again, because the parsers may look
+// back to the beginning of the line, e.g. the layout parsers. The results of the Memo parsers after the edit are reused
+// at a shifted position: the spans of the Node and Captured results are shifted too, but the other results
+// must not hold absolute input positions. The results of the Memo parsers are not reused inside the indented blocks
+// of the layout parsers.
+type IncrementalParser struct {
+	parser   *Parser
+	input    string
+	previous map[memoKey]memoEntry
+	stats    IncrementalStats
+}
+
+// NewIncrementalParser creates a new IncrementalParser with the parser of the whole input
+func NewIncrementalParser(parser *Parser) *IncrementalParser {
+	return &IncrementalParser{parser: parser}
+}
+
+// Parse runs the parser on the input without reusing the results of the previous parsing
+func (ip *IncrementalParser) Parse(input string) ParserState {
+	return ip.run(input, newMemoTable(nil, nil))
+}
+
+// Edit applies the edit to the input of the previous parsing, and parses the new input incrementally
+func (ip *IncrementalParser) Edit(edit TextEdit) (ParserState, error) {
+	input, err := edit.Apply(ip.input)
+	if err != nil {
+		return ParserState{}, err
+	}
+	return ip.run(input, newMemoTable(ip.previous, newMemoEdit(ip.input, edit))), nil
+}
+
+// Input returns with the input of the last parsing
+func (ip *IncrementalParser) Input() string {
+	return ip.input
+}
+
+// Stats returns with the statistics of the last parsing
+func (ip *IncrementalParser) Stats() IncrementalStats {
+	return ip.stats
+}
+
+// run parses the input using the memo table, then keeps the table for the next parsing
+func (ip *IncrementalParser) run(input string, table *memoTable) ParserState {
+	initialState := NewParserState(&input, Result(nil), 0, nil)
+	initialState.memo = table
+	newState := ip.parser.ParserFun(initialState)
+	newState.memo = nil
+	ip.input = input
+	ip.previous = table.entries
+	ip.stats = table.stats
+	return newState
+}
+
+// Memo is a parser, that memoizes the results of the parser during incremental parsing.
+// These are the units of the reuse of the IncrementalParser, e.g. the statements or the declarations of a language.
+// The results of the parser are also reused, if it is called again at the same position during the same parsing.
+// It is the same as the parser during normal parsing.
+func Memo(parser *Parser) *Parser {
+	newParser := Parser{
+		name: "Memo(" + parser.Name() + ")",
+		spec: ParserSpec{Kind: MemoKind, Parsers: []*Parser{parser}},
+	}
+	parserFun := func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		table := parserState.memo
+		if table == nil || parserState.indentation != nil {
+			return parser.ParserFun(parserState)
+		}
+		key := memoKey{parser: &newParser, index: parserState.Index}
+		if entry, ok := table.entries[key]; ok {
+			return table.apply(entry, parserState)
+		}
+		if entry, ok := table.reusable(&newParser, parserState.Index); ok {
+			table.entries[key] = entry
+			table.stats.Reused++
+			return table.apply(entry, parserState)
+		}
+
+		outerExtent := table.extent
+		table.extent = parserState.Index
+		newState := parser.ParserFun(parserState)
+		extent := max(table.extent, newState.Index)
+		table.extent = max(outerExtent, extent)
+		table.stats.Parsed++
+		if newState.indentation == nil {
+			table.entries[key] = memoEntry{
+				index:   newState.Index,
+				results: newState.Results,
+				err:     newState.Err,
+				isError: newState.IsError,
+				extent:  extent,
+			}
+		}
+		return newState
+	}
+	newParser.SetParserFun(parserFun)
+	return &newParser
+}
+
+// Lookahead declares, that the custom parser examines at most n bytes of the input beyond the index it returns with,
+// not counting the input examined by its sub-parsers. It makes possible to reuse the results of the Memo parsers,
+// that contain the custom parser, during incremental parsing. Without the declaration the custom parsers are
+// considered to examine the whole remaining input.
+func (p *Parser) Lookahead(n int) *Parser {
+	p.lookahead = &n
+	return p
+}
+
+// memoKey identifies a result of a Memo parser
+type memoKey struct {
+	parser *Parser
+	index  int
+}
+
+// memoEntry is a memoized result of a Memo parser
+type memoEntry struct {
+	index   int
+	results Result
+	err     error
+	isError bool
+	// extent is the end of the input examined by the parser
+	extent int
+}
+
+// memoEdit is the edit between the previous and the actual parsing
+type memoEdit struct {
+	TextEdit
+	// delta is the change of the length of the input
+	delta int
+	// reusableFrom is the first position of the previous input after the edit, where the results can be reused.
+	// It is the beginning of the line after the edit.
+	reusableFrom int
+	// sameRows is true if the edit does not change the number of lines, so the error positions after the edit remain the same
+	sameRows bool
+}
+
+// newMemoEdit creates the memoEdit of the edit of the previous input
+func newMemoEdit(previous string, edit TextEdit) *memoEdit {
+	end := edit.Offset + edit.Deleted
+	reusableFrom := len(previous) + 1
+	if i := strings.IndexByte(previous[end:], '\n'); i >= 0 {
+		reusableFrom = end + i + 1
+	}
+	return &memoEdit{
+		TextEdit:     edit,
+		delta:        len(edit.Inserted) - edit.Deleted,
+		reusableFrom: reusableFrom,
+		sameRows:     strings.Count(edit.Inserted, "\n") == strings.Count(previous[edit.Offset:end], "\n"),
+	}
+}
+
+// memoTable holds the results of the Memo parsers during incremental parsing
+type memoTable struct {
+	entries  map[memoKey]memoEntry
+	previous map[memoKey]memoEntry
+	edit     *memoEdit
+	// extent is the end of the input examined by the parsers since the start of the innermost Memo parser
+	extent int
+	stats  IncrementalStats
+}
+
+// newMemoTable creates a new memo table, that may reuse the entries of the previous parsing
+func newMemoTable(previous map[memoKey]memoEntry, edit *memoEdit) *memoTable {
+	return &memoTable{entries: map[memoKey]memoEntry{}, previous: previous, edit: edit}
+}
+
+// reusable returns with the entry of the previous parsing, that is valid at the index of the actual input
+func (t *memoTable) reusable(parser *Parser, index int) (memoEntry, bool) {
+	edit := t.edit
+	if edit == nil {
+		return memoEntry{}, false
+	}
+	switch {
+	case index < edit.Offset:
+		entry, ok := t.previous[memoKey{parser: parser, index: index}]
+		return entry, ok && entry.extent <= edit.Offset
+	case index >= edit.Offset+len(edit.Inserted) && index-edit.delta >= edit.reusableFrom:
+		entry, ok := t.previous[memoKey{parser: parser, index: index - edit.delta}]
+		if !ok || entry.isError && !edit.sameRows {
+			return memoEntry{}, false
+		}
+		entry.index += edit.delta
+		entry.extent += edit.delta
+		entry.results = shiftSpans(entry.results, edit.delta)
+		return entry, true
+	}
+	return memoEntry{}, false
+}
+
+// shiftSpans returns with the result, whose Node and Captured spans are moved by delta.
+// The nodes and the arrays are copied, so the results of the previous parsing are not changed.
+func shiftSpans(result Result, delta int) Result {
+	if delta == 0 {
+		return result
+	}
+	switch r := result.(type) {
+	case *Node:
+		if r == nil {
+			return r
+		}
+		node := *r
+		node.Span = Span{Start: r.Span.Start + delta, End: r.Span.End + delta}
+		node.Value = shiftSpans(r.Value, delta)
+		if r.Children != nil {
+			node.Children = make([]*Node, len(r.Children))
+			for i, child := range r.Children {
+				node.Children[i] = shiftSpans(child, delta).(*Node)
+			}
+		}
+		return &node
+	case Captured:
+		r.Span = Span{Start: r.Span.Start + delta, End: r.Span.End + delta}
+		r.Value = shiftSpans(r.Value, delta)
+		return r
+	case []Result:
+		if r == nil {
+			return r
+		}
+		results := make([]Result, len(r))
+		for i, item := range r {
+			results[i] = shiftSpans(item, delta)
+		}
+		return results
+	}
+	return result
+}
+
+// apply returns with the state of the memoized result
+func (t *memoTable) apply(entry memoEntry, parserState ParserState) ParserState {
+	t.extent = max(t.extent, entry.extent)
+	newState := UpdateParserState(parserState, entry.index, entry.results)
+	newState.Err = entry.err
+	newState.IsError = entry.isError
+	return newState
+}
+
+// examine extends the examined input with the input examined by a parser call
+func (t *memoTable) examine(p *Parser, parserState, newState ParserState) {
+	index := max(parserState.Index, newState.Index)
+	extent := index
+	switch p.spec.Kind {
+	case CharKind, StrKind:
+		extent = max(index, parserState.Index+len(p.spec.Literal))
+	case CondKind, CondMinKind, CondMinMaxKind:
+		// These parsers examine the rune at the index, or the end of the input
+		remaining := (*parserState.inputString)[index:]
+		if utf8.FullRuneInString(remaining) {
+			_, size := utf8.DecodeRuneInString(remaining)
+			extent = index + size
+		} else {
+			extent = parserState.InputLength() + 1
+		}
+	case EndOfInputKind:
+		extent = index + 1
+	case RestKind, RegExpKind:
+		// These parsers may examine the end of the input too
+		extent = parserState.InputLength() + 1
+	case CustomParserKind:
+		if p.lookahead == nil {
+			extent = parserState.InputLength() + 1
+		} else {
+			extent = index + *p.lookahead
+		}
+	}
+	t.extent = max(t.extent, extent)
+}
@@ -0,0 +1,169 @@
+package parc
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// statements returns a parser of `name = value;` lines, where the value is a number or a nested list of values
+func statements() *Parser {
+	return SequenceOf(ZeroOrMore(statementLine(func(kind string, parser *Parser) *Parser { return parser })), EndOfInput())
+}
+
+// nodeStatements returns a parser of statement lines, that results in nodes and captures holding their spans.
+// The invalid lines are skipped, so the statements after them are parsed too.
+func nodeStatements() *Parser {
+	line := statementLine(func(kind string, parser *Parser) *Parser {
+		return NodeOf(kind, Capture(kind, parser))
+	})
+	invalidLine := SequenceOf(CondMin(func(r rune) bool { return r != '\n' }, 0), Newline)
+	return ZeroOrMore(Choice(line, invalidLine))
+}
+
+// statementLine returns a parser of a statement line, whose lists and statements are wrapped by the given function
+func statementLine(wrap func(kind string, parser *Parser) *Parser) *Parser {
+	var value Parser
+	list := Memo(wrap("list", SequenceOf(
+		Char("("),
+		ZeroOrMore(SequenceOf(ZeroOrMore(Space), &value).Map(func(result Result) Result {
+			return result.([]Result)[1]
+		})),
+		ZeroOrMore(Space),
+		Char(")"),
+	).Map(func(result Result) Result {
+		return result.([]Result)[1]
+	})))
+	value = *Choice(Digits, list)
+	statement := Memo(wrap("statement", SequenceOf(Letters, Str(" = "), &value, Char(";")).Map(func(result Result) Result {
+		arr := result.([]Result)
+		return []Result{arr[0], arr[2]}
+	})))
+	return SequenceOf(statement, Newline)
+}
+
+// requireSameState checks that the incremental parsing resulted in the same state as the full parsing
+func requireSameState(t *testing.T, expected, actual ParserState, msgAndArgs ...any) {
+	t.Helper()
+	require.Equal(t, expected.IsError, actual.IsError, msgAndArgs...)
+	require.Equal(t, fmt.Sprint(expected.Err), fmt.Sprint(actual.Err), msgAndArgs...)
+	require.Equal(t, expected.Index, actual.Index, msgAndArgs...)
+	require.Equal(t, expected.Results, actual.Results, msgAndArgs...)
+}
+
+func TestIncrementalParser_Edit(t *testing.T) {
+	lines := []string{}
+	for i := 0; i < 50; i++ {
+		lines = append(lines, fmt.Sprintf("v = (%d (1 2) (3 (4 5)));\n", i))
+	}
+	input := strings.Join(lines, "")
+	parser := statements()
+	ip := NewIncrementalParser(parser)
+	requireSameState(t, parser.Parse(&input), ip.Parse(input))
+	require.Equal(t, IncrementalStats{Reused: 0, Parsed: 451}, ip.Stats())
+
+	offset := strings.Index(input, "(25 ") + 1
+	newState, err := ip.Edit(TextEdit{Offset: offset, Deleted: 2, Inserted: "7 77"})
+	require.NoError(t, err)
+	newInput := ip.Input()
+	require.Equal(t, "v = (7 77 (1 2) (3 (4 5)));\n", newInput[offset-5:offset+23])
+	requireSameState(t, parser.Parse(&newInput), newState)
+	require.Equal(t, IncrementalStats{Reused: 50, Parsed: 9}, ip.Stats())
+
+	// Breaking a line reports the same error as the full parsing
+	newState, err = ip.Edit(TextEdit{Offset: offset, Inserted: ")"})
+	require.NoError(t, err)
+	newInput = ip.Input()
+	requireSameState(t, parser.Parse(&newInput), newState)
+	require.True(t, newState.IsError)
+}
+
+func TestIncrementalParser_EditRandom(t *testing.T) {
+	alphabet := []string{"a", "b", "1", "2", "(", ")", " ", " = ", ";", "\n", "x = (1);\n"}
+	// The spans of the nodes and the captures must be shifted, when their results are reused after the edit
+	for _, parser := range []*Parser{statements(), nodeStatements()} {
+		random := rand.New(rand.NewSource(42))
+		ip := NewIncrementalParser(parser)
+		ip.Parse("a = 1;\nb = (1 (2 3));\nc = ((1) 2);\n")
+		for i := 0; i < 2000; i++ {
+			input := ip.Input()
+			offset := random.Intn(len(input) + 1)
+			edit := TextEdit{Offset: offset, Deleted: random.Intn(min(3, len(input)-offset) + 1)}
+			if random.Intn(3) > 0 || len(input) < 10 {
+				edit.Inserted = alphabet[random.Intn(len(alphabet))]
+			}
+			newState, err := ip.Edit(edit)
+			require.NoError(t, err)
+			newInput := ip.Input()
+			requireSameState(t, parser.Parse(&newInput), newState, "%q after %+v", newInput, edit)
+		}
+	}
+}
+
+func TestIncrementalParser_EditShiftsSpans(t *testing.T) {
+	parser := ZeroOrMore(Memo(NodeOf("line", SequenceOf(Letters, Newline))))
+	ip := NewIncrementalParser(parser)
+	ip.Parse("ab\ncd\nef\n")
+	newState, err := ip.Edit(TextEdit{Offset: 0, Inserted: "x"})
+	require.NoError(t, err)
+	require.Equal(t, IncrementalStats{Reused: 3, Parsed: 1}, ip.Stats())
+	spans := []Span{}
+	for _, node := range newState.Results.([]Result) {
+		spans = append(spans, node.(*Node).Span)
+	}
+	require.Equal(t, []Span{{0, 4}, {4, 7}, {7, 10}}, spans)
+}
+
+func TestIncrementalParser_EditInvalidRange(t *testing.T) {
+	ip := NewIncrementalParser(statements())
+	ip.Parse("a = 1;\n")
+	_, err := ip.Edit(TextEdit{Offset: 5, Deleted: 3})
+	require.EqualError(t, err, "TextEdit: invalid range 5+3 of input of length 7")
+	require.Equal(t, "a = 1;\n", ip.Input())
+}
+
+func TestMemo(t *testing.T) {
+	calls := 0
+	counted := NewParser("counted", func(parserState ParserState) ParserState {
+		calls++
+		return Letters.ParserFun(parserState)
+	}).Lookahead(0)
+	word := Memo(counted)
+	parser := Choice(SequenceOf(word, Digits), SequenceOf(word, Space))
+
+	// Normal parsing runs the parser every time
+	input := "abc "
+	newState := parser.Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{"abc", " "}, newState.Results)
+	require.Equal(t, 2, calls)
+
+	// Incremental parsing reuses the result at the same position
+	calls = 0
+	ip := NewIncrementalParser(parser)
+	newState = ip.Parse(input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{"abc", " "}, newState.Results)
+	require.Equal(t, 1, calls)
+	require.Equal(t, MemoKind, word.Spec().Kind)
+}
+
+func TestLookahead(t *testing.T) {
+	// The custom parser without declared lookahead is considered to examine the whole input
+	undeclared := Memo(NewParser("undeclared", Letters.ParserFun))
+	ip := NewIncrementalParser(SequenceOf(undeclared, Rest()))
+	ip.Parse("abc 12345")
+	_, err := ip.Edit(TextEdit{Offset: 8, Deleted: 1, Inserted: "6"})
+	require.NoError(t, err)
+	require.Equal(t, IncrementalStats{Reused: 0, Parsed: 1}, ip.Stats())
+
+	declared := Memo(NewParser("declared", Letters.ParserFun).Lookahead(0))
+	ip = NewIncrementalParser(SequenceOf(declared, Rest()))
+	ip.Parse("abc 12345")
+	newState, err := ip.Edit(TextEdit{Offset: 8, Deleted: 1, Inserted: "6"})
+	require.NoError(t, err)
+	require.Equal(t, IncrementalStats{Reused: 1, Parsed: 0}, ip.Stats())
+	require.Equal(t, []Result{"abc", " 12346"}, newState.Results)
+}
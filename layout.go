@@ -0,0 +1,331 @@
+package parc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// indentLevel is an item of the indentation stack of the parser state
+type indentLevel struct {
+	// indent is the whitespace at the beginning of the lines of the level
+	indent string
+	// parent is the enclosing level
+	parent *indentLevel
+}
+
+// Indentation returns with the actual indentation level, that is the leading whitespace of the lines of the innermost indented block.
+// It is empty at the top level.
+func (ps ParserState) Indentation() string {
+	if ps.indentation == nil {
+		return ""
+	}
+	return ps.indentation.indent
+}
+
+// IndentationDepth returns with the number of the levels of the indentation stack
+func (ps ParserState) IndentationDepth() int {
+	depth := 0
+	for level := ps.indentation; level != nil; level = level.parent {
+		depth++
+	}
+	return depth
+}
+
+// pushIndentation returns with a copy of the state, that has the indent as its innermost indentation level
+func (ps ParserState) pushIndentation(indent string) ParserState {
+	ps.indentation = &indentLevel{indent: indent, parent: ps.indentation}
+	return ps
+}
+
+// popIndentation returns with a copy of the state, that does not have its innermost indentation level
+func (ps ParserState) popIndentation() ParserState {
+	if ps.indentation != nil {
+		ps.indentation = ps.indentation.parent
+	}
+	return ps
+}
+
+var (
+	// Indent matches the end of the actual line, the following blank lines and the indentation of the next line,
+	// that must be deeper than the actual indentation level. It pushes the new indentation onto the indentation stack,
+	// and returns with the indentation as a string.
+	Indent = NewParser("Indent", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		line, err := nextLayoutLine(parserState)
+		if err != nil {
+			return UpdateParserError(line.state, fmt.Errorf("Indent: %w", err))
+		}
+		if line.atEnd {
+			return UpdateParserError(line.state, fmt.Errorf("Indent: expected an indented line, got Unexpected end of input"))
+		}
+		if cmp, errState := line.compare("Indent", parserState.Indentation()); errState != nil {
+			return *errState
+		} else if cmp <= 0 {
+			return UpdateParserError(line.state, fmt.Errorf("Indent: expected an indented line"))
+		}
+		return UpdateParserState(line.state.pushIndentation(line.indent), line.state.Index, Result(line.indent))
+	})
+
+	// SameIndent matches the end of the actual line, the following blank lines and the indentation of the next line,
+	// that must be the same as the actual indentation level. It returns with the indentation as a string.
+	SameIndent = NewParser("SameIndent", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		line, err := nextLayoutLine(parserState)
+		if err != nil {
+			return UpdateParserError(line.state, fmt.Errorf("SameIndent: %w", err))
+		}
+		if line.atEnd {
+			return UpdateParserError(line.state, fmt.Errorf("SameIndent: expected a line, got Unexpected end of input"))
+		}
+		cmp, errState := line.compare("SameIndent", parserState.Indentation())
+		switch {
+		case errState != nil:
+			return *errState
+		case cmp > 0:
+			return UpdateParserError(line.state, fmt.Errorf("SameIndent: unexpected indentation"))
+		case cmp < 0:
+			return UpdateParserError(line.state, fmt.Errorf("SameIndent: unexpected unindent"))
+		}
+		return UpdateParserState(line.state, line.state.Index, Result(line.indent))
+	})
+
+	// Dedent matches if the next non-blank line is less indented than the actual indentation level, or the input ends.
+	// It pops the actual level from the indentation stack, and returns with nil.
+	// It does not consume any input, so a line may close several levels by consecutive Dedent parsers,
+	// and then the line can be matched by SameIndent. The indentation of the line must be the same as one of the enclosing levels.
+	Dedent = NewParser("Dedent", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		if parserState.indentation == nil {
+			return UpdateParserError(parserState, fmt.Errorf("Dedent: there is no indentation level to close"))
+		}
+		line, err := nextLayoutLine(parserState)
+		if err != nil {
+			return UpdateParserError(line.state, fmt.Errorf("Dedent: %w", err))
+		}
+		if !line.atEnd {
+			cmp, errState := line.compare("Dedent", parserState.Indentation())
+			if errState != nil {
+				return *errState
+			}
+			if cmp >= 0 {
+				return UpdateParserError(line.state, fmt.Errorf("Dedent: expected unindent"))
+			}
+			if cmp, errState := line.compare("Dedent", parserState.popIndentation().Indentation()); errState != nil {
+				return *errState
+			} else if cmp > 0 {
+				return UpdateParserError(line.state, fmt.Errorf("Dedent: unindent does not match any outer indentation level"))
+			}
+		}
+		return UpdateParserState(parserState.popIndentation(), parserState.Index, nil)
+	})
+)
+
+// NonIndented runs the parser only at the beginning of a line without indentation, e.g. to parse the top level items of a layout-sensitive grammar
+func NonIndented(parser *Parser) *Parser {
+	return NewParser("NonIndented("+parser.Name()+")", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		if parserState.Index > 0 && (*parserState.inputString)[parserState.Index-1] != '\n' {
+			return UpdateParserError(parserState, fmt.Errorf("NonIndented: expected the beginning of a line"))
+		}
+		if r, _ := parserState.NextRune(); r == ' ' || r == '\t' {
+			return UpdateParserError(parserState, fmt.Errorf("NonIndented: unexpected indentation"))
+		}
+		return parser.ParserFun(parserState)
+	})
+}
+
+// IndentBlock is a parser of a header followed by a block of one or more items, e.g. a Python statement with its body,
+// or a YAML key with its nested mapping. The items start at the beginning of the lines after the header,
+// and they must be indented to the same level, that is deeper than the indentation of the line of the header.
+// The blank lines are skipped, and the block lasts until the first less indented line or the end of the input.
+// The items may be nested blocks, but they must not consume the line break after them.
+// It returns with an array of the result of the header and the array of the results of the items.
+func IndentBlock(header, item *Parser) *Parser {
+	return NewParser("IndentBlock("+header.Name()+", "+item.Name()+")", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		reference := lineIndentation(*parserState.inputString, parserState.Index)
+		headerState := header.ParserFun(parserState)
+		if headerState.IsError {
+			return headerState
+		}
+
+		line, err := nextLayoutLine(headerState)
+		if err != nil {
+			return UpdateParserError(line.state, fmt.Errorf("IndentBlock: %w", err))
+		}
+		if !line.atEnd {
+			if cmp, errState := line.compare("IndentBlock", reference); errState != nil {
+				return *errState
+			} else if cmp <= 0 {
+				line.atEnd = true
+			}
+		}
+		if line.atEnd {
+			return UpdateParserError(line.state, fmt.Errorf("IndentBlock: expected an indented block"))
+		}
+
+		level := line.indent
+		items := []Result{}
+		nextState := line.state.pushIndentation(level)
+		for {
+			itemState := item.ParserFun(nextState)
+			if itemState.IsError {
+				return itemState
+			}
+			items = append(items, itemState.Results)
+			nextState = itemState
+
+			line, err := nextLayoutLine(nextState)
+			if err != nil {
+				return UpdateParserError(line.state, fmt.Errorf("IndentBlock: %w", err))
+			}
+			if line.atEnd {
+				break
+			}
+			cmp, errState := line.compare("IndentBlock", level)
+			if errState != nil {
+				return *errState
+			}
+			if cmp > 0 {
+				return UpdateParserError(line.state, fmt.Errorf("IndentBlock: unexpected indentation"))
+			}
+			if cmp < 0 {
+				if cmp, _ := line.compare("IndentBlock", reference); cmp > 0 {
+					return UpdateParserError(line.state, fmt.Errorf("IndentBlock: unindent does not match any outer indentation level"))
+				}
+				break
+			}
+			nextState = line.state
+		}
+		nextState.indentation = parserState.indentation
+		return UpdateParserState(nextState, nextState.Index, Result([]Result{headerState.Results, items}))
+	})
+}
+
+// LineFold is a parser of a logical line, that may continue in the following lines, if they are indented deeper than its first line.
+// The makeParser function receives a whitespace parser, that matches spaces, tabs and line breaks,
+// but it consumes a line break only if the next non-blank line is a continuation line. The function returns with
+// the parser of the logical line, that uses the whitespace parser between its items.
+func LineFold(makeParser func(space *Parser) *Parser) *Parser {
+	space := NewParser("LineFoldSpace", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		nextState := parserState.Consume(len(parserState.Remaining()) - len(strings.TrimLeft(parserState.Remaining(), " \t")))
+		if !isAtLineEnd(nextState.Remaining()) {
+			return UpdateParserState(nextState, nextState.Index, nil)
+		}
+		line, err := nextLayoutLine(nextState)
+		if err != nil || line.atEnd {
+			return UpdateParserState(nextState, nextState.Index, nil)
+		}
+		cmp, errState := line.compare("LineFold", parserState.Indentation())
+		if errState != nil {
+			return *errState
+		}
+		if cmp <= 0 {
+			return UpdateParserState(nextState, nextState.Index, nil)
+		}
+		return UpdateParserState(line.state, line.state.Index, nil)
+	})
+	parser := makeParser(space)
+	return NewParser("LineFold("+parser.Name()+")", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		// The indentation of the first line is the reference of the continuation lines
+		reference := lineIndentation(*parserState.inputString, parserState.Index)
+		newState := parser.ParserFun(parserState.pushIndentation(reference))
+		newState.indentation = parserState.indentation
+		return newState
+	})
+}
+
+// layoutLine is the next non-blank line found by nextLayoutLine
+type layoutLine struct {
+	// state points to the first character after the indentation of the line, or to the end of the input
+	state ParserState
+	// indent is the indentation of the line
+	indent string
+	// atEnd is true if there is no more non-blank line
+	atEnd bool
+}
+
+// compare compares the indentation of the line with the reference indentation.
+// It returns with -1, 0 or 1 if the line is less, equally or more indented,
+// or with an error state, if the tabs and spaces are mixed in a way that the indentations are not comparable.
+func (l layoutLine) compare(name, reference string) (int, *ParserState) {
+	common := 0
+	for common < len(l.indent) && common < len(reference) && l.indent[common] == reference[common] {
+		common++
+	}
+	switch {
+	case common == len(l.indent) && common == len(reference):
+		return 0, nil
+	case common == len(reference):
+		return 1, nil
+	case common == len(l.indent):
+		return -1, nil
+	}
+	errState := UpdateParserError(UpdateParserState(l.state, l.state.Index-len(l.indent)+common, nil),
+		fmt.Errorf("%s: inconsistent use of tabs and spaces in indentation", name))
+	return 0, &errState
+}
+
+// nextLayoutLine skips the trailing whitespace and the end of the actual line, and the following blank lines.
+// It fails if the actual line has further content.
+func nextLayoutLine(parserState ParserState) (layoutLine, error) {
+	input := *parserState.inputString
+	index := parserState.Index
+	for index < len(input) && (input[index] == ' ' || input[index] == '\t') {
+		index++
+	}
+	if !isAtLineEnd(input[index:]) {
+		return layoutLine{state: UpdateParserState(parserState, index, nil)}, fmt.Errorf("expected end of line")
+	}
+	for {
+		switch {
+		case index == len(input):
+			return layoutLine{state: UpdateParserState(parserState, index, nil), atEnd: true}, nil
+		case input[index] == '\r':
+			index += 2
+		default:
+			index++
+		}
+		start := index
+		for index < len(input) && (input[index] == ' ' || input[index] == '\t') {
+			index++
+		}
+		if index == len(input) {
+			return layoutLine{state: UpdateParserState(parserState, index, nil), atEnd: true}, nil
+		}
+		if !isAtLineEnd(input[index:]) {
+			return layoutLine{state: UpdateParserState(parserState, index, nil), indent: input[start:index]}, nil
+		}
+	}
+}
+
+// isAtLineEnd tests if the text starts with a line break, or it is empty
+func isAtLineEnd(text string) bool {
+	return text == "" || text[0] == '\n' || strings.HasPrefix(text, "\r\n")
+}
+
+// lineIndentation returns with the indentation of the line, that contains the index
+func lineIndentation(input string, index int) string {
+	start := strings.LastIndexByte(input[:index], '\n') + 1
+	end := start
+	for end < len(input) && (input[end] == ' ' || input[end] == '\t') {
+		end++
+	}
+	return input[start:end]
+}
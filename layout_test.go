@@ -0,0 +1,191 @@
+package parc
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// yamlLike returns a parser of nested `key:` blocks and `key: value` lines
+func yamlLike() *Parser {
+	var entry Parser
+	key := CondMin(func(r rune) bool { return IsAlphaNumeric(r) || r == '_' }, 1)
+	value := SequenceOf(Str(": "), CondMin(func(r rune) bool { return r != '\n' }, 1)).Map(func(result Result) Result {
+		return result.([]Result)[1]
+	})
+	block := IndentBlock(SequenceOf(key, Char(":")).Map(func(result Result) Result {
+		return result.([]Result)[0]
+	}), &entry).Map(func(result Result) Result {
+		arr := result.([]Result)
+		return map[string]Result{arr[0].(string): arr[1]}
+	})
+	scalar := SequenceOf(key, value).Map(func(result Result) Result {
+		arr := result.([]Result)
+		return map[string]Result{arr[0].(string): arr[1]}
+	})
+	entry = *Choice(scalar, block)
+	return SequenceOf(NonIndented(&entry), ZeroOrMore(SequenceOf(Newline, NonIndented(&entry)).Map(func(result Result) Result {
+		return result.([]Result)[1]
+	})))
+}
+
+func TestIndentBlock(t *testing.T) {
+	input := "server:\n  host: localhost\n  tls:\n\n    cert: a.pem\n    key: a.key\n  port: 80\nname: demo"
+	newState := SequenceOf(yamlLike(), EndOfInput()).Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{
+		map[string]Result{"server": []Result{
+			map[string]Result{"host": "localhost"},
+			map[string]Result{"tls": []Result{
+				map[string]Result{"cert": "a.pem"},
+				map[string]Result{"key": "a.key"},
+			}},
+			map[string]Result{"port": "80"},
+		}},
+		[]Result{map[string]Result{"name": "demo"}},
+	}, newState.Results.([]Result)[0])
+	require.Equal(t, 0, newState.IndentationDepth())
+}
+
+func TestIndentBlockErrors(t *testing.T) {
+	key := CondMin(IsAsciiLetter, 1)
+	scalar := SequenceOf(key, Str(": "), key)
+	block := IndentBlock(SequenceOf(key, Char(":")), scalar)
+	testCases := []struct {
+		input   string
+		message string
+	}{
+		{"server:\nhost: localhost", "2:1: IndentBlock: expected an indented block"},
+		{"server:", "1:8: IndentBlock: expected an indented block"},
+		{"server: x", "1:9: IndentBlock: expected end of line"},
+		{"server:\n    host: localhost\n      port: x", "3:7: IndentBlock: unexpected indentation"},
+		{"server:\n    host: localhost\n  port: x", "3:3: IndentBlock: unindent does not match any outer indentation level"},
+		{"server:\n  host: localhost\n\tport: x", "3:1: IndentBlock: inconsistent use of tabs and spaces in indentation"},
+		{"server:\n\t host: localhost\n\t\tport: x", "3:2: IndentBlock: inconsistent use of tabs and spaces in indentation"},
+	}
+	for _, tc := range testCases {
+		newState := block.Parse(&tc.input)
+		require.True(t, newState.IsError, tc.input)
+		require.EqualError(t, newState.Err, tc.message, tc.input)
+	}
+
+	input := "  server: x"
+	newState := yamlLike().Parse(&input)
+	require.True(t, newState.IsError)
+	require.Contains(t, newState.Err.Error(), "1:1: NonIndented: unexpected indentation")
+
+	input = "a server: x"
+	newState = SequenceOf(Str("a "), NonIndented(scalar)).Parse(&input)
+	require.True(t, newState.IsError)
+	require.Contains(t, newState.Err.Error(), "1:3: NonIndented: expected the beginning of a line")
+}
+
+// pythonLike returns a parser of `if cond:` statements with indented bodies and simple statements
+func pythonLike() *Parser {
+	var statement Parser
+	name := CondMin(IsAsciiLetter, 1)
+	body := SequenceOf(Indent, &statement, ZeroOrMore(SequenceOf(SameIndent, &statement).Map(func(result Result) Result {
+		return result.([]Result)[1]
+	})), Dedent).Map(func(result Result) Result {
+		arr := result.([]Result)
+		return append([]Result{arr[1]}, arr[2].([]Result)...)
+	})
+	compound := SequenceOf(Str("if "), name, Char(":"), body).Map(func(result Result) Result {
+		arr := result.([]Result)
+		return map[string]Result{"if " + arr[1].(string): arr[3]}
+	})
+	statement = *Choice(compound, name)
+	return SequenceOf(&statement, ZeroOrMore(SequenceOf(SameIndent, &statement).Map(func(result Result) Result {
+		return result.([]Result)[1]
+	}))).Map(func(result Result) Result {
+		arr := result.([]Result)
+		return append([]Result{arr[0]}, arr[1].([]Result)...)
+	})
+}
+
+func TestIndentDedent(t *testing.T) {
+	input := "a\nif x:\n    b\n    if y:\n        c\n    \n    d\ne\n"
+	newState := pythonLike().Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{
+		"a",
+		map[string]Result{"if x": []Result{
+			"b",
+			map[string]Result{"if y": []Result{"c"}},
+			"d",
+		}},
+		"e",
+	}, newState.Results)
+	require.Equal(t, 0, newState.IndentationDepth())
+	require.Equal(t, "\n", newState.Remaining())
+
+	input = "if x:\n  if y:\n    c"
+	newState = pythonLike().Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{map[string]Result{"if x": []Result{map[string]Result{"if y": []Result{"c"}}}}}, newState.Results)
+}
+
+func TestIndentDedentErrors(t *testing.T) {
+	name := CondMin(IsAsciiLetter, 1)
+	testCases := []struct {
+		parser  *Parser
+		input   string
+		message string
+	}{
+		{SequenceOf(Str("if x:"), Indent), "if x:\nb", "2:1: Indent: expected an indented line"},
+		{SequenceOf(Str("if x:"), Indent), "if x:", "1:6: Indent: expected an indented line, got Unexpected end of input"},
+		{SequenceOf(Str("if x:"), Indent), "if x: b", "1:7: Indent: expected end of line"},
+		{SequenceOf(Str("if x:"), Indent, name, SameIndent), "if x:\n    b\n      c", "3:7: SameIndent: unexpected indentation"},
+		{SequenceOf(Str("if x:"), Indent, name, SameIndent), "if x:\n    b\nc", "3:1: SameIndent: unexpected unindent"},
+		{SequenceOf(Str("if x:"), Indent, name, SameIndent), "if x:\n    b\n\tc", "3:1: SameIndent: inconsistent use of tabs and spaces in indentation"},
+		{SequenceOf(Str("if x:"), Indent, name, Dedent), "if x:\n    b\n  c", "3:3: Dedent: unindent does not match any outer indentation level"},
+		{SequenceOf(Str("if x:"), Indent, name, Dedent), "if x:\n    b\n    c", "3:5: Dedent: expected unindent"},
+	}
+	for _, tc := range testCases {
+		newState := tc.parser.Parse(&tc.input)
+		require.True(t, newState.IsError, tc.input)
+		require.Contains(t, newState.Err.Error(), tc.message, tc.input)
+	}
+
+	input := "a"
+	require.EqualError(t, Dedent.Parse(&input).Err, "1:1: Dedent: there is no indentation level to close")
+}
+
+func TestLineFold(t *testing.T) {
+	word := CondMin(IsAsciiLetter, 1)
+	fold := LineFold(func(space *Parser) *Parser {
+		return SequenceOf(word, ZeroOrMore(SequenceOf(space, word).Map(func(result Result) Result {
+			return result.([]Result)[1]
+		})), space).Map(func(result Result) Result {
+			return append([]Result{result.([]Result)[0]}, result.([]Result)[1].([]Result)...)
+		})
+	})
+	lines := SequenceOf(fold, ZeroOrMore(SequenceOf(Newline, fold).Map(func(result Result) Result {
+		return result.([]Result)[1]
+	})))
+
+	input := "one two\n   three\n\n  four\nfive six"
+	newState := lines.Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{
+		[]Result{"one", "two", "three", "four"},
+		[]Result{[]Result{"five", "six"}},
+	}, newState.Results)
+	require.Equal(t, 0, newState.IndentationDepth())
+	require.True(t, newState.AtTheEnd())
+
+	input = "  one\n\ttwo"
+	newState = SequenceOf(Str("  "), lines).Parse(&input)
+	require.True(t, newState.IsError)
+	require.Contains(t, newState.Err.Error(), "2:1: LineFold: inconsistent use of tabs and spaces in indentation")
+}
+
+func TestIndentationOfNewState(t *testing.T) {
+	input := "x"
+	state := NewParserState(&input, nil, 0, nil)
+	require.Equal(t, "", state.Indentation())
+	require.Equal(t, 0, state.IndentationDepth())
+	state = state.pushIndentation("  ").pushIndentation("    ")
+	require.Equal(t, "    ", state.Indentation())
+	require.Equal(t, 2, state.IndentationDepth())
+	require.Equal(t, "  ", state.popIndentation().Indentation())
+}
@@ -0,0 +1,327 @@
+package parc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// QuotedStringOptions holds the parameters of the quoted string literal parsers.
+// A literal without any escape sequences enabled is a raw literal, where the backslash is an ordinary character.
+type QuotedStringOptions struct {
+	// Quote is the character, that opens and closes the literal
+	Quote rune
+	// Escapes maps the character following the backslash to the decoded text in case of the single character escapes, e.g. 'n' to "\n"
+	Escapes map[rune]string
+	// HexEscapes enables the `\xHH` escape sequences
+	HexEscapes bool
+	// UnicodeEscapes enables the `\uXXXX` escape sequences
+	UnicodeEscapes bool
+	// LongUnicodeEscapes enables the `\UXXXXXXXX` escape sequences
+	LongUnicodeEscapes bool
+	// OctalEscapes enables the octal escape sequences of at most three digits, e.g. `\101` or `\0`
+	OctalEscapes bool
+	// MinOctalDigits is the minimum number of digits of the octal escape sequences. Go requires 3, C requires 1.
+	MinOctalDigits int
+	// ByteEscapes makes the hexadecimal and octal escape sequences to represent bytes instead of Unicode code points, like in Go and C
+	ByteEscapes bool
+	// SurrogatePairs makes a pair of `\uXXXX` escaped UTF-16 surrogates to be decoded into a single rune, like in JSON.
+	// The lone surrogates are replaced by the U+FFFD replacement character.
+	// The escaped surrogates are invalid if it is false.
+	SurrogatePairs bool
+	// Multiline allows line breaks in the literal
+	Multiline bool
+	// DisallowControlChars forbids the unescaped control characters (U+0000..U+001F) in the literal, except the allowed line breaks
+	DisallowControlChars bool
+	// DiscardCarriageReturns removes the carriage return characters from the literal, like in Go raw strings
+	DiscardCarriageReturns bool
+}
+
+// hasEscapes tests if there is any kind of escape sequence enabled
+func (o QuotedStringOptions) hasEscapes() bool {
+	return o.Escapes != nil || o.HexEscapes || o.UnicodeEscapes || o.LongUnicodeEscapes || o.OctalEscapes
+}
+
+var (
+	// GoStringOptions are the options of Go interpreted string literals
+	GoStringOptions = QuotedStringOptions{
+		Quote:              '"',
+		Escapes:            map[rune]string{'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v", '\\': `\`, '"': `"`},
+		HexEscapes:         true,
+		UnicodeEscapes:     true,
+		LongUnicodeEscapes: true,
+		OctalEscapes:       true,
+		MinOctalDigits:     3,
+		ByteEscapes:        true,
+	}
+
+	// GoRawStringOptions are the options of Go raw string literals
+	GoRawStringOptions = QuotedStringOptions{Quote: '`', Multiline: true, DiscardCarriageReturns: true}
+
+	// JSONStringOptions are the options of JSON string literals
+	JSONStringOptions = QuotedStringOptions{
+		Quote:                '"',
+		Escapes:              map[rune]string{'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", '\\': `\`, '/': "/", '"': `"`},
+		UnicodeEscapes:       true,
+		SurrogatePairs:       true,
+		DisallowControlChars: true,
+	}
+
+	// ShellSingleQuotedOptions are the options of the single-quoted strings of POSIX shells, that have no escape sequences
+	ShellSingleQuotedOptions = QuotedStringOptions{Quote: '\'', Multiline: true}
+
+	// CCharOptions are the options of C character constants
+	CCharOptions = QuotedStringOptions{
+		Quote:          '\'',
+		Escapes:        map[rune]string{'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v", '\\': `\`, '\'': "'", '"': `"`, '?': "?"},
+		HexEscapes:     true,
+		OctalEscapes:   true,
+		MinOctalDigits: 1,
+		ByteEscapes:    true,
+	}
+
+	// GoString is a parser of a Go interpreted string literal, e.g. `"Hello\tWorld\n"`. It returns with the decoded string.
+	GoString = QuotedString(GoStringOptions).As("GoString")
+
+	// GoRawString is a parser of a Go raw string literal, e.g. "`C:\temp`". It returns with the content of the literal.
+	GoRawString = QuotedString(GoRawStringOptions).As("GoRawString")
+
+	// JSONString is a parser of a JSON string literal, e.g. `"caf\u00e9"`. It returns with the decoded string.
+	JSONString = QuotedString(JSONStringOptions).As("JSONString")
+
+	// ShellSingleQuoted is a parser of a single-quoted shell string, e.g. `'$HOME'`. It returns with the content of the literal.
+	ShellSingleQuoted = QuotedString(ShellSingleQuotedOptions).As("ShellSingleQuoted")
+
+	// CChar is a parser of a C character constant, e.g. `'\n'`. It returns with the rune value of the character.
+	CChar = CharLiteral(CCharOptions).As("CChar")
+)
+
+// QuotedString creates a parser of a quoted string literal defined by the options.
+// The parser returns with the decoded content of the literal as a string.
+// The errors of the escape sequences are reported at the position of the backslash.
+func QuotedString(options QuotedStringOptions) *Parser {
+	var parser *Parser
+	parser = NewParser("QuotedString", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState, text, _ := scanQuoted(parser.Name(), options, parserState)
+		if newState.IsError {
+			return newState
+		}
+		return UpdateParserState(newState, newState.Index, Result(text))
+	})
+	return parser
+}
+
+// CharLiteral creates a parser of a character literal, that is a quoted literal of a single character defined by the options.
+// The parser returns with the rune value of the character. The byte escapes result in the rune of the same value.
+func CharLiteral(options QuotedStringOptions) *Parser {
+	var parser *Parser
+	parser = NewParser("CharLiteral", func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState, text, chars := scanQuoted(parser.Name(), options, parserState)
+		if newState.IsError {
+			return newState
+		}
+		switch {
+		case chars == 0:
+			return UpdateParserError(parserState, fmt.Errorf("%s: empty character literal", parser.Name()))
+		case chars > 1:
+			return UpdateParserError(parserState, fmt.Errorf("%s: more than one character in character literal", parser.Name()))
+		}
+		r, size := utf8.DecodeRuneInString(text)
+		if r == utf8.RuneError && size <= 1 {
+			r = rune(text[0])
+		}
+		return UpdateParserState(newState, newState.Index, Result(r))
+	})
+	return parser
+}
+
+// scanQuoted parses a quoted literal, and returns with the new state, the decoded text and the number of characters of the literal.
+// In partial mode it needs more input, if the input ends inside of the literal.
+func scanQuoted(name string, options QuotedStringOptions, parserState ParserState) (ParserState, string, int) {
+	quote := string(options.Quote)
+	if !strings.HasPrefix(parserState.Remaining(), quote) {
+		if parserState.partial && parserState.AtTheEnd() {
+			return UpdateParserIncomplete(parserState, name, len(quote)), "", 0
+		}
+		if parserState.AtTheEnd() {
+			return UpdateParserError(parserState, fmt.Errorf("%s: got Unexpected end of input", name)), "", 0
+		}
+		return UpdateParserError(parserState, fmt.Errorf("%s: expected opening quote %s", name, quote)), "", 0
+	}
+
+	var sb strings.Builder
+	chars := 0
+	nextState := parserState.Consume(len(quote))
+	for {
+		remaining := nextState.Remaining()
+		r, size := utf8.DecodeRuneInString(remaining)
+		switch {
+		case parserState.partial && !utf8.FullRuneInString(remaining):
+			return UpdateParserIncomplete(parserState, name, 1), "", 0
+		case size == 0:
+			return UpdateParserError(parserState, fmt.Errorf("%s: unterminated literal", name)), "", 0
+		case r == options.Quote:
+			nextState = nextState.Consume(size)
+			return nextState, sb.String(), chars
+		case r == '\n' && !options.Multiline:
+			return UpdateParserError(nextState, fmt.Errorf("%s: newline in literal", name)), "", 0
+		case r == '\r' && options.DiscardCarriageReturns:
+			nextState = nextState.Consume(size)
+		case r < 0x20 && options.DisallowControlChars && !(r == '\n' && options.Multiline):
+			return UpdateParserError(nextState, fmt.Errorf("%s: invalid control character %U in literal", name, r)), "", 0
+		case r == '\\' && options.hasEscapes():
+			escapeState, text, err := decodeEscape(options, nextState)
+			if err == errUnterminatedEscape && parserState.partial {
+				return UpdateParserIncomplete(parserState, name, 1), "", 0
+			}
+			if err == errUnterminatedEscape {
+				return UpdateParserError(parserState, fmt.Errorf("%s: unterminated literal", name)), "", 0
+			}
+			if err != nil {
+				return UpdateParserError(nextState, fmt.Errorf("%s: %w", name, err)), "", 0
+			}
+			sb.WriteString(text)
+			chars++
+			nextState = escapeState
+		default:
+			sb.WriteString(remaining[:size])
+			chars++
+			nextState = nextState.Consume(size)
+		}
+	}
+}
+
+// errUnterminatedEscape is the error of a backslash at the end of the input,
+// or in partial mode, of an escape sequence, that may be continued by more input
+var errUnterminatedEscape = errors.New("unterminated escape sequence")
+
+// decodeEscape decodes the escape sequence starting at the backslash of the parser state.
+// It returns with the state after the escape sequence and the decoded text.
+// In partial mode it returns with errUnterminatedEscape, if the input ends inside of the escape sequence.
+func decodeEscape(options QuotedStringOptions, parserState ParserState) (ParserState, string, error) {
+	if len(parserState.Remaining()) < 2 {
+		return parserState, "", errUnterminatedEscape
+	}
+	r, nextState := parserState.Consume(1).NextRune()
+	if text, ok := options.Escapes[r]; ok {
+		return nextState, text, nil
+	}
+
+	switch {
+	case r == 'x' && options.HexEscapes:
+		code, ok := hexCode(nextState.Remaining(), 2)
+		if !ok && parserState.partial && isHexPrefix(nextState.Remaining(), 2) {
+			return nextState, "", errUnterminatedEscape
+		}
+		if !ok {
+			return nextState, "", fmt.Errorf(`invalid escape sequence \x: expected 2 hexadecimal digits`)
+		}
+		nextState = nextState.Consume(2)
+		if options.ByteEscapes {
+			return nextState, string([]byte{byte(code)}), nil
+		}
+		return nextState, string(rune(code)), nil
+
+	case r == 'u' && options.UnicodeEscapes:
+		code, ok := hexCode(nextState.Remaining(), 4)
+		if !ok && parserState.partial && isHexPrefix(nextState.Remaining(), 4) {
+			return nextState, "", errUnterminatedEscape
+		}
+		if !ok {
+			return nextState, "", fmt.Errorf(`invalid escape sequence \u: expected 4 hexadecimal digits`)
+		}
+		nextState = nextState.Consume(4)
+		if !utf16.IsSurrogate(rune(code)) {
+			return nextState, string(rune(code)), nil
+		}
+		if !options.SurrogatePairs {
+			return nextState, "", fmt.Errorf(`escape sequence \u%04X is invalid Unicode code point`, code)
+		}
+		// The high surrogate is combined with the escaped low surrogate right after it
+		rest := nextState.Remaining()
+		if parserState.partial && len(rest) < 6 && strings.HasPrefix(`\u`, rest[:min(2, len(rest))]) && isHexPrefix(rest[min(2, len(rest)):], 4) {
+			return nextState, "", errUnterminatedEscape
+		}
+		if strings.HasPrefix(rest, `\u`) {
+			if low, ok := hexCode(rest[2:], 4); ok {
+				if combined := utf16.DecodeRune(rune(code), rune(low)); combined != utf8.RuneError {
+					return nextState.Consume(6), string(combined), nil
+				}
+			}
+		}
+		return nextState, string(utf8.RuneError), nil
+
+	case r == 'U' && options.LongUnicodeEscapes:
+		code, ok := hexCode(nextState.Remaining(), 8)
+		if !ok && parserState.partial && isHexPrefix(nextState.Remaining(), 8) {
+			return nextState, "", errUnterminatedEscape
+		}
+		if !ok {
+			return nextState, "", fmt.Errorf(`invalid escape sequence \U: expected 8 hexadecimal digits`)
+		}
+		if !utf8.ValidRune(rune(code)) {
+			return nextState, "", fmt.Errorf(`escape sequence \U%08X is invalid Unicode code point`, code)
+		}
+		return nextState.Consume(8), string(rune(code)), nil
+
+	case IsOctalDigit(r) && options.OctalEscapes:
+		digits := nextState.Remaining()
+		length := 0
+		for length < 2 && length < len(digits) && IsOctalDigit(rune(digits[length])) {
+			length++
+		}
+		if parserState.partial && length < 2 && length == len(digits) {
+			return nextState, "", errUnterminatedEscape
+		}
+		if length+1 < options.MinOctalDigits {
+			return nextState, "", fmt.Errorf(`invalid escape sequence \%c: expected %d octal digits`, r, options.MinOctalDigits)
+		}
+		code, _ := strconv.ParseUint(string(r)+digits[:length], 8, 32)
+		if code > 255 {
+			return nextState, "", fmt.Errorf(`octal escape value %d > 255`, code)
+		}
+		nextState = nextState.Consume(length)
+		if options.ByteEscapes {
+			return nextState, string([]byte{byte(code)}), nil
+		}
+		return nextState, string(rune(code)), nil
+	}
+	return nextState, "", fmt.Errorf(`invalid escape sequence \%c`, r)
+}
+
+// isHexPrefix tests if the text is shorter than the number of digits, and it holds only hexadecimal digits,
+// so it may be continued to a hexadecimal code
+func isHexPrefix(text string, digits int) bool {
+	if len(text) >= digits {
+		return false
+	}
+	for _, r := range text {
+		if !IsHexadecimalDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hexCode decodes the first digits number of hexadecimal digits of the text
+func hexCode(text string, digits int) (uint64, bool) {
+	if len(text) < digits {
+		return 0, false
+	}
+	for _, r := range text[:digits] {
+		if !IsHexadecimalDigit(r) {
+			return 0, false
+		}
+	}
+	code, err := strconv.ParseUint(text[:digits], 16, 32)
+	return code, err == nil
+}
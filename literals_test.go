@@ -0,0 +1,106 @@
+package parc
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGoString(t *testing.T) {
+	testCases := []TestCase{
+		{Input: `""`, ExpectedResult: ""},
+		{Input: `"Hello\tWorld\n"`, ExpectedResult: "Hello\tWorld\n"},
+		{Input: `"\a\b\f\r\v\\\""`, ExpectedResult: "\a\b\f\r\v\\\""},
+		{Input: `"\x41\101\u00e9\U0001F600"`, ExpectedResult: "AAé😀"},
+		{Input: `"\xff\377"`, ExpectedResult: "\xff\377"},
+		{Input: `"árvíztűrő"`, ExpectedResult: "árvíztűrő"},
+	}
+	for _, tc := range testCases {
+		newState := GoString.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+		require.Equal(t, len(tc.Input), newState.Index, tc.Input)
+	}
+
+	input := `"a" + "b"`
+	newState := GoString.Parse(&input)
+	require.Equal(t, "a", newState.Results)
+	require.Equal(t, 3, newState.Index)
+}
+
+func TestGoRawString(t *testing.T) {
+	input := "`C:\\temp\\n\r\nline 2`"
+	newState := GoRawString.Parse(&input)
+	require.False(t, newState.IsError)
+	require.Equal(t, "C:\\temp\\n\nline 2", newState.Results)
+}
+
+func TestJSONString(t *testing.T) {
+	testCases := []TestCase{
+		{Input: `"caf\u00e9 \/ \"x\""`, ExpectedResult: `café / "x"`},
+		{Input: `"\ud83d\ude00"`, ExpectedResult: "😀"},
+		{Input: `"\ud83d x"`, ExpectedResult: "\uFFFD x"},
+		{Input: `"\ude00\ud83d"`, ExpectedResult: "\uFFFD\uFFFD"},
+	}
+	for _, tc := range testCases {
+		newState := JSONString.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+	}
+}
+
+func TestShellSingleQuoted(t *testing.T) {
+	input := `'$HOME\n` + "\n" + `"x"'`
+	newState := ShellSingleQuoted.Parse(&input)
+	require.False(t, newState.IsError)
+	require.Equal(t, `$HOME\n`+"\n"+`"x"`, newState.Results)
+}
+
+func TestCChar(t *testing.T) {
+	testCases := []TestCase{
+		{Input: `'a'`, ExpectedResult: 'a'},
+		{Input: `'\n'`, ExpectedResult: '\n'},
+		{Input: `'\''`, ExpectedResult: '\''},
+		{Input: `'\0'`, ExpectedResult: rune(0)},
+		{Input: `'\101'`, ExpectedResult: 'A'},
+		{Input: `'\x7f'`, ExpectedResult: rune(0x7f)},
+		{Input: `'\xff'`, ExpectedResult: rune(0xff)},
+		{Input: `'ő'`, ExpectedResult: 'ő'},
+	}
+	for _, tc := range testCases {
+		newState := CChar.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+	}
+}
+
+func TestQuotedStringErrors(t *testing.T) {
+	testCases := []struct {
+		parser  *Parser
+		input   string
+		message string
+	}{
+		{GoString, `abc`, "1:1: GoString: expected opening quote \""},
+		{GoString, ``, "1:1: GoString: got Unexpected end of input"},
+		{GoString, `"abc`, "1:1: GoString: unterminated literal"},
+		{GoString, `"abc\`, "1:1: GoString: unterminated literal"},
+		{GoString, "\"ab\ncd\"", "1:4: GoString: newline in literal"},
+		{GoString, `"ab\qcd"`, `1:4: GoString: invalid escape sequence \q`},
+		{GoString, `"ab\'"`, `1:4: GoString: invalid escape sequence \'`},
+		{GoString, `"\x4g"`, `1:2: GoString: invalid escape sequence \x: expected 2 hexadecimal digits`},
+		{GoString, `"\u12"`, `1:2: GoString: invalid escape sequence \u: expected 4 hexadecimal digits`},
+		{GoString, `"\ud800"`, `1:2: GoString: escape sequence \uD800 is invalid Unicode code point`},
+		{GoString, `"\U00110000"`, `1:2: GoString: escape sequence \U00110000 is invalid Unicode code point`},
+		{GoString, `"\12"`, `1:2: GoString: invalid escape sequence \1: expected 3 octal digits`},
+		{GoString, `"\400"`, `1:2: GoString: octal escape value 256 > 255`},
+		{JSONString, "\"a\tb\"", "1:3: JSONString: invalid control character U+0009 in literal"},
+		{JSONString, `"\x41"`, `1:2: JSONString: invalid escape sequence \x`},
+		{CChar, `''`, "1:1: CChar: empty character literal"},
+		{CChar, `'ab'`, "1:1: CChar: more than one character in character literal"},
+		{QuotedString(QuotedStringOptions{Quote: '"'}), `"abc`, "1:1: QuotedString: unterminated literal"},
+	}
+	for _, tc := range testCases {
+		newState := tc.parser.Parse(&tc.input)
+		require.True(t, newState.IsError, tc.input)
+		require.EqualError(t, newState.Err, tc.message, tc.input)
+	}
+}
@@ -1,6 +1,8 @@
 package parc
 
-import ()
+import (
+	"errors"
+)
 
 // Map call the map function to the result and returns with the return value of this function
 func Map(parser *Parser, mapper func(Result) Result) *Parser {
@@ -15,25 +17,65 @@ func Map(parser *Parser, mapper func(Result) Result) *Parser {
 
 		result := mapper(newState.Results)
 
-		return updateParserState(newState, newState.Index, Result(result))
+		return UpdateParserState(newState, newState.Index, Result(result))
 	}
 
-	return NewParser("Map("+parser.Name()+")", parserFun)
+	newParser := NewParser("Map("+parser.Name()+")", parserFun)
+	newParser.spec = ParserSpec{Kind: MapKind, Parsers: []*Parser{parser}}
+	return newParser
 }
 
 // ErrorMap is like Map but it transforms the error value.
 // The function passed to ErrorMap gets an object the current error message (error),
 // the index (index) that parsing stopped at from this parsing session.
+// The errors of the parsers, that need more input in partial mode, are not transformed.
 func (p *Parser) ErrorMap(mapperFn func(ParserState) error) *Parser {
 
 	parserFun := func(parserState ParserState) ParserState {
 		newState := p.ParserFun(parserState)
-		if !newState.IsError {
+		if !newState.IsError || newState.IsIncomplete() {
 			return newState
 		}
 
-		return updateParserError(newState, mapperFn(newState))
+		return UpdateParserError(newState, mapperFn(newState))
 	}
 
-	return NewParser("ErrorMap("+p.Name()+")", parserFun)
+	parser := NewParser("ErrorMap("+p.Name()+")", parserFun)
+	parser.spec = ParserSpec{Kind: ErrorMapKind, Parsers: []*Parser{p}}
+	return parser
+}
+
+// TryMap is like Map, but the mapper function may fail, e.g. if it converts the result into a value out of range.
+// The error of the mapper function is reported at the position where the parser started.
+// The diagnostics of the Diagnose method show it with the range of the input, that was matched by the parser.
+// If the error is a DecodeError of a part of the matched input, it is reported at the position and with the range of that part.
+func (p *Parser) TryMap(mapper func(Result) (Result, error)) *Parser {
+	parserFun := func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := p.ParserFun(parserState)
+		if newState.IsError {
+			return newState
+		}
+
+		result, err := mapper(newState.Results)
+		if err != nil {
+			span := Span{Start: parserState.Index, End: newState.Index}
+			var decodeError *DecodeError
+			if errors.As(err, &decodeError) && decodeError.Span.Start >= span.Start && decodeError.Span.End <= span.End {
+				span = decodeError.Span
+			}
+			if parserState.failures != nil {
+				parserState.failures.fail(span, err)
+			}
+			return UpdateParserError(UpdateParserState(parserState, span.Start, parserState.Results), err)
+		}
+
+		return UpdateParserState(newState, newState.Index, Result(result))
+	}
+
+	parser := NewParser("TryMap("+p.Name()+")", parserFun)
+	parser.spec = ParserSpec{Kind: MapKind, Parsers: []*Parser{p}}
+	return parser
 }
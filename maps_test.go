@@ -87,3 +87,39 @@ func TestParser_ErrorMap(t *testing.T) {
 	require.Equal(t, fmt.Errorf("1:1: %w", expectedError), newState.Err)
 	require.True(t, newState.IsError)
 }
+
+func TestParser_TryMap(t *testing.T) {
+	atoi := func(in Result) (Result, error) {
+		return strconv.Atoi(in.(string))
+	}
+
+	input := "x 42"
+	newState := SequenceOf(Str("x "), Digits.TryMap(atoi)).Parse(&input)
+	require.False(t, newState.IsError)
+	require.Equal(t, []Result{"x ", 42}, newState.Results)
+
+	input = "x 99999999999999999999"
+	newState = SequenceOf(Str("x "), Digits.TryMap(atoi)).Parse(&input)
+	require.True(t, newState.IsError)
+	require.Contains(t, newState.Err.Error(), "1:3: strconv.Atoi: parsing \"99999999999999999999\": value out of range")
+	require.Equal(t, MapKind, Digits.TryMap(atoi).Spec().Kind)
+
+	// The decoding errors are reported at the position of the decoded part of the input
+	type pair struct {
+		Key   string `parc:"key"`
+		Value int8   `parc:"value"`
+	}
+	decodePair := func(in Result) (Result, error) {
+		var p pair
+		err := Decode(in, &p)
+		return p, err
+	}
+	parser := SequenceOf(Capture("key", Letters), Char("="), Capture("value", Digits)).TryMap(decodePair)
+	input = "key=300"
+	newState = parser.Parse(&input)
+	require.True(t, newState.IsError)
+	require.Equal(t, 4, newState.Index)
+	require.Contains(t, newState.Err.Error(), "1:5: Decode: field Value [4:7]:")
+	_, diagnostic := parser.Diagnose(&input)
+	require.Equal(t, Span{Start: 4, End: 7}, diagnostic.Span)
+}
@@ -0,0 +1,129 @@
+package parc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Span is the range of the input matched by a parser, given by byte offsets
+type Span struct {
+	// Start is the offset of the first byte of the range
+	Start int `json:"start"`
+	// End is the offset of the first byte after the range
+	End int `json:"end"`
+}
+
+// Text returns with the part of the input, that is covered by the span
+func (s Span) Text(input string) string {
+	return input[s.Start:s.End]
+}
+
+// Node is a generic node of an abstract syntax tree built by the NodeOf parsers
+type Node struct {
+	// Kind identifies the syntactic category of the node, e.g. `operation` or `number`
+	Kind string `json:"kind"`
+	// Value is the result of the parser of the node, if it has no children
+	Value Result `json:"value,omitempty"`
+	// Children are the nodes of the nested NodeOf parsers in the order of their position
+	Children []*Node `json:"children,omitempty"`
+	// Span is the range of the input matched by the parser of the node
+	Span Span `json:"span"`
+}
+
+// NodeOf returns a parser, that runs the parser and returns with a *Node of the given kind.
+// The children of the node are the nodes found in the results of the parser, including the nested arrays of results.
+// If there is no node in the results, the results become the value of the node.
+// The other results of the parser are dropped, so the significant tokens, e.g. the operators, should be nodes too.
+func NodeOf(kind string, parser *Parser) *Parser {
+	parserFun := func(parserState ParserState) ParserState {
+		if parserState.IsError {
+			return parserState
+		}
+		newState := parser.ParserFun(parserState)
+		if newState.IsError {
+			return newState
+		}
+
+		node := &Node{Kind: kind, Children: collectNodes(newState.Results, nil), Span: Span{Start: parserState.Index, End: newState.Index}}
+		if node.Children == nil {
+			node.Value = newState.Results
+		}
+		return UpdateParserState(newState, newState.Index, Result(node))
+	}
+
+	newParser := NewParser("NodeOf("+kind+")", parserFun)
+	newParser.spec = ParserSpec{Kind: MapKind, Parsers: []*Parser{parser}}
+	return newParser
+}
+
+// collectNodes appends the nodes of the result to the nodes in depth-first order
+func collectNodes(result Result, nodes []*Node) []*Node {
+	switch r := result.(type) {
+	case *Node:
+		nodes = append(nodes, r)
+	case []Result:
+		for _, item := range r {
+			nodes = collectNodes(item, nodes)
+		}
+	}
+	return nodes
+}
+
+// Walk visits the node and its descendants in depth-first pre-order.
+// If the visit function returns false, the children of the node are skipped.
+func (n *Node) Walk(visit func(node *Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(visit)
+	}
+}
+
+// Find returns with the node and its descendants of the given kind in depth-first pre-order
+func (n *Node) Find(kind string) []*Node {
+	var nodes []*Node
+	n.Walk(func(node *Node) bool {
+		if node.Kind == kind {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	return nodes
+}
+
+// First returns with the first of the node and its descendants of the given kind in depth-first pre-order,
+// or nil if there is no such node
+func (n *Node) First(kind string) *Node {
+	var found *Node
+	n.Walk(func(node *Node) bool {
+		if found == nil && node.Kind == kind {
+			found = node
+		}
+		return found == nil
+	})
+	return found
+}
+
+// Pretty returns with the indented text format of the tree, that has a line for each node with its kind, span and value
+func (n *Node) Pretty() string {
+	var sb strings.Builder
+	n.pretty(&sb, 0)
+	return sb.String()
+}
+
+// pretty writes the lines of the node and its descendants at the given depth
+func (n *Node) pretty(sb *strings.Builder, depth int) {
+	fmt.Fprintf(sb, "%s%s [%d:%d]", strings.Repeat("  ", depth), n.Kind, n.Span.Start, n.Span.End)
+	switch value := n.Value.(type) {
+	case nil:
+	case string:
+		fmt.Fprintf(sb, " %q", value)
+	default:
+		fmt.Fprintf(sb, " %v", value)
+	}
+	sb.WriteString("\n")
+	for _, child := range n.Children {
+		child.pretty(sb, depth+1)
+	}
+}
@@ -0,0 +1,112 @@
+package parc
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// prefixExpression returns a parser of prefix arithmetic expressions like `(+ 1 (* 2 3))`, that builds a tree of nodes
+func prefixExpression() *Parser {
+	var expr Parser
+	operator := NodeOf("operator", Choice(Str("+"), Str("-"), Str("*"), Str("/")))
+	operation := NodeOf("operation", SequenceOf(Str("("), operator, Str(" "), &expr, Str(" "), &expr, Str(")")))
+	expr = *Choice(NodeOf("number", Integer), operation)
+	return &expr
+}
+
+// evaluateNode computes the value of the expression tree
+func evaluateNode(node *Node) int {
+	if node.Kind == "number" {
+		return node.Value.(int)
+	}
+	a, b := evaluateNode(node.Children[1]), evaluateNode(node.Children[2])
+	switch node.Children[0].Value {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	}
+	return a / b
+}
+
+func TestNodeOf(t *testing.T) {
+	input := "(+ (* 10 2) 3)"
+	newState := prefixExpression().Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+	root := newState.Results.(*Node)
+	require.Equal(t, "operation", root.Kind)
+	require.Nil(t, root.Value)
+	require.Equal(t, Span{Start: 0, End: 14}, root.Span)
+	require.Equal(t, "(* 10 2)", root.Children[1].Span.Text(input))
+	require.Equal(t, 23, evaluateNode(root))
+
+	require.Equal(t, `operation [0:14]
+  operator [1:2] "+"
+  operation [3:11]
+    operator [4:5] "*"
+    number [6:8] 10
+    number [9:10] 2
+  number [12:13] 3
+`, root.Pretty())
+}
+
+func TestNode_Find(t *testing.T) {
+	input := "(+ (* 10 2) 3)"
+	root := prefixExpression().Parse(&input).Results.(*Node)
+
+	numbers := []Result{}
+	for _, node := range root.Find("number") {
+		numbers = append(numbers, node.Value)
+	}
+	require.Equal(t, []Result{10, 2, 3}, numbers)
+	require.Len(t, root.Find("operation"), 2)
+	require.Nil(t, root.Find("string"))
+
+	require.Equal(t, "*", root.Children[1].First("operator").Value)
+	require.Equal(t, "+", root.First("operator").Value)
+	require.Nil(t, root.First("string"))
+}
+
+func TestNode_Walk(t *testing.T) {
+	input := "(+ (* 10 2) 3)"
+	root := prefixExpression().Parse(&input).Results.(*Node)
+
+	kinds := []string{}
+	root.Walk(func(node *Node) bool {
+		kinds = append(kinds, node.Kind)
+		// The nested operations are skipped
+		return node == root || node.Kind != "operation"
+	})
+	require.Equal(t, []string{"operation", "operator", "operation", "number"}, kinds)
+}
+
+func TestNode_JSON(t *testing.T) {
+	input := "(- 5 3)"
+	root := prefixExpression().Parse(&input).Results.(*Node)
+	data, err := json.Marshal(root)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kind": "operation", "span": {"start": 0, "end": 7}, "children": [
+		{"kind": "operator", "value": "-", "span": {"start": 1, "end": 2}},
+		{"kind": "number", "value": 5, "span": {"start": 3, "end": 4}},
+		{"kind": "number", "value": 3, "span": {"start": 5, "end": 6}}
+	]}`, string(data))
+
+	var decoded Node
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, "operation", decoded.Kind)
+	require.Equal(t, Span{Start: 3, End: 4}, decoded.Children[1].Span)
+	require.Equal(t, float64(5), decoded.Children[1].Value)
+}
+
+func TestNodeOf_Leaf(t *testing.T) {
+	input := "(+ 1 x)"
+	newState := NodeOf("operation", SequenceOf(Str("("), Str("+"))).Parse(&input)
+	require.False(t, newState.IsError)
+	require.Equal(t, &Node{Kind: "operation", Value: []Result{"(", "+"}, Span: Span{Start: 0, End: 2}}, newState.Results)
+
+	newState = prefixExpression().Parse(&input)
+	require.True(t, newState.IsError)
+}
@@ -0,0 +1,204 @@
+package parc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// IntegerLiteral is a parser of an integer literal with optional sign, that may be decimal (`-42`),
+	// hexadecimal (`0x2A`), octal (`0o52`) or binary (`0b101010`). The digits may be separated by `_` characters, e.g. `1_000_000`.
+	// The decimal literals with leading zeros are decimal, e.g. `0755` is 755. It returns with an int64 value.
+	IntegerLiteral = integerLiteral(integerText, "IntegerLiteral", true).PrintWith(printIntegerLiteral("", 10))
+
+	// HexIntegerLiteral is a parser of a hexadecimal integer literal with `0x` or `0X` prefix, e.g. `0xFF_FF`. It returns with an int64 value.
+	HexIntegerLiteral = integerLiteral(prefixedDigits("0x", "0X", IsHexadecimalDigit), "HexIntegerLiteral", true).PrintWith(printIntegerLiteral("0x", 16))
+
+	// OctalIntegerLiteral is a parser of an octal integer literal with `0o` or `0O` prefix, e.g. `0o755`. It returns with an int64 value.
+	OctalIntegerLiteral = integerLiteral(prefixedDigits("0o", "0O", IsOctalDigit), "OctalIntegerLiteral", true).PrintWith(printIntegerLiteral("0o", 8))
+
+	// BinaryIntegerLiteral is a parser of a binary integer literal with `0b` or `0B` prefix, e.g. `0b1010_0101`. It returns with an int64 value.
+	BinaryIntegerLiteral = integerLiteral(prefixedDigits("0b", "0B", IsBinaryDigit), "BinaryIntegerLiteral", true).PrintWith(printIntegerLiteral("0b", 2))
+
+	// BigIntegerLiteral is a parser of the same integer literals as IntegerLiteral, without range limit. It returns with a *big.Int value.
+	BigIntegerLiteral = integerLiteral(integerText, "BigIntegerLiteral", false).PrintWith(printIntegerLiteral("", 10))
+
+	// FloatLiteral is a parser of a floating point literal with optional sign, where the fraction and the exponent are optional,
+	// e.g. `42`, `3.14`, `.5`, `1.`, `1e10` or `6.022_140e23`. It also accepts the hexadecimal floats with mandatory
+	// binary exponent (`0x1.8p3`) and the `Inf`, `Infinity` and `NaN` values in lower or title case. It returns with a float64 value.
+	FloatLiteral = floatText.TryMap(func(result Result) (Result, error) {
+		value, err := strconv.ParseFloat(result.(string), 64)
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("FloatLiteral: %s is out of range", result)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("FloatLiteral: invalid digit separator in %s", result)
+		}
+		return value, nil
+	}).As("FloatLiteral").PrintWith(printFloatLiteral)
+)
+
+// BigFloatLiteral returns a parser of the same floating point literals as FloatLiteral except `NaN`,
+// that returns with a *big.Float value of the given precision in bits. Zero precision means 64 bits.
+func BigFloatLiteral(prec uint) *Parser {
+	return floatText.TryMap(func(result Result) (Result, error) {
+		text := result.(string)
+		if strings.EqualFold(text, "NaN") {
+			return nil, fmt.Errorf("BigFloatLiteral: NaN is not supported")
+		}
+		if _, err := strconv.ParseFloat(text, 64); err != nil && !errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("BigFloatLiteral: invalid digit separator in %s", text)
+		}
+		value, _, err := big.ParseFloat(strings.ReplaceAll(text, "_", ""), 0, prec, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("BigFloatLiteral: %w", err)
+		}
+		return value, nil
+	}).As("BigFloatLiteral").PrintWith(printFloatLiteral)
+}
+
+var (
+	// numberSign is an optional sign, that results in nil if it is missing
+	numberSign = Optional(Choice(Char("+"), Char("-")))
+
+	// decimalDigits matches decimal digits separated by optional `_` characters
+	decimalDigits = SequenceOf(Digit, CondMin(isDigitOrSeparator(IsDigit), 0)).Map(JoinStrResults)
+
+	// integerText matches the text of the integer literals
+	integerText = SequenceOf(
+		numberSign,
+		Choice(
+			prefixedDigits("0x", "0X", IsHexadecimalDigit),
+			prefixedDigits("0o", "0O", IsOctalDigit),
+			prefixedDigits("0b", "0B", IsBinaryDigit),
+			decimalDigits,
+		),
+	).Map(JoinStrResults)
+
+	// floatText matches the text of the floating point literals
+	floatText = buildFloatText()
+)
+
+// isDigitOrSeparator returns a condition, that matches the digits and the `_` digit separator
+func isDigitOrSeparator(isDigit func(rune) bool) func(rune) bool {
+	return func(r rune) bool { return isDigit(r) || r == '_' }
+}
+
+// prefixedDigits returns a parser of a base prefix followed by digits, that may be separated by `_` characters
+func prefixedDigits(lowerPrefix, upperPrefix string, isDigit func(rune) bool) *Parser {
+	return SequenceOf(Choice(Str(lowerPrefix), Str(upperPrefix)), CondMin(isDigitOrSeparator(isDigit), 1)).Map(JoinStrResults)
+}
+
+// buildFloatText creates the parser of the text of the floating point literals
+func buildFloatText() *Parser {
+	exponent := func(lower, upper string) *Parser {
+		return SequenceOf(Choice(Char(lower), Char(upper)), numberSign, decimalDigits)
+	}
+	hexDigits := SequenceOf(Cond(IsHexadecimalDigit), CondMin(isDigitOrSeparator(IsHexadecimalDigit), 0))
+	hexFloat := SequenceOf(
+		Choice(Str("0x"), Str("0X")),
+		Choice(
+			SequenceOf(hexDigits, Optional(SequenceOf(Char("."), Optional(hexDigits)))),
+			SequenceOf(Char("."), hexDigits),
+		),
+		exponent("p", "P"),
+	)
+	decimalFloat := Choice(
+		SequenceOf(decimalDigits, Optional(SequenceOf(Char("."), Optional(decimalDigits))), Optional(exponent("e", "E"))),
+		SequenceOf(Char("."), decimalDigits, Optional(exponent("e", "E"))),
+	)
+	special := Choice(Str("Infinity"), Str("infinity"), Str("Inf"), Str("inf"), Str("NaN"), Str("nan"))
+	return SequenceOf(numberSign, Choice(hexFloat, decimalFloat, special)).Map(JoinStrResults)
+}
+
+// integerLiteral creates an integer literal parser from the parser of its text.
+// It results in an int64 value if limited is true, otherwise in a *big.Int value.
+func integerLiteral(text *Parser, name string, limited bool) *Parser {
+	return text.TryMap(func(result Result) (Result, error) {
+		value, err := parseIntegerText(result.(string))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !limited {
+			return value, nil
+		}
+		if !value.IsInt64() {
+			return nil, fmt.Errorf("%s: %s is out of range", name, result)
+		}
+		return value.Int64(), nil
+	}).As(name)
+}
+
+// parseIntegerText converts the text of an integer literal into a big.Int value, and checks its digit separators.
+// The `_` separators must stand between two digits, or between the base prefix and a digit.
+func parseIntegerText(text string) (*big.Int, error) {
+	body := strings.TrimLeft(text, "+-")
+	base, prefixLength := 10, 0
+	if len(body) > 1 && body[0] == '0' {
+		switch body[1] {
+		case 'x', 'X':
+			base, prefixLength = 16, 2
+		case 'o', 'O':
+			base, prefixLength = 8, 2
+		case 'b', 'B':
+			base, prefixLength = 2, 2
+		}
+	}
+	digits := body[prefixLength:]
+	for i := 0; i < len(digits); i++ {
+		if digits[i] != '_' {
+			continue
+		}
+		if i+1 == len(digits) || digits[i+1] == '_' || i == 0 && prefixLength == 0 {
+			return nil, fmt.Errorf("invalid digit separator in %s", text)
+		}
+	}
+	value, ok := new(big.Int).SetString(strings.ReplaceAll(digits, "_", ""), base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %s", text)
+	}
+	if strings.HasPrefix(text, "-") {
+		value.Neg(value)
+	}
+	return value, nil
+}
+
+// printIntegerLiteral returns a printer function of the int64 and *big.Int results of the integer literals of the given base and prefix
+func printIntegerLiteral(prefix string, base int) PrintFun {
+	return func(printer *Printer, result Result) error {
+		var value big.Int
+		switch r := result.(type) {
+		case int64:
+			value.SetInt64(r)
+		case *big.Int:
+			value.Set(r)
+		default:
+			return fmt.Errorf("Print: expected integer, got %T", result)
+		}
+		if value.Sign() < 0 {
+			if prefix != "" {
+				return fmt.Errorf("Print: negative value %s can not be printed with %s prefix", value.String(), prefix)
+			}
+			printer.Write("-")
+			value.Neg(&value)
+		}
+		printer.Write(prefix + value.Text(base))
+		return nil
+	}
+}
+
+// printFloatLiteral is the printer function of the float64 and *big.Float results of the floating point literals
+func printFloatLiteral(printer *Printer, result Result) error {
+	switch r := result.(type) {
+	case float64:
+		printer.Write(strconv.FormatFloat(r, 'g', -1, 64))
+	case *big.Float:
+		printer.Write(r.Text('g', -1))
+	default:
+		return fmt.Errorf("Print: expected floating point number, got %T", result)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package parc
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestIntegerLiteral(t *testing.T) {
+	testCases := []TestCase{
+		{Input: "0", ExpectedResult: int64(0)},
+		{Input: "42", ExpectedResult: int64(42)},
+		{Input: "-42", ExpectedResult: int64(-42)},
+		{Input: "+42", ExpectedResult: int64(42)},
+		{Input: "0755", ExpectedResult: int64(755)},
+		{Input: "1_000_000", ExpectedResult: int64(1000000)},
+		{Input: "0x2A", ExpectedResult: int64(42)},
+		{Input: "0XdeadBEEF", ExpectedResult: int64(0xdeadbeef)},
+		{Input: "0x_FF_FF", ExpectedResult: int64(0xffff)},
+		{Input: "0o52", ExpectedResult: int64(42)},
+		{Input: "-0O777", ExpectedResult: int64(-511)},
+		{Input: "0b1010_0101", ExpectedResult: int64(0xa5)},
+		{Input: "9223372036854775807", ExpectedResult: int64(math.MaxInt64)},
+		{Input: "-9223372036854775808", ExpectedResult: int64(math.MinInt64)},
+	}
+	for _, tc := range testCases {
+		newState := IntegerLiteral.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+		require.Equal(t, len(tc.Input), newState.Index, tc.Input)
+	}
+
+	input := "0b102"
+	newState := IntegerLiteral.Parse(&input)
+	require.Equal(t, int64(2), newState.Results)
+	require.Equal(t, 4, newState.Index)
+}
+
+func TestPrefixedIntegerLiterals(t *testing.T) {
+	input := "0xff"
+	require.Equal(t, Result(int64(255)), HexIntegerLiteral.Parse(&input).Results)
+	input = "0o17"
+	require.Equal(t, Result(int64(15)), OctalIntegerLiteral.Parse(&input).Results)
+	input = "0b11"
+	require.Equal(t, Result(int64(3)), BinaryIntegerLiteral.Parse(&input).Results)
+
+	for _, parser := range []*Parser{HexIntegerLiteral, OctalIntegerLiteral, BinaryIntegerLiteral} {
+		input := "42"
+		require.True(t, parser.Parse(&input).IsError, parser.Name())
+	}
+}
+
+func TestBigIntegerLiteral(t *testing.T) {
+	input := "-123_456_789_012_345_678_901_234_567_890"
+	newState := BigIntegerLiteral.Parse(&input)
+	require.False(t, newState.IsError)
+	expected, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	require.Equal(t, 0, expected.Cmp(newState.Results.(*big.Int)))
+
+	input = "0xFFFFFFFFFFFFFFFFFFFF"
+	newState = BigIntegerLiteral.Parse(&input)
+	require.False(t, newState.IsError)
+	require.Equal(t, "ffffffffffffffffffff", newState.Results.(*big.Int).Text(16))
+}
+
+func TestIntegerLiteralErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		message string
+	}{
+		{"x = 9223372036854775808", "1:5: IntegerLiteral: 9223372036854775808 is out of range"},
+		{"x = -0x8000000000000001", "1:5: IntegerLiteral: -0x8000000000000001 is out of range"},
+		{"x = 1__000", "1:5: IntegerLiteral: invalid digit separator in 1__000"},
+		{"x = 1000_", "1:5: IntegerLiteral: invalid digit separator in 1000_"},
+		{"x = 0x_", "1:5: IntegerLiteral: invalid digit separator in 0x_"},
+	}
+	for _, tc := range testCases {
+		newState := SequenceOf(Str("x = "), IntegerLiteral).Parse(&tc.input)
+		require.True(t, newState.IsError, tc.input)
+		require.Contains(t, newState.Err.Error(), tc.message, tc.input)
+	}
+}
+
+func TestFloatLiteral(t *testing.T) {
+	testCases := []TestCase{
+		{Input: "42", ExpectedResult: 42.0},
+		{Input: "3.14", ExpectedResult: 3.14},
+		{Input: "-3.14", ExpectedResult: -3.14},
+		{Input: ".5", ExpectedResult: 0.5},
+		{Input: "1.", ExpectedResult: 1.0},
+		{Input: "1e10", ExpectedResult: 1e10},
+		{Input: "+2.5E-3", ExpectedResult: 2.5e-3},
+		{Input: "6.022_140e23", ExpectedResult: 6.022140e23},
+		{Input: "0x1.8p3", ExpectedResult: 12.0},
+		{Input: "0X.8P-1", ExpectedResult: 0.25},
+		{Input: "0x1_0p0", ExpectedResult: 16.0},
+		{Input: "Inf", ExpectedResult: math.Inf(1)},
+		{Input: "-Infinity", ExpectedResult: math.Inf(-1)},
+		{Input: "4e-400", ExpectedResult: 0.0},
+	}
+	for _, tc := range testCases {
+		newState := FloatLiteral.Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+		require.Equal(t, len(tc.Input), newState.Index, tc.Input)
+	}
+
+	input := "NaN"
+	newState := FloatLiteral.Parse(&input)
+	require.False(t, newState.IsError)
+	require.True(t, math.IsNaN(newState.Results.(float64)))
+
+	input = "1e5x"
+	newState = FloatLiteral.Parse(&input)
+	require.Equal(t, 1e5, newState.Results)
+	require.Equal(t, 3, newState.Index)
+}
+
+func TestFloatLiteralErrors(t *testing.T) {
+	testCases := []struct {
+		input   string
+		message string
+	}{
+		{"x = 1e999", "1:5: FloatLiteral: 1e999 is out of range"},
+		{"x = -0x1p2000", "1:5: FloatLiteral: -0x1p2000 is out of range"},
+		{"x = 1__0.5", "1:5: FloatLiteral: invalid digit separator in 1__0.5"},
+	}
+	for _, tc := range testCases {
+		newState := SequenceOf(Str("x = "), FloatLiteral).Parse(&tc.input)
+		require.True(t, newState.IsError, tc.input)
+		require.Contains(t, newState.Err.Error(), tc.message, tc.input)
+	}
+
+	input := "abc"
+	require.True(t, FloatLiteral.Parse(&input).IsError)
+}
+
+func TestBigFloatLiteral(t *testing.T) {
+	input := "1e999"
+	newState := BigFloatLiteral(0).Parse(&input)
+	require.False(t, newState.IsError)
+	expected, _, _ := big.ParseFloat("1e999", 10, 64, big.ToNearestEven)
+	require.Equal(t, 0, expected.Cmp(newState.Results.(*big.Float)))
+
+	input = "0.1"
+	newState = BigFloatLiteral(200).Parse(&input)
+	require.False(t, newState.IsError)
+	require.Equal(t, uint(200), newState.Results.(*big.Float).Prec())
+	require.Equal(t, "0.1000000000000000000000000000000000000000", newState.Results.(*big.Float).Text('f', 40))
+
+	input = "-0x1.8p1_0"
+	newState = BigFloatLiteral(0).Parse(&input)
+	require.False(t, newState.IsError)
+	value, _ := newState.Results.(*big.Float).Float64()
+	require.Equal(t, -1536.0, value)
+
+	input = "NaN"
+	newState = BigFloatLiteral(0).Parse(&input)
+	require.EqualError(t, newState.Err, "1:1: BigFloatLiteral: NaN is not supported")
+}
@@ -0,0 +1,230 @@
+// Package parctest provides test helpers for the parsers built with the parc package.
+//
+// The golden file tests run a parser over a directory of input files,
+// and compare the serialized results and errors with the content of the golden files stored next to the inputs.
+// Run the tests with the PARCTEST_UPDATE environment variable set to regenerate the golden files:
+//
+//	PARCTEST_UPDATE=1 go test .
+//
+// so the changes of the grammar show up as reviewable diffs of the golden files.
+// The package does not register command line flags, but if the test binary defines a boolean `-update` flag,
+// it is also honored:
+//
+//	go test . -update
+package parctest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/tombenke/parc"
+)
+
+// UpdateEnv is the environment variable, that makes the golden file tests regenerate the golden files instead of comparing them
+const UpdateEnv = "PARCTEST_UPDATE"
+
+// updating tests if the golden files should be regenerated, according to the Update field of the test,
+// the UpdateEnv environment variable, or the `-update` flag, if the test binary defines one
+func (gt GoldenTest) updating() bool {
+	if gt.Update {
+		return true
+	}
+	if update, err := strconv.ParseBool(os.Getenv(UpdateEnv)); err == nil && update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			update, ok := getter.Get().(bool)
+			return ok && update
+		}
+	}
+	return false
+}
+
+// GoldenTest describes a golden file test of a parser
+type GoldenTest struct {
+	// Parser is the parser to run over the input files
+	Parser *parc.Parser
+	// Dir is the directory of the input and golden files
+	Dir string
+	// InputExt is the extension of the input files. Its default value is ".input".
+	InputExt string
+	// GoldenExt is the extension of the golden files. Its default value is ".golden".
+	GoldenExt string
+	// Serialize converts the final state of the parser to the content of the golden file.
+	// Its default value is the Serialize function of this package.
+	Serialize func(parc.ParserState) string
+	// Update makes the test regenerate the golden files instead of comparing them
+	Update bool
+}
+
+// RunGoldenFiles runs the parser over every `*.input` file of the directory,
+// and compares the serialized results with the corresponding `*.golden` file.
+func RunGoldenFiles(t *testing.T, parser *parc.Parser, dir string) {
+	t.Helper()
+	GoldenTest{Parser: parser, Dir: dir}.Run(t)
+}
+
+// Run executes the golden file test. Every input file is run as a separate sub-test named after the input file.
+func (gt GoldenTest) Run(t *testing.T) {
+	t.Helper()
+	inputExt := gt.InputExt
+	if inputExt == "" {
+		inputExt = ".input"
+	}
+	goldenExt := gt.GoldenExt
+	if goldenExt == "" {
+		goldenExt = ".golden"
+	}
+	serialize := gt.Serialize
+	if serialize == nil {
+		serialize = Serialize
+	}
+
+	update := gt.updating()
+
+	inputFiles, err := filepath.Glob(filepath.Join(gt.Dir, "*"+inputExt))
+	if err != nil {
+		t.Fatalf("parctest: %v", err)
+	}
+	if len(inputFiles) == 0 {
+		t.Fatalf("parctest: no input files found with %s extension in %s", inputExt, gt.Dir)
+	}
+
+	for _, inputFile := range inputFiles {
+		name := strings.TrimSuffix(filepath.Base(inputFile), inputExt)
+		goldenFile := strings.TrimSuffix(inputFile, inputExt) + goldenExt
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(inputFile)
+			if err != nil {
+				t.Fatalf("parctest: %v", err)
+			}
+			input := string(content)
+			actual := serialize(gt.Parser.Parse(&input))
+
+			if update {
+				if err := os.WriteFile(goldenFile, []byte(actual), 0o644); err != nil {
+					t.Fatalf("parctest: %v", err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatalf("parctest: %v, run the test with %s=1 to create the golden file", err, UpdateEnv)
+			}
+			if diff := Diff(goldenFile, string(expected), actual); diff != "" {
+				t.Errorf("parctest: the results differ from the golden file, run the test with %s=1 to accept the changes:\n%s", UpdateEnv, diff)
+			}
+		})
+	}
+}
+
+// Diff returns with the unified diff of the expected and actual content, or an empty string if they are equal
+func Diff(name, expected, actual string) string {
+	if expected == actual {
+		return ""
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(expected),
+		B:        difflib.SplitLines(actual),
+		FromFile: name,
+		ToFile:   "actual",
+		Context:  3,
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return diff
+}
+
+// Serialize converts the final state of a parser to a readable, deterministic text format.
+// It holds the position of the index, the error message if there is any, and the tree of the results.
+func Serialize(state parc.ParserState) string {
+	var sb strings.Builder
+	row, col := state.IndexRowCol()
+	fmt.Fprintf(&sb, "index: %d\nrow: %d\ncol: %d\n", state.Index, row, col)
+	if state.IsError {
+		fmt.Fprintf(&sb, "error: %s\n", state.Err)
+	} else {
+		sb.WriteString("error: <nil>\n")
+	}
+	sb.WriteString("results: ")
+	writeValue(&sb, reflect.ValueOf(state.Results), "")
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// writeValue writes the value in an indented tree format into the builder
+func writeValue(sb *strings.Builder, v reflect.Value, indent string) {
+	if !v.IsValid() {
+		sb.WriteString("<nil>")
+		return
+	}
+	nextIndent := indent + "  "
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			sb.WriteString("<nil>")
+			return
+		}
+		if v.Kind() == reflect.Pointer {
+			sb.WriteString("&")
+		}
+		writeValue(sb, v.Elem(), indent)
+	case reflect.String:
+		fmt.Fprintf(sb, "%q", v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			sb.WriteString("<nil>")
+			return
+		}
+		if v.Len() == 0 {
+			sb.WriteString("[]")
+			return
+		}
+		sb.WriteString("[\n")
+		for i := 0; i < v.Len(); i++ {
+			sb.WriteString(nextIndent)
+			writeValue(sb, v.Index(i), nextIndent)
+			sb.WriteString("\n")
+		}
+		sb.WriteString(indent + "]")
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		sb.WriteString(v.Type().String() + "{\n")
+		for _, key := range keys {
+			sb.WriteString(nextIndent)
+			writeValue(sb, key, nextIndent)
+			sb.WriteString(": ")
+			writeValue(sb, v.MapIndex(key), nextIndent)
+			sb.WriteString("\n")
+		}
+		sb.WriteString(indent + "}")
+	case reflect.Struct:
+		sb.WriteString(v.Type().String() + "{\n")
+		for i := 0; i < v.NumField(); i++ {
+			sb.WriteString(nextIndent + v.Type().Field(i).Name + ": ")
+			if v.Type().Field(i).IsExported() {
+				writeValue(sb, v.Field(i), nextIndent)
+			} else {
+				fmt.Fprintf(sb, "%v", v.Field(i))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString(indent + "}")
+	default:
+		fmt.Fprintf(sb, "%s(%v)", v.Type(), v.Interface())
+	}
+}
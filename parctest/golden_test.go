@@ -0,0 +1,89 @@
+package parctest
+
+import (
+	"flag"
+	"github.com/stretchr/testify/require"
+	"github.com/tombenke/parc"
+	"testing"
+)
+
+type flight struct {
+	Airline string
+	Number  string
+	Day     *int
+}
+
+var flightIdentifier = parc.SequenceOf(
+	parc.SequenceOf(
+		parc.CondMinMax(parc.IsAlphaNumeric, 2, 2),
+		parc.CondMinMax(parc.IsAlphabetic, 0, 1),
+	).Map(parc.JoinStrResults).As("airline"),
+	parc.CondMinMax(parc.IsDigit, 3, 4).As("flight-number"),
+	parc.SequenceOf(parc.Char("/"), parc.NonNegativeInteger).As("day"),
+).Map(func(result parc.Result) parc.Result {
+	arr := result.([]parc.Result)
+	return flight{
+		Airline: arr[0].(string),
+		Number:  arr[1].(string),
+		Day:     parc.GetResultsItem[int](arr[2], 1),
+	}
+})
+
+// update is defined by the test package, like the tests of the grammars may define it, without conflicting with parctest
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestRunGoldenFiles(t *testing.T) {
+	RunGoldenFiles(t, flightIdentifier, "testdata/flight")
+}
+
+func TestSerialize(t *testing.T) {
+	input := "Hello World"
+	state := parc.SequenceOf(parc.Letters, parc.Space, parc.Letters).Parse(&input)
+	require.Equal(t, `index: 11
+row: 1
+col: 12
+error: <nil>
+results: [
+  "Hello"
+  " "
+  "World"
+]
+`, Serialize(state))
+
+	input = "Hello\n42"
+	state = parc.SequenceOf(parc.Letters, parc.Newline, parc.Letters).Parse(&input)
+	require.Contains(t, Serialize(state), "error: 1:1: SequenceOf(): 2:1: Letters:")
+	require.Contains(t, Serialize(state), "results: <nil>")
+
+	input = "42"
+	state = parc.Map(parc.Digits, func(parc.Result) parc.Result {
+		return map[string]any{"b": 2, "a": []parc.Result{}}
+	}).Parse(&input)
+	require.Contains(t, Serialize(state), `results: map[string]interface {}{
+  "a": []
+  "b": int(2)
+}`)
+}
+
+func TestDiff(t *testing.T) {
+	require.Equal(t, "", Diff("x.golden", "a\nb\n", "a\nb\n"))
+	diff := Diff("x.golden", "a\nb\nc\n", "a\nB\nc\n")
+	require.Contains(t, diff, "--- x.golden")
+	require.Contains(t, diff, "+++ actual")
+	require.Contains(t, diff, "-b\n")
+	require.Contains(t, diff, "+B\n")
+}
+
+func TestGoldenTest_updating(t *testing.T) {
+	t.Setenv(UpdateEnv, "")
+	require.Equal(t, *update, GoldenTest{}.updating())
+	require.True(t, GoldenTest{Update: true}.updating())
+
+	t.Setenv(UpdateEnv, "1")
+	require.True(t, GoldenTest{}.updating())
+
+	t.Setenv(UpdateEnv, "")
+	require.NoError(t, flag.Set("update", "true"))
+	defer flag.Set("update", "false")
+	require.True(t, GoldenTest{}.updating())
+}
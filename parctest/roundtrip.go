@@ -0,0 +1,41 @@
+package parctest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/tombenke/parc"
+)
+
+// RoundTrip tests the printing of the parser, by parsing each input, printing the result, and parsing the printed text again.
+// The printed text must be accepted by the parser, and printing its result must give the same text,
+// so the printer keeps the meaning of the input, and the formatting of a formatted text does not change it.
+// Every input is run as a separate sub-test named after its index.
+func RoundTrip(t *testing.T, parser *parc.Parser, inputs ...string) {
+	t.Helper()
+	for i, input := range inputs {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Helper()
+			state := parser.Parse(&input)
+			if state.IsError {
+				t.Fatalf("parctest: the input could not be parsed: %v", state.Err)
+			}
+			printed, err := parc.Print(parser, state.Results)
+			if err != nil {
+				t.Fatalf("parctest: the result could not be printed: %v", err)
+			}
+
+			state = parser.Parse(&printed)
+			if state.IsError {
+				t.Fatalf("parctest: the printed text could not be parsed: %v\n%s", state.Err, printed)
+			}
+			reprinted, err := parc.Print(parser, state.Results)
+			if err != nil {
+				t.Fatalf("parctest: the result of the printed text could not be printed: %v", err)
+			}
+			if diff := Diff("printed", printed, reprinted); diff != "" {
+				t.Errorf("parctest: the printed text is printed differently:\n%s", diff)
+			}
+		})
+	}
+}
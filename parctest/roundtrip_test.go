@@ -0,0 +1,20 @@
+package parctest
+
+import (
+	"github.com/tombenke/parc"
+	"testing"
+)
+
+// assignments is a formatter of `name = value` lines, that prints a single space around the `=` characters
+var assignments = parc.ZeroOrMore(parc.SequenceOf(
+	parc.Letters,
+	parc.PrintAs(parc.CondMin(parc.IsSpace, 0), " "),
+	parc.Char("="),
+	parc.PrintAs(parc.CondMin(parc.IsSpace, 0), " "),
+	parc.Choice(parc.IntegerLiteral, parc.Letters),
+	parc.Newline,
+))
+
+func TestRoundTrip(t *testing.T) {
+	RoundTrip(t, assignments, "", "a=1\n", "answer   =  0x2A\nname=parc\n")
+}
@@ -26,6 +26,14 @@ type ParserFun func(parserState ParserState) ParserState
 type Parser struct {
 	name      string
 	ParserFun ParserFun
+	spec      ParserSpec
+	// lookahead is the number of bytes the custom parser examines beyond the index it returns with,
+	// if it is declared by the Lookahead method
+	lookahead *int
+	// printer is the printer function set by the PrintWith method
+	printer PrintFun
+	// named is true if the parser is named by the As method
+	named bool
 }
 
 // Debug switches debugging ON with the given level. Level=0 means, Debug is switched off.
@@ -44,11 +52,18 @@ func NewParser(parserName string, parserFun ParserFun) *Parser {
 func (p *Parser) SetParserFun(parserFun ParserFun) {
 	wrapperFn := func(parserState ParserState) ParserState {
 		var indent string
+		if profiler != nil {
+			profiler.enter(p, parserState)
+		}
 		if debugLevel > 0 {
 			indent = strings.Repeat("|   ", parseDepth)
 			fmt.Printf("%s+-> %s <= Input: '%s'\n", indent, p.Name(), parserState.Remaining())
 			parseDepth = parseDepth + 1
 		}
+		var failureMark failureMark
+		if parserState.failures != nil {
+			failureMark = parserState.failures.enter(p, parserState.Index)
+		}
 		newState := parserFun(parserState)
 		if debugLevel > 0 {
 			parseDepth = parseDepth - 1
@@ -59,6 +74,15 @@ func (p *Parser) SetParserFun(parserFun ParserFun) {
 				fmt.Printf("%s    Err: %+v\n", indent, newState.Err)
 			}
 		}
+		if profiler != nil {
+			profiler.exit(p, parserState, newState)
+		}
+		if parserState.failures != nil {
+			parserState.failures.exit(p, parserState, newState, failureMark)
+		}
+		if parserState.memo != nil && !parserState.IsError {
+			parserState.memo.examine(p, parserState, newState)
+		}
 		return newState
 	}
 	p.ParserFun = wrapperFn
@@ -89,16 +113,19 @@ func (p *Parser) Map(mapper func(Result) Result) *Parser {
 
 		result := mapper(newState.Results)
 
-		return updateParserState(newState, newState.Index, Result(result))
+		return UpdateParserState(newState, newState.Index, Result(result))
 	}
 
-	return NewParser("Map("+p.Name()+")", parserFun)
+	parser := NewParser("Map("+p.Name()+")", parserFun)
+	parser.spec = ParserSpec{Kind: MapKind, Parsers: []*Parser{p}}
+	return parser
 }
 
 // As takes a name for the parser,
 // that will be used in error messages and debugging instead of the original native name of the parser
 func (p *Parser) As(name string) *Parser {
 	p.name = name
+	p.named = true
 	return p
 }
 
@@ -118,8 +145,10 @@ func (p *Parser) Chain(parserMakerFn func(Result) *Parser) *Parser {
 		nextParser := parserMakerFn(newState.Results)
 		result := nextParser.ParserFun(newState)
 
-		return updateParserState(newState, newState.Index, Result(result))
+		return UpdateParserState(newState, newState.Index, Result(result))
 	}
 
-	return NewParser("Chain("+p.Name()+")", parserFun)
+	parser := NewParser("Chain("+p.Name()+")", parserFun)
+	parser.spec = ParserSpec{Kind: ChainKind, Parsers: []*Parser{p}, ChainFn: parserMakerFn}
+	return parser
 }
@@ -0,0 +1,117 @@
+package parc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IncompleteError is the error of the parsers in partial mode, if the input ended before they could decide whether they match
+type IncompleteError struct {
+	// Needed is the minimum number of the additional bytes the parser needs, or zero if it is unknown
+	Needed int
+}
+
+// Error returns with the error message
+func (e *IncompleteError) Error() string {
+	if e.Needed == 0 {
+		return "incomplete input, needs more data"
+	}
+	return fmt.Sprintf("incomplete input, needs %d more bytes", e.Needed)
+}
+
+// UpdateParserIncomplete returns with a new copy of parser state within an IncompleteError prefixed by the name of the parser.
+// It can be used by custom parser functions to report, that they need more input in partial mode.
+func UpdateParserIncomplete(state ParserState, name string, needed int) ParserState {
+	return UpdateParserError(state, fmt.Errorf("%s: %w", name, &IncompleteError{Needed: needed}))
+}
+
+// IsPartial returns true if the input may continue after its end, so the parsers reaching the end of the input
+// must report that they need more input instead of failing
+func (ps ParserState) IsPartial() bool {
+	return ps.partial
+}
+
+// IsIncomplete returns true if the parser failed, because it needs more input
+func (ps ParserState) IsIncomplete() bool {
+	var incomplete *IncompleteError
+	return ps.IsError && errors.As(ps.Err, &incomplete)
+}
+
+// Needed returns with the minimum number of the additional bytes the parser needs, if the state is incomplete.
+// It returns with zero, if the number is unknown or the state is not incomplete.
+func (ps ParserState) Needed() int {
+	var incomplete *IncompleteError
+	if ps.IsError && errors.As(ps.Err, &incomplete) {
+		return incomplete.Needed
+	}
+	return 0
+}
+
+// ParsePartial runs the parser with the target string, that may be continued later, e.g. a chunk of a network stream.
+// The parsers, that reach the end of the input before they could decide whether they match, fail with an IncompleteError.
+// The parsing can be repeated after more data is appended to the input.
+func (p *Parser) ParsePartial(inputString *string) ParserState {
+	initialState := NewParserState(inputString, Result(nil), 0, nil)
+	initialState.partial = true
+	return p.ParserFun(initialState)
+}
+
+// PartialParser parses a stream of input, that arrives in chunks, e.g. the messages received by network code.
+// It buffers the chunks, and parses the buffered input in partial mode after each chunk.
+// If the parser succeeds, the consumed input is removed from the buffer, so the next parsing starts with the rest of the input.
+// The results of the Memo parsers, that did not reach the end of the previous chunk, are reused by the next parsing.
+type PartialParser struct {
+	parser   *Parser
+	buffer   string
+	previous map[memoKey]memoEntry
+}
+
+// NewPartialParser creates a new PartialParser with the parser of the messages of the stream
+func NewPartialParser(parser *Parser) *PartialParser {
+	return &PartialParser{parser: parser}
+}
+
+// Feed appends the data to the buffered input, and parses it in partial mode.
+// If the result is incomplete, the parsing is resumed by the next call of Feed.
+// Feed can be called with empty data, to parse the next message of the buffered input.
+func (pp *PartialParser) Feed(data string) ParserState {
+	var table *memoTable
+	if pp.previous != nil {
+		table = newMemoTable(pp.previous, newMemoEdit(pp.buffer, TextEdit{Offset: len(pp.buffer), Inserted: data}))
+	} else {
+		table = newMemoTable(nil, nil)
+	}
+	input := pp.buffer + data
+	initialState := NewParserState(&input, Result(nil), 0, nil)
+	initialState.partial = true
+	initialState.memo = table
+	newState := pp.parser.ParserFun(initialState)
+	newState.memo = nil
+	pp.buffer = input
+	pp.previous = table.entries
+	if !newState.IsError {
+		pp.consume(newState.Index)
+	}
+	return newState
+}
+
+// Close parses the buffered input as a complete input, e.g. at the end of the stream
+func (pp *PartialParser) Close() ParserState {
+	input := pp.buffer
+	newState := pp.parser.Parse(&input)
+	if !newState.IsError {
+		pp.consume(newState.Index)
+	}
+	return newState
+}
+
+// Buffered returns with the buffered input, that is not consumed yet
+func (pp *PartialParser) Buffered() string {
+	return pp.buffer
+}
+
+// consume removes the consumed input from the buffer
+func (pp *PartialParser) consume(n int) {
+	pp.buffer = pp.buffer[n:]
+	pp.previous = nil
+}
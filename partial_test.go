@@ -0,0 +1,135 @@
+package parc
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParsePartial(t *testing.T) {
+	for _, test := range []struct {
+		parser *Parser
+		input  string
+		needed int
+		err    string
+	}{
+		{Str("hello"), "hel", 2, "1:1: Str('hello'): incomplete input, needs 2 more bytes"},
+		{Str("hello"), "", 5, "1:1: Str('hello'): incomplete input, needs 5 more bytes"},
+		{Char("a"), "", 1, "1:1: Char('a'): incomplete input, needs 1 more bytes"},
+		{Cond(IsDigit), "\xe2\x82", 1, "1:1: Cond('github.com/tombenke/parc.IsDecimalDigit'): incomplete input, needs 1 more bytes"},
+		{Letters, "abc", 1, "1:1: Letters: incomplete input, needs 1 more bytes"},
+		{CondMin(IsDigit, 3), "1", 2, "1:1: CondMin: incomplete input, needs 2 more bytes"},
+		{CondMinMax(IsDigit, 2, 4), "123", 1, "1:1: CondMinMax: incomplete input, needs 1 more bytes"},
+		{Count(Str("ab"), 3), "ababa", 1, "1:1: Count(Str('ab')): 1:5: Str('ab'): incomplete input, needs 1 more bytes"},
+		{Choice(Str("hello"), Str("help")), "hel", 2, "1:1: Choice(): 1:1: Str('hello'): incomplete input, needs 2 more bytes"},
+		{ZeroOrMore(Str("ab")), "abab", 2, "1:1: ZeroOrMore(Str('ab')): 1:5: Str('ab'): incomplete input, needs 2 more bytes"},
+		{Optional(Str("ab")), "a", 1, "1:1: ZeroOrOne(Str('ab')): 1:1: Str('ab'): incomplete input, needs 1 more bytes"},
+		{EndOfInput(), "", 0, "1:1: EndOfInput: incomplete input, needs more data"},
+		{Rest(), "abc", 0, "1:1: Rest: incomplete input, needs more data"},
+		{RegExp("^[a-z]+"), "abc", 0, "1:1: RegExp(/^[a-z]+/): incomplete input, needs more data"},
+		{RegExp("^abc"), "ab", 0, "1:1: RegExp(/^abc/): incomplete input, needs more data"},
+		{RegExp(`^a\b`), "a", 0, "1:1: RegExp(/^a\\b/): incomplete input, needs more data"},
+		{QuotedString(GoStringOptions), "", 1, "1:1: QuotedString: incomplete input, needs 1 more bytes"},
+		{QuotedString(JSONStringOptions), `"wi`, 1, "1:1: QuotedString: incomplete input, needs 1 more bytes"},
+		{QuotedString(JSONStringOptions), "\"\xe2\x82", 1, "1:1: QuotedString: incomplete input, needs 1 more bytes"},
+		{QuotedString(GoStringOptions), `"a\`, 1, "1:1: QuotedString: incomplete input, needs 1 more bytes"},
+		{QuotedString(JSONStringOptions), `"\u00`, 1, "1:1: QuotedString: incomplete input, needs 1 more bytes"},
+		{QuotedString(JSONStringOptions), `"\uD83D\u`, 1, "1:1: QuotedString: incomplete input, needs 1 more bytes"},
+		{QuotedString(GoStringOptions), `"\x4`, 1, "1:1: QuotedString: incomplete input, needs 1 more bytes"},
+		{CharLiteral(CCharOptions), `'\1`, 1, "1:1: CharLiteral: incomplete input, needs 1 more bytes"},
+		{Str("ab").ErrorMap(func(ParserState) error { return errors.New("mapped") }), "a", 1, "1:1: Str('ab'): incomplete input, needs 1 more bytes"},
+	} {
+		newState := test.parser.ParsePartial(&test.input)
+		require.True(t, newState.IsIncomplete(), "%s %q: %v", test.parser.Name(), test.input, newState.Err)
+		require.Equal(t, test.needed, newState.Needed())
+		require.EqualError(t, newState.Err, test.err)
+
+		// The same input fails in normal mode
+		newState = test.parser.Parse(&test.input)
+		require.False(t, newState.IsIncomplete())
+		require.Equal(t, 0, newState.Needed())
+	}
+}
+
+func TestParsePartialDecided(t *testing.T) {
+	for _, test := range []struct {
+		parser  *Parser
+		input   string
+		isError bool
+		results Result
+	}{
+		{Str("hello"), "help", true, nil},
+		{Str("hello"), "hello", false, "hello"},
+		{Letters, "abc ", false, "abc"},
+		{CondMinMax(IsDigit, 2, 4), "1234", false, "1234"},
+		{Count(Str("ab"), 2), "ababc", false, []Result{"ab", "ab"}},
+		{Choice(Str("hello"), Str("help")), "hex", true, nil},
+		{ZeroOrMore(Str("ab")), "ababc", false, []Result{"ab", "ab"}},
+		{RegExp("^abc"), "abx", true, nil},
+		{QuotedString(JSONStringOptions), `"wi"`, false, "wi"},
+		{QuotedString(JSONStringOptions), `"\u00x`, true, nil},
+		{QuotedString(JSONStringOptions), `"\uD83D"`, false, "\uFFFD"},
+	} {
+		newState := test.parser.ParsePartial(&test.input)
+		require.False(t, newState.IsIncomplete(), "%s %q: %v", test.parser.Name(), test.input, newState.Err)
+		require.Equal(t, test.isError, newState.IsError, newState.Err)
+		if !test.isError {
+			require.Equal(t, test.results, newState.Results)
+		}
+	}
+}
+
+func TestPartialParser(t *testing.T) {
+	line := SequenceOf(Letters, Char("="), CondMin(func(r rune) bool { return r != '\n' }, 0), Newline).Map(func(result Result) Result {
+		arr := result.([]Result)
+		return []Result{arr[0], arr[2]}
+	})
+	pp := NewPartialParser(line)
+
+	newState := pp.Feed("na")
+	require.True(t, newState.IsIncomplete())
+	newState = pp.Feed("me=J")
+	require.True(t, newState.IsIncomplete())
+	require.Equal(t, 1, newState.Needed())
+
+	newState = pp.Feed("ohn\nage=4")
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{"name", "John"}, newState.Results)
+	require.Equal(t, "age=4", pp.Buffered())
+
+	newState = pp.Feed("2\nx=1\n")
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{"age", "42"}, newState.Results)
+
+	// The next message is already buffered
+	newState = pp.Feed("")
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, []Result{"x", "1"}, newState.Results)
+	require.Equal(t, "", pp.Buffered())
+
+	// The end of the stream
+	pp.Feed("y=2")
+	newState = pp.Close()
+	require.True(t, newState.IsError)
+	require.False(t, newState.IsIncomplete())
+	require.Equal(t, "y=2", pp.Buffered())
+}
+
+func TestPartialParser_Memo(t *testing.T) {
+	calls := 0
+	line := Memo(NewParser("line", func(parserState ParserState) ParserState {
+		calls++
+		return SequenceOf(Letters, Char("="), Digits, Newline).ParserFun(parserState)
+	}).Lookahead(0))
+	pp := NewPartialParser(SequenceOf(ZeroOrMore(line), Str("END\n")))
+
+	newState := pp.Feed("a=1\nb=2\n")
+	require.True(t, newState.IsIncomplete())
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	newState = pp.Feed("c=3\nEND\n")
+	require.False(t, newState.IsError, newState.Err)
+	require.Equal(t, 2, calls)
+	require.Equal(t, "", pp.Buffered())
+}
@@ -0,0 +1,161 @@
+package parc
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// WritePprof writes the collected call stacks in the gzipped protocol buffer format of pprof,
+// so the profile can be analysed by the `go tool pprof` command.
+// Every parser is represented as a function, and the samples hold the number of calls and the self time
+// of the parsers aggregated by the unique call stacks.
+func (pr *Profiler) WritePprof(w io.Writer) error {
+	strs := newStringTable()
+	functionIds := map[string]uint64{}
+	var functions []byte
+	var locations []byte
+	locationOf := func(name string) uint64 {
+		if id, ok := functionIds[name]; ok {
+			return id
+		}
+		id := uint64(len(functionIds) + 1)
+		functionIds[name] = id
+
+		var fn protoBuffer
+		fn.uint64Field(1, id)
+		fn.int64Field(2, strs.index(name))
+		fn.int64Field(3, strs.index(name))
+		functions = appendMessage(functions, 5, fn.bytes)
+
+		var line protoBuffer
+		line.uint64Field(1, id)
+		var loc protoBuffer
+		loc.uint64Field(1, id)
+		loc.messageField(4, line.bytes)
+		locations = appendMessage(locations, 4, loc.bytes)
+		return id
+	}
+
+	var profile protoBuffer
+	for _, valueType := range [][2]string{{"calls", "count"}, {"self", "nanoseconds"}} {
+		var vt protoBuffer
+		vt.int64Field(1, strs.index(valueType[0]))
+		vt.int64Field(2, strs.index(valueType[1]))
+		profile.messageField(1, vt.bytes)
+	}
+
+	for _, key := range pr.sampleOf {
+		sample := pr.samples[key]
+		locationIds := make([]uint64, 0, len(sample.stack))
+		for _, name := range sample.stack {
+			locationIds = append(locationIds, locationOf(name))
+		}
+		var s protoBuffer
+		s.packedUint64Field(1, locationIds)
+		s.packedUint64Field(2, []uint64{uint64(sample.calls), uint64(sample.selfTime.Nanoseconds())})
+		profile.messageField(2, s.bytes)
+	}
+
+	profile.bytes = append(profile.bytes, locations...)
+	profile.bytes = append(profile.bytes, functions...)
+
+	stopped := pr.stopped
+	if stopped.IsZero() {
+		stopped = pr.started
+	}
+	var periodType protoBuffer
+	periodType.int64Field(1, strs.index("calls"))
+	periodType.int64Field(2, strs.index("count"))
+
+	// The string table has to be written after every string is registered
+	var tail protoBuffer
+	tail.int64Field(9, pr.started.UnixNano())
+	tail.int64Field(10, stopped.Sub(pr.started).Nanoseconds())
+	tail.messageField(11, periodType.bytes)
+	tail.int64Field(12, 1)
+	for _, s := range strs.strings {
+		tail.stringField(6, s)
+	}
+	profile.bytes = append(profile.bytes, tail.bytes...)
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile.bytes); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// stringTable collects the strings of the profile. The first item must be the empty string.
+type stringTable struct {
+	strings []string
+	indexes map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{strings: []string{""}, indexes: map[string]int64{"": 0}}
+}
+
+// index returns with the index of the string in the table, and registers it if it is not there yet
+func (st *stringTable) index(s string) int64 {
+	if idx, ok := st.indexes[s]; ok {
+		return idx
+	}
+	idx := int64(len(st.strings))
+	st.strings = append(st.strings, s)
+	st.indexes[s] = idx
+	return idx
+}
+
+// protoBuffer is a minimal protocol buffer encoder, that supports the wire types used by the pprof format
+type protoBuffer struct {
+	bytes []byte
+}
+
+func (pb *protoBuffer) varint(v uint64) {
+	for v >= 0x80 {
+		pb.bytes = append(pb.bytes, byte(v)|0x80)
+		v >>= 7
+	}
+	pb.bytes = append(pb.bytes, byte(v))
+}
+
+func (pb *protoBuffer) key(field int, wireType int) {
+	pb.varint(uint64(field<<3 | wireType))
+}
+
+func (pb *protoBuffer) uint64Field(field int, v uint64) {
+	pb.key(field, 0)
+	pb.varint(v)
+}
+
+func (pb *protoBuffer) int64Field(field int, v int64) {
+	pb.key(field, 0)
+	pb.varint(uint64(v))
+}
+
+func (pb *protoBuffer) stringField(field int, s string) {
+	pb.key(field, 2)
+	pb.varint(uint64(len(s)))
+	pb.bytes = append(pb.bytes, s...)
+}
+
+func (pb *protoBuffer) messageField(field int, msg []byte) {
+	pb.key(field, 2)
+	pb.varint(uint64(len(msg)))
+	pb.bytes = append(pb.bytes, msg...)
+}
+
+func (pb *protoBuffer) packedUint64Field(field int, values []uint64) {
+	var packed protoBuffer
+	for _, v := range values {
+		packed.varint(v)
+	}
+	pb.messageField(field, packed.bytes)
+}
+
+// appendMessage appends an embedded message field to the buffer
+func appendMessage(buf []byte, field int, msg []byte) []byte {
+	pb := protoBuffer{bytes: buf}
+	pb.messageField(field, msg)
+	return pb.bytes
+}
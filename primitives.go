@@ -3,7 +3,9 @@ package parc
 import (
 	"fmt"
 	"regexp"
+	"regexp/syntax"
 	"strings"
+	"unicode/utf8"
 )
 
 // StartOfInput is a parser that only succeeds when the parser is at the beginning of the input.
@@ -14,16 +16,19 @@ func StartOfInput() *Parser {
 		}
 
 		if parserState.Index > 0 {
-			return updateParserError(
+			return UpdateParserError(
 				parserState,
 				fmt.Errorf("StartOfInput: expect start of input but index position is %d", parserState.Index))
 		}
 		return parserState
 	}
-	return NewParser("StartOfInput()", parserFun)
+	parser := NewParser("StartOfInput()", parserFun)
+	parser.spec = ParserSpec{Kind: StartOfInputKind}
+	return parser
 }
 
 // EndOfInput is a parser that only succeeds when there is no more input to be parsed.
+// In partial mode it needs more input at the end of the input, because the input may continue.
 func EndOfInput() *Parser {
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
@@ -31,18 +36,24 @@ func EndOfInput() *Parser {
 		}
 
 		inputLength := parserState.InputLength()
+		if parserState.partial && parserState.Index == inputLength {
+			return UpdateParserIncomplete(parserState, "EndOfInput", 0)
+		}
 		if parserState.Index != inputLength {
-			return updateParserError(
+			return UpdateParserError(
 				parserState,
 				fmt.Errorf("EndOfInput: expect end of input but got '%s'", parserState.Remaining()),
 			)
 		}
 		return parserState
 	}
-	return NewParser("EndOfInput()", parserFun)
+	parser := NewParser("EndOfInput()", parserFun)
+	parser.spec = ParserSpec{Kind: EndOfInputKind}
+	return parser
 }
 
-// Rest is a parser that returns the remaining input
+// Rest is a parser that returns the remaining input.
+// In partial mode it needs more input, because the rest is not known until the input is complete.
 func Rest() *Parser {
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
@@ -51,79 +62,183 @@ func Rest() *Parser {
 
 		inputLength := parserState.InputLength()
 		if parserState.Index > inputLength {
-			return updateParserError(
+			return UpdateParserError(
 				parserState,
 				fmt.Errorf("Rest: expect index %d less then or equal to the length of input %d", parserState.Index, inputLength))
 		}
-		return updateParserState(parserState, inputLength, Result(parserState.Remaining()))
+		if parserState.partial {
+			return UpdateParserIncomplete(parserState, "Rest", 0)
+		}
+		return UpdateParserState(parserState, inputLength, Result(parserState.Remaining()))
 	}
-	return NewParser("Rest()", parserFun)
+	parser := NewParser("Rest()", parserFun)
+	parser.spec = ParserSpec{Kind: RestKind}
+	return parser
 }
 
-// Char is a parser that matches a fixed, single character value with the target string exactly one time
+// Char is a parser that matches a fixed, single character value with the target string exactly one time.
+// In partial mode it needs more input at the end of the input.
 func Char(s string) *Parser {
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 		if len(s) != 1 {
-			return updateParserError(parserState, fmt.Errorf("Char('%s'): Wrong argument for Char('%s'). It must be a single character", s, s))
+			return UpdateParserError(parserState, fmt.Errorf("Char('%s'): Wrong argument for Char('%s'). It must be a single character", s, s))
 		}
 
 		if strings.HasPrefix(parserState.Remaining(), s) {
-			return updateParserState(parserState, parserState.Index+len(s), Result(s))
+			return UpdateParserState(parserState, parserState.Index+len(s), Result(s))
+		}
+		if parserState.partial && parserState.AtTheEnd() {
+			return UpdateParserIncomplete(parserState, "Char('"+s+"')", 1)
 		}
 
-		return updateParserError(parserState, fmt.Errorf("Char('%s'): Could not match '%s' with '%s'", s, s, parserState.Remaining()))
+		return UpdateParserError(parserState, fmt.Errorf("Char('%s'): Could not match '%s' with '%s'", s, s, parserState.Remaining()))
 	}
-	return NewParser("Char('"+s+"')", parserFun)
+	parser := NewParser("Char('"+s+"')", parserFun)
+	parser.spec = ParserSpec{Kind: CharKind, Literal: s}
+	return parser
 }
 
-// Str is a parser that matches a fixed string value with the target string exactly one time
+// Str is a parser that matches a fixed string value with the target string exactly one time.
+// In partial mode it needs more input, if the remaining input is a prefix of the value.
 func Str(s string) *Parser {
-	parser := Parser{name: "Str('" + s + "')"}
+	parser := Parser{
+		name: "Str('" + s + "')",
+		spec: ParserSpec{Kind: StrKind, Literal: s},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 
 		slicedInput := parserState.Remaining()
+		if parserState.partial && len(slicedInput) < len(s) && strings.HasPrefix(s, slicedInput) {
+			return UpdateParserIncomplete(parserState, parser.Name(), len(s)-len(slicedInput))
+		}
 		if len(slicedInput) == 0 {
-			return updateParserError(parserState, fmt.Errorf("%s: tried to match '%s', but got Unexpected end of input", parser.Name(), s))
+			return UpdateParserError(parserState, fmt.Errorf("%s: tried to match '%s', but got Unexpected end of input", parser.Name(), s))
 		}
 
 		if strings.HasPrefix(slicedInput, s) {
-			return updateParserState(parserState, parserState.Index+len(s), Result(s))
+			return UpdateParserState(parserState, parserState.Index+len(s), Result(s))
 		}
 
-		return updateParserError(parserState, fmt.Errorf("%s: could not match '%s' with '%s'", parser.Name(), s, parserState.Remaining()))
+		return UpdateParserError(parserState, fmt.Errorf("%s: could not match '%s' with '%s'", parser.Name(), s, parserState.Remaining()))
 	}
 	parser.SetParserFun(parserFun)
 	return &parser
 }
 
 // RexExp is a parser that matches the regexpStr regular expression with the target string and returns with the first match.
+// In partial mode it needs more input, if the match reaches the end of the input,
+// or if there is no match, but the remaining input is the prefix of a possible match.
 func RegExp(regexpStr string) *Parser {
-	parser := Parser{name: "RegExp(/" + regexpStr + "/)"}
+	parser := Parser{
+		name: "RegExp(/" + regexpStr + "/)",
+		spec: ParserSpec{Kind: RegExpKind, Literal: regexpStr},
+	}
 	parserFun := func(parserState ParserState) ParserState {
 		if parserState.IsError {
 			return parserState
 		}
 		slicedInput := parserState.Remaining()
+		if parserState.partial && len(slicedInput) == 0 {
+			return UpdateParserIncomplete(parserState, parser.Name(), 0)
+		}
 		if len(slicedInput) == 0 {
-			return updateParserError(parserState, fmt.Errorf("%s: tried to match /%s/, but got Unexpected end of input", parser.Name(), regexpStr))
+			return UpdateParserError(parserState, fmt.Errorf("%s: tried to match /%s/, but got Unexpected end of input", parser.Name(), regexpStr))
 		}
 
 		lettersRegexp := regexp.MustCompile(regexpStr)
 
 		loc := lettersRegexp.FindIndex([]byte(slicedInput))
 
+		if loc == nil && parserState.partial && isRegExpPrefix(regexpStr, slicedInput) {
+			return UpdateParserIncomplete(parserState, parser.Name(), 0)
+		}
 		if loc == nil {
-			return updateParserError(parserState, fmt.Errorf("%s: could not match %s", parser.Name(), regexpStr))
+			return UpdateParserError(parserState, fmt.Errorf("%s: could not match %s", parser.Name(), regexpStr))
+		}
+		// The match might be longer with more input
+		if parserState.partial && loc[1] == len(slicedInput) {
+			return UpdateParserIncomplete(parserState, parser.Name(), 0)
 		}
 
-		return updateParserState(parserState, parserState.Index+loc[1], Result(slicedInput[loc[0]:loc[1]]))
+		return UpdateParserState(parserState, parserState.Index+loc[1], Result(slicedInput[loc[0]:loc[1]]))
 	}
 	parser.SetParserFun(parserFun)
 	return &parser
 }
+
+// isRegExpPrefix tests if the input may be continued to match the regular expression.
+// It runs the program of the regular expression over the input like an unanchored search,
+// and tests if any of its threads may consume more input at the end of the input.
+func isRegExpPrefix(regexpStr, input string) bool {
+	re, err := syntax.Parse(regexpStr, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return false
+	}
+
+	// follow adds the instructions reachable from the pc without consuming input to the closure.
+	// The empty-width assertions depending on the next rune are assumed to hold at the end of the input.
+	var follow func(closure map[uint32]bool, pc uint32, context syntax.EmptyOp, atEnd bool)
+	follow = func(closure map[uint32]bool, pc uint32, context syntax.EmptyOp, atEnd bool) {
+		if closure[pc] {
+			return
+		}
+		closure[pc] = true
+		inst := &prog.Inst[pc]
+		switch inst.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			follow(closure, inst.Out, context, atEnd)
+			follow(closure, inst.Arg, context, atEnd)
+		case syntax.InstCapture, syntax.InstNop:
+			follow(closure, inst.Out, context, atEnd)
+		case syntax.InstEmptyWidth:
+			if atEnd {
+				context |= syntax.EmptyEndLine | syntax.EmptyEndText | syntax.EmptyWordBoundary | syntax.EmptyNoWordBoundary
+			}
+			if syntax.EmptyOp(inst.Arg)&^context == 0 {
+				follow(closure, inst.Out, context, atEnd)
+			}
+		}
+	}
+
+	var pending []uint32
+	previous := rune(-1)
+	for index := 0; ; {
+		r, size := utf8.DecodeRuneInString(input[index:])
+		if size == 0 {
+			r = -1
+		}
+		closure := map[uint32]bool{}
+		for _, pc := range append(pending, uint32(prog.Start)) {
+			follow(closure, pc, syntax.EmptyOpContext(previous, r), size == 0)
+		}
+
+		pending = pending[:0]
+		for pc := range closure {
+			inst := &prog.Inst[pc]
+			switch inst.Op {
+			case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+				if size == 0 {
+					return true
+				}
+				if inst.MatchRune(r) {
+					pending = append(pending, inst.Out)
+				}
+			}
+		}
+		if size == 0 {
+			return false
+		}
+		previous = r
+		index += size
+	}
+}
@@ -0,0 +1,294 @@
+package parc
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// PrintFun is the printer function of a parser, that writes the text of the result of the parser
+// by the printer, e.g. by its Write and Print methods
+type PrintFun func(printer *Printer, result Result) error
+
+// PrintWith sets the printer function of the parser, that is used by the Printer instead of the default printing
+// derived from the way the parser was built. It is needed to print the results of the Map and the custom parsers.
+func (p *Parser) PrintWith(printFun PrintFun) *Parser {
+	p.printer = printFun
+	return p
+}
+
+// Printer turns the results of the parsers back into text, so it is the inverse of the parsing.
+// It walks through the graph of the parsers, that is described by the ParserSpec of each parser, together with the result:
+//
+//   - The Str, Char, RegExp, Cond and Rest parsers print their string result, if it could be matched by the parser.
+//   - The SequenceOf parsers print the items of their result by the parsers of the sequence.
+//   - The Choice parsers print their result by the first alternative that can print it.
+//   - The repetitions, e.g. ZeroOrMore, print the items of their result by the repeated parser.
+//   - The ZeroOrOne parsers print nothing for a nil result.
+//
+// The results of the Map, Chain and custom parsers can not be printed, unless a printer function is set by the PrintWith method.
+// The printed text parsed by the same parser gives the same result, except the results changed by the printer functions.
+//
+// The layout of the text can be controlled by the PrintAs and Indented parsers, e.g. to turn a parser into a formatter,
+// that prints a single space instead of the whitespace of the input.
+type Printer struct {
+	// Indentation is written at the beginning of the lines for each level of indentation
+	Indentation string
+
+	buf         bytes.Buffer
+	level       int
+	atLineStart bool
+}
+
+// NewPrinter creates a new Printer, that indents by two spaces
+func NewPrinter() *Printer {
+	return &Printer{Indentation: "  ", atLineStart: true}
+}
+
+// Print returns with the text of the result of the parser printed by a new Printer
+func Print(parser *Parser, result Result) (string, error) {
+	printer := NewPrinter()
+	if err := printer.Print(parser, result); err != nil {
+		return "", err
+	}
+	return printer.String(), nil
+}
+
+// String returns with the printed text
+func (pr *Printer) String() string {
+	return pr.buf.String()
+}
+
+// Write writes the text, and the indentation at the beginning of its non-empty lines
+func (pr *Printer) Write(text string) {
+	for len(text) > 0 {
+		if pr.atLineStart && text[0] != '\n' {
+			pr.buf.WriteString(strings.Repeat(pr.Indentation, pr.level))
+			pr.atLineStart = false
+		}
+		line, rest, found := strings.Cut(text, "\n")
+		pr.buf.WriteString(line)
+		if !found {
+			return
+		}
+		pr.buf.WriteByte('\n')
+		pr.atLineStart = true
+		text = rest
+	}
+}
+
+// Indent increases the level of the indentation of the next lines
+func (pr *Printer) Indent() {
+	pr.level++
+}
+
+// Dedent decreases the level of the indentation of the next lines
+func (pr *Printer) Dedent() {
+	if pr.level > 0 {
+		pr.level--
+	}
+}
+
+// printerMark is the state of a printer, that can be restored to drop the text printed since then
+type printerMark struct {
+	length      int
+	level       int
+	atLineStart bool
+}
+
+// mark returns with the actual state of the printer
+func (pr *Printer) mark() printerMark {
+	return printerMark{length: pr.buf.Len(), level: pr.level, atLineStart: pr.atLineStart}
+}
+
+// reset restores the state of the printer
+func (pr *Printer) reset(mark printerMark) {
+	pr.buf.Truncate(mark.length)
+	pr.level = mark.level
+	pr.atLineStart = mark.atLineStart
+}
+
+// Print writes the text of the result of the parser.
+// It returns error if the result could not be produced by the parser, or the parser has no way to print it.
+func (pr *Printer) Print(parser *Parser, result Result) error {
+	if parser.printer != nil {
+		return parser.printer(pr, result)
+	}
+
+	spec := parser.Spec()
+	switch spec.Kind {
+	case StartOfInputKind, EndOfInputKind:
+		return nil
+
+	case StrKind, CharKind:
+		if result != spec.Literal {
+			return fmt.Errorf("Print: %s: expected %q, got %v", parser.Name(), spec.Literal, result)
+		}
+		pr.Write(spec.Literal)
+		return nil
+
+	case RestKind, RegExpKind, CondKind, CondMinKind, CondMinMaxKind:
+		text, ok := result.(string)
+		if !ok {
+			return fmt.Errorf("Print: %s: expected string, got %T", parser.Name(), result)
+		}
+		if err := checkText(spec, text); err != nil {
+			return fmt.Errorf("Print: %s: %w", parser.Name(), err)
+		}
+		pr.Write(text)
+		return nil
+
+	case SequenceOfKind:
+		results, ok := result.([]Result)
+		if !ok || len(results) != len(spec.Parsers) {
+			return fmt.Errorf("Print: %s: expected %d results, got %v", parser.Name(), len(spec.Parsers), result)
+		}
+		for i, p := range spec.Parsers {
+			if err := pr.Print(p, results[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ChoiceKind:
+		mark := pr.mark()
+		for _, alternative := range spec.Parsers {
+			if err := pr.Print(alternative, result); err == nil {
+				return nil
+			}
+			pr.reset(mark)
+		}
+		return fmt.Errorf("Print: %s: none of the alternatives can print %v", parser.Name(), result)
+
+	case ZeroOrOneKind:
+		if result == nil {
+			return nil
+		}
+		return pr.Print(spec.Parsers[0], result)
+
+	case CountKind, CountMinKind, CountMinMaxKind, ZeroOrMoreKind, OneOrMoreKind:
+		results, ok := result.([]Result)
+		if !ok || len(results) < spec.Min || spec.Max != Unbounded && len(results) > spec.Max {
+			return fmt.Errorf("Print: %s: expected %d to %d results, got %v", parser.Name(), spec.Min, spec.Max, result)
+		}
+		for _, item := range results {
+			if err := pr.Print(spec.Parsers[0], item); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ErrorMapKind, MemoKind:
+		return pr.Print(spec.Parsers[0], result)
+	}
+
+	return fmt.Errorf("Print: can not print the result of %s without a printer function", parser.Name())
+}
+
+// PrintDefault writes the shortest text the parser matches regardless of its result, e.g. the literal of a Str parser,
+// or nothing in case of an optional parser. It is useful to print the parts of the input, whose results were dropped by a Map,
+// e.g. the separators or the brackets.
+func (pr *Printer) PrintDefault(parser *Parser) error {
+	spec := parser.Spec()
+	switch spec.Kind {
+	case StartOfInputKind, EndOfInputKind, RestKind:
+		return nil
+
+	case StrKind, CharKind:
+		pr.Write(spec.Literal)
+		return nil
+
+	case RegExpKind, CondKind, CondMinKind, CondMinMaxKind:
+		if err := checkText(spec, ""); err != nil {
+			return fmt.Errorf("Print: %s: can not print without result", parser.Name())
+		}
+		return nil
+
+	case SequenceOfKind:
+		for _, p := range spec.Parsers {
+			if err := pr.PrintDefault(p); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case ChoiceKind:
+		mark := pr.mark()
+		for _, alternative := range spec.Parsers {
+			if err := pr.PrintDefault(alternative); err == nil {
+				return nil
+			}
+			pr.reset(mark)
+		}
+		return fmt.Errorf("Print: %s: none of the alternatives can print without result", parser.Name())
+
+	case CountKind, CountMinKind, CountMinMaxKind, ZeroOrOneKind, ZeroOrMoreKind, OneOrMoreKind:
+		for i := 0; i < spec.Min; i++ {
+			if err := pr.PrintDefault(spec.Parsers[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case MapKind, ErrorMapKind, MemoKind:
+		return pr.PrintDefault(spec.Parsers[0])
+	}
+
+	return fmt.Errorf("Print: %s: can not print without result", parser.Name())
+}
+
+// checkText tests if the text could be matched by the primitive parser of the spec
+func checkText(spec ParserSpec, text string) error {
+	switch spec.Kind {
+	case RegExpKind:
+		if !regexp.MustCompile(`^(?:` + spec.Literal + `)$`).MatchString(text) {
+			return fmt.Errorf("%q does not match /%s/", text, spec.Literal)
+		}
+	case CondKind, CondMinKind, CondMinMaxKind:
+		count := utf8.RuneCountInString(text)
+		if count < spec.Min || spec.Max != Unbounded && count > spec.Max {
+			return fmt.Errorf("expected %d to %d characters, got %q", spec.Min, spec.Max, text)
+		}
+		for _, r := range text {
+			if !spec.Condition(r) {
+				return fmt.Errorf("the condition does not match %q in %q", r, text)
+			}
+		}
+	}
+	return nil
+}
+
+// layoutParser returns a parser, that parses the same way as the parser, but prints by the printer function
+func layoutParser(name string, parser *Parser, printFun PrintFun) *Parser {
+	parserFun := func(parserState ParserState) ParserState {
+		return parser.ParserFun(parserState)
+	}
+
+	newParser := NewParser(name+"("+parser.Name()+")", parserFun)
+	newParser.spec = ParserSpec{Kind: MapKind, Parsers: []*Parser{parser}}
+	newParser.printer = printFun
+	return newParser
+}
+
+// PrintAs returns a parser, that parses the same way as the parser, but it prints the text regardless of the result.
+// It makes possible to normalize the layout of the printed text, e.g. `PrintAs(whitespace, " ")` prints a single space
+// instead of any whitespace, and `PrintAs(whitespace, "\n")` breaks the line.
+func PrintAs(parser *Parser, text string) *Parser {
+	return layoutParser("PrintAs", parser, func(printer *Printer, result Result) error {
+		printer.Write(text)
+		return nil
+	})
+}
+
+// Indented returns a parser, that parses the same way as the parser, but the lines it prints are indented by one more level.
+// The indentation is written at the beginning of the lines, so the line breaks should be printed by PrintAs,
+// otherwise the original indentation of the input is also printed.
+func Indented(parser *Parser) *Parser {
+	return layoutParser("Indented", parser, func(printer *Printer, result Result) error {
+		printer.Indent()
+		defer printer.Dedent()
+		return printer.Print(parser, result)
+	})
+}
@@ -0,0 +1,146 @@
+package parc
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// configParser returns a formatter of nested configuration blocks like `server { port 8080; }`
+func configParser() *Parser {
+	whitespace := CondMin(IsWhitespace, 0)
+	var block Parser
+	item := Choice(
+		SequenceOf(Letters, PrintAs(CondMin(IsWhitespace, 1), " "), Choice(IntegerLiteral, Letters), PrintAs(whitespace, ""), Char(";")),
+		&block,
+	)
+	block = *SequenceOf(
+		Letters,
+		PrintAs(whitespace, " "),
+		Char("{"),
+		Indented(ZeroOrMore(SequenceOf(PrintAs(whitespace, "\n"), item))),
+		PrintAs(whitespace, "\n"),
+		Char("}"),
+	)
+	return SequenceOf(PrintAs(whitespace, ""), &block, PrintAs(whitespace, "\n"), EndOfInput())
+}
+
+func TestPrint(t *testing.T) {
+	parser := SequenceOf(
+		Letters,
+		Optional(SequenceOf(Char(":"), Digits)),
+		ZeroOrMore(SequenceOf(Space, Choice(Str("on"), Str("off"), RegExp(`[0-9a-f]{2}`)))),
+		Between(Char("["), Char("]"))(CondMinMax(IsAsciiLetter, 0, 3)),
+		Rest(),
+	)
+	for _, input := range []string{"host:80 on 0a off[abc] rest", "host[]"} {
+		newState := parser.Parse(&input)
+		require.False(t, newState.IsError, newState.Err)
+		actual, err := Print(parser, newState.Results)
+		require.NoError(t, err)
+		require.Equal(t, input, actual)
+	}
+}
+
+func TestPrint_Layout(t *testing.T) {
+	parser := configParser()
+	input := "  server{listen   8080;name web;\n\n\tinner {x -1;}}"
+	newState := parser.Parse(&input)
+	require.False(t, newState.IsError, newState.Err)
+
+	expected := "server {\n  listen 8080;\n  name web;\n  inner {\n    x -1;\n  }\n}\n"
+	actual, err := Print(parser, newState.Results)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	// The formatted text is formatted the same way
+	newState = parser.Parse(&actual)
+	require.False(t, newState.IsError, newState.Err)
+	actual, err = Print(parser, newState.Results)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	printer := NewPrinter()
+	printer.Indentation = "\t"
+	require.NoError(t, printer.Print(parser, newState.Results))
+	require.Equal(t, "server {\n\tlisten 8080;\n\tname web;\n\tinner {\n\t\tx -1;\n\t}\n}\n", printer.String())
+}
+
+func TestPrint_Values(t *testing.T) {
+	for _, test := range []struct {
+		parser   *Parser
+		result   Result
+		expected string
+	}{
+		{NonNegativeInteger, 42, "42"},
+		{Integer, -42, "-42"},
+		{RealNumber, 42.0, "42."},
+		{RealNumber, -2.5e-30, "-2.5e-30"},
+		{IntegerLiteral, int64(-42), "-42"},
+		{HexIntegerLiteral, int64(255), "0xff"},
+		{OctalIntegerLiteral, int64(8), "0o10"},
+		{BinaryIntegerLiteral, int64(5), "0b101"},
+		{BigIntegerLiteral, new(big.Int).Lsh(big.NewInt(1), 70), "1180591620717411303424"},
+		{FloatLiteral, 1e21, "1e+21"},
+		{BigFloatLiteral(0), new(big.Float).SetPrec(64).SetFloat64(0.5), "0.5"},
+		{Capture("word", Letters), Captured{Name: "word", Value: "abc", Span: Span{Start: 0, End: 3}}, "abc"},
+	} {
+		actual, err := Print(test.parser, test.result)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, actual)
+
+		// The printed text is parsed into the same result
+		newState := test.parser.Parse(&actual)
+		require.False(t, newState.IsError, newState.Err)
+		require.Equal(t, test.result, newState.Results)
+	}
+
+	tagged := Between(Char("<"), Char(">"))(Letters).Map(func(result Result) Result { return []byte(result.(string)) })
+	tagged.PrintWith(func(printer *Printer, result Result) error {
+		printer.Write("<" + string(result.([]byte)) + ">")
+		return nil
+	})
+	actual, err := Print(tagged, []byte("abc"))
+	require.NoError(t, err)
+	require.Equal(t, "<abc>", actual)
+}
+
+func TestPrintDefault(t *testing.T) {
+	printer := NewPrinter()
+	require.NoError(t, printer.PrintDefault(SequenceOf(
+		Choice(RegExp(`[a-z]+`), Str("x")),
+		Optional(Char("?")),
+		CondMin(IsSpace, 0),
+		Count(Char("-"), 2),
+		Map(Str("ok"), JoinStrResults),
+	)))
+	require.Equal(t, "x--ok", printer.String())
+
+	require.EqualError(t, printer.PrintDefault(Letters), "Print: Letters: can not print without result")
+	require.Equal(t, "x--ok", printer.String())
+}
+
+func TestPrint_Errors(t *testing.T) {
+	for _, test := range []struct {
+		parser *Parser
+		result Result
+		err    string
+	}{
+		{Str("abc"), "abd", `Print: Str('abc'): expected "abc", got abd`},
+		{Letters, "ab1", `Print: Letters: the condition does not match '1' in "ab1"`},
+		{CondMinMax(IsDigit, 2, 3), "1", `Print: CondMinMax: expected 2 to 3 characters, got "1"`},
+		{RegExp(`[a-z]+`), "a1", `Print: RegExp(/[a-z]+/): "a1" does not match /[a-z]+/`},
+		{Rest(), 1, "Print: Rest(): expected string, got int"},
+		{SequenceOf(Letters, Digits), []Result{"a"}, "Print: SequenceOf(): expected 2 results, got [a]"},
+		{Count(Letters, 2), []Result{"a"}, "Print: Count(Letters): expected 2 to 2 results, got [a]"},
+		{Choice(Digits, Str("x")), "y", "Print: Choice(): none of the alternatives can print y"},
+		{Map(Letters, JoinStrResults), "a", "Print: can not print the result of Map(Letters) without a printer function"},
+		{Capture("a", Letters), Captured{Name: "b", Value: "x"}, `Print: Capture(a): expected capture "a", got {b x {0 0}}`},
+		{HexIntegerLiteral, int64(-1), "Print: negative value -1 can not be printed with 0x prefix"},
+		{RealNumber, math.Inf(1), "Print: RealNumber can not print +Inf"},
+	} {
+		_, err := Print(test.parser, test.result)
+		require.EqualError(t, err, test.err)
+	}
+}
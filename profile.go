@@ -0,0 +1,200 @@
+package parc
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// profiler holds the actual profiler, if profiling is switched on, otherwise it is nil
+var profiler *Profiler
+
+// RuleStats holds the statistics collected by the profiler about a single named parser
+type RuleStats struct {
+	// Name is the name of the parser
+	Name string
+	// Calls is the number of times the parser was called
+	Calls int
+	// Successes is the number of successful calls
+	Successes int
+	// Failures is the number of failed calls
+	Failures int
+	// BytesConsumed is the total number of bytes consumed by the successful calls
+	BytesConsumed int
+	// Backtracks is the number of times the parser was called again at an input position
+	// where it had already been called during the same parsing
+	Backtracks int
+	// CumulativeTime is the time spent in the parser including the time spent in its sub-parsers
+	CumulativeTime time.Duration
+	// SelfTime is the time spent in the parser excluding the time spent in its sub-parsers
+	SelfTime time.Duration
+}
+
+// Profiler collects statistics about the parsers during parsing.
+// Use the StartProfiling function to create and activate a new profiler.
+type Profiler struct {
+	started  time.Time
+	stopped  time.Time
+	stats    map[string]*RuleStats
+	frames   []profileFrame
+	active   map[string]int
+	visited  map[string]map[int]struct{}
+	samples  map[string]*profileSample
+	sampleOf []string
+}
+
+// profileFrame holds the data of a parser call that is in progress
+type profileFrame struct {
+	name      string
+	start     time.Time
+	childTime time.Duration
+}
+
+// profileSample holds the aggregated values of a unique call stack
+type profileSample struct {
+	stack    []string
+	calls    int64
+	selfTime time.Duration
+}
+
+// StartProfiling creates a new profiler and switches profiling ON.
+// Every parser call will be measured until StopProfiling is called.
+func StartProfiling() *Profiler {
+	profiler = &Profiler{
+		started: time.Now(),
+		stats:   map[string]*RuleStats{},
+		active:  map[string]int{},
+		visited: map[string]map[int]struct{}{},
+		samples: map[string]*profileSample{},
+	}
+	return profiler
+}
+
+// StopProfiling switches profiling OFF. The statistics are kept in the profiler returned by StartProfiling.
+func StopProfiling() {
+	if profiler != nil {
+		profiler.stopped = time.Now()
+	}
+	profiler = nil
+}
+
+// enter is called by the wrapper of the parser function before the parser function is executed
+func (pr *Profiler) enter(p *Parser, parserState ParserState) {
+	if parserState.IsError {
+		return
+	}
+	name := p.Name()
+	if len(pr.frames) == 0 {
+		// A new top-level parsing has been started
+		pr.visited = map[string]map[int]struct{}{}
+	}
+
+	stats := pr.ruleStats(name)
+	stats.Calls = stats.Calls + 1
+
+	positions, ok := pr.visited[name]
+	if !ok {
+		positions = map[int]struct{}{}
+		pr.visited[name] = positions
+	}
+	if _, ok := positions[parserState.Index]; ok {
+		stats.Backtracks = stats.Backtracks + 1
+	} else {
+		positions[parserState.Index] = struct{}{}
+	}
+
+	pr.active[name] = pr.active[name] + 1
+	pr.frames = append(pr.frames, profileFrame{name: name, start: time.Now()})
+}
+
+// exit is called by the wrapper of the parser function after the parser function is executed
+func (pr *Profiler) exit(p *Parser, parserState ParserState, newState ParserState) {
+	if parserState.IsError || len(pr.frames) == 0 {
+		return
+	}
+	frame := pr.frames[len(pr.frames)-1]
+	elapsed := time.Since(frame.start)
+	selfTime := elapsed - frame.childTime
+
+	stats := pr.ruleStats(frame.name)
+	if newState.IsError {
+		stats.Failures = stats.Failures + 1
+	} else {
+		stats.Successes = stats.Successes + 1
+		stats.BytesConsumed = stats.BytesConsumed + newState.Index - parserState.Index
+	}
+	stats.SelfTime = stats.SelfTime + selfTime
+
+	// In case of recursion only the outermost call is counted into the cumulative time
+	pr.active[frame.name] = pr.active[frame.name] - 1
+	if pr.active[frame.name] == 0 {
+		stats.CumulativeTime = stats.CumulativeTime + elapsed
+	}
+
+	pr.addSample(selfTime)
+
+	pr.frames = pr.frames[:len(pr.frames)-1]
+	if len(pr.frames) > 0 {
+		pr.frames[len(pr.frames)-1].childTime = pr.frames[len(pr.frames)-1].childTime + elapsed
+	}
+}
+
+// ruleStats returns with the statistics record of the parser, and creates it if it does not exist yet
+func (pr *Profiler) ruleStats(name string) *RuleStats {
+	stats, ok := pr.stats[name]
+	if !ok {
+		stats = &RuleStats{Name: name}
+		pr.stats[name] = stats
+	}
+	return stats
+}
+
+// addSample adds the self time of the actual call to the sample of the actual call stack
+func (pr *Profiler) addSample(selfTime time.Duration) {
+	stack := make([]string, 0, len(pr.frames))
+	for i := len(pr.frames) - 1; i >= 0; i-- {
+		stack = append(stack, pr.frames[i].name)
+	}
+	key := strings.Join(stack, "\x00")
+	sample, ok := pr.samples[key]
+	if !ok {
+		sample = &profileSample{stack: stack}
+		pr.samples[key] = sample
+		pr.sampleOf = append(pr.sampleOf, key)
+	}
+	sample.calls = sample.calls + 1
+	sample.selfTime = sample.selfTime + selfTime
+}
+
+// Stats returns with the statistics of every parser that was called during profiling, sorted by cost.
+// The most expensive parser, that has the highest self time, is the first one.
+func (pr *Profiler) Stats() []RuleStats {
+	stats := make([]RuleStats, 0, len(pr.stats))
+	for _, s := range pr.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].SelfTime != stats[j].SelfTime {
+			return stats[i].SelfTime > stats[j].SelfTime
+		}
+		if stats[i].Calls != stats[j].Calls {
+			return stats[i].Calls > stats[j].Calls
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Report writes the statistics of the parsers in a human readable table format to the writer, sorted by cost.
+func (pr *Profiler) Report(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "CALLS\tSUCCESS\tFAILURE\tBYTES\tBACKTRACK\tCUMULATIVE\tSELF\tPARSER")
+	for _, s := range pr.Stats() {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			s.Calls, s.Successes, s.Failures, s.BytesConsumed, s.Backtracks, s.CumulativeTime, s.SelfTime, s.Name)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,82 @@
+package parc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	word := CondMin(IsAsciiLetter, 1).As("word")
+	number := CondMin(IsDigit, 1).As("number")
+	item := Choice(number, word).As("item")
+	items := SequenceOf(item, ZeroOrMore(SequenceOf(Space, item).As("next-item"))).As("items")
+
+	input := "hello 42 world"
+	p := StartProfiling()
+	newState := items.Parse(&input)
+	StopProfiling()
+	require.False(t, newState.IsError)
+
+	stats := map[string]RuleStats{}
+	for _, s := range p.Stats() {
+		stats[s.Name] = s
+	}
+
+	require.Equal(t, 1, stats["items"].Calls)
+	require.Equal(t, 14, stats["items"].BytesConsumed)
+	require.Equal(t, 3, stats["item"].Calls)
+	require.Equal(t, 3, stats["item"].Successes)
+	require.Equal(t, 3, stats["number"].Calls)
+	require.Equal(t, 1, stats["number"].Successes)
+	require.Equal(t, 2, stats["number"].Failures)
+	require.Equal(t, 2, stats["word"].Calls)
+	require.Equal(t, 10, stats["word"].BytesConsumed)
+	require.Equal(t, 3, stats["next-item"].Calls)
+	require.Equal(t, 1, stats["next-item"].Failures)
+	require.True(t, stats["items"].CumulativeTime >= stats["item"].CumulativeTime)
+
+	// The same parser tried again at the same position counts as a backtrack
+	choice := Choice(SequenceOf(word, Str("!")), SequenceOf(word, Str("?")))
+	input = "hello?"
+	p = StartProfiling()
+	choice.Parse(&input)
+	StopProfiling()
+	for _, s := range p.Stats() {
+		if s.Name == "word" {
+			require.Equal(t, 2, s.Calls)
+			require.Equal(t, 1, s.Backtracks)
+		}
+	}
+
+	var report bytes.Buffer
+	require.NoError(t, p.Report(&report))
+	require.Contains(t, report.String(), "BACKTRACK")
+	require.Contains(t, report.String(), "word")
+
+	// Profiling is switched off
+	numStats := len(p.Stats())
+	newState = item.Parse(&input)
+	require.False(t, newState.IsError)
+	require.Equal(t, numStats, len(p.Stats()))
+}
+
+func TestProfilerWritePprof(t *testing.T) {
+	word := CondMin(IsAsciiLetter, 1).As("word")
+	input := "hello"
+	p := StartProfiling()
+	word.Parse(&input)
+	StopProfiling()
+
+	var buf bytes.Buffer
+	require.NoError(t, p.WritePprof(&buf))
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "word")
+	require.Contains(t, string(raw), "nanoseconds")
+}
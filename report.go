@@ -0,0 +1,279 @@
+package parc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// DiagnosticReport collects the diagnostics of the parsing of several inputs, e.g. the files checked by a linter,
+// and exports them in machine-readable formats: in JSON, and in SARIF 2.1.0, that is the format of the code scanning tools.
+//
+// The positions are given by the byte offsets, and by the line and column numbers calculated by IndexRowCol.
+// The Diagnose method reports only one error per input, while the DiagnoseAll method reports all the errors of an input,
+// that are linked to each other by their related locations.
+type DiagnosticReport struct {
+	// ToolName is the name of the tool, that is reported as the driver of the SARIF run
+	ToolName string
+	// ToolVersion is the version of the tool. It is omitted if it is empty.
+	ToolVersion string
+	// InformationURI is the address of the documentation of the tool. It is omitted if it is empty.
+	InformationURI string
+
+	entries []reportEntry
+}
+
+// reportEntry is a diagnostic of an input of the report
+type reportEntry struct {
+	file       string
+	input      string
+	diagnostic *Diagnostic
+}
+
+// NewDiagnosticReport creates a new, empty DiagnosticReport of the tool
+func NewDiagnosticReport(toolName string) *DiagnosticReport {
+	return &DiagnosticReport{ToolName: toolName}
+}
+
+// Add adds the diagnostics of the input of the file to the report. The nil diagnostics are skipped,
+// so the diagnostic of the Diagnose method can be added without checking it.
+func (r *DiagnosticReport) Add(file, input string, diagnostics ...*Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		if diagnostic != nil {
+			r.entries = append(r.entries, reportEntry{file: file, input: input, diagnostic: diagnostic})
+		}
+	}
+}
+
+// Len returns with the number of the diagnostics of the report
+func (r *DiagnosticReport) Len() int {
+	return len(r.entries)
+}
+
+// jsonPosition is a position of the input in the JSON report
+type jsonPosition struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// jsonRange is a range of the input in the JSON report
+type jsonRange struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+// jsonRelated is a related location of a diagnostic in the JSON report
+type jsonRelated struct {
+	Message string    `json:"message"`
+	Range   jsonRange `json:"range"`
+}
+
+// jsonDiagnostic is a diagnostic in the JSON report
+type jsonDiagnostic struct {
+	File     string        `json:"file"`
+	Rule     string        `json:"rule"`
+	Severity Severity      `json:"severity"`
+	Message  string        `json:"message"`
+	Expected []string      `json:"expected,omitempty"`
+	Notes    []string      `json:"notes,omitempty"`
+	Range    jsonRange     `json:"range"`
+	Related  []jsonRelated `json:"related,omitempty"`
+}
+
+// WriteJSON writes the diagnostics as an indented JSON array, e.g.:
+//
+//	[
+//	  {
+//	    "file": "server.conf",
+//	    "rule": "syntax",
+//	    "severity": "error",
+//	    "message": "unexpected \"}\"",
+//	    "expected": ["Digits", "\"{\""],
+//	    "range": {
+//	      "start": {"offset": 18, "line": 2, "column": 10},
+//	      "end": {"offset": 19, "line": 2, "column": 11}
+//	    }
+//	  }
+//	]
+func (r *DiagnosticReport) WriteJSON(w io.Writer) error {
+	diagnostics := make([]jsonDiagnostic, 0, len(r.entries))
+	for _, entry := range r.entries {
+		d := entry.diagnostic
+		diagnostic := jsonDiagnostic{
+			File:     entry.file,
+			Rule:     d.Rule,
+			Severity: d.Severity,
+			Message:  d.Message,
+			Expected: d.Expected,
+			Notes:    d.Notes,
+			Range:    jsonRangeOf(entry.input, d.Span),
+		}
+		for _, related := range d.Related {
+			diagnostic.Related = append(diagnostic.Related, jsonRelated{Message: related.Message, Range: jsonRangeOf(entry.input, related.Span)})
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(diagnostics); err != nil {
+		return fmt.Errorf("DiagnosticReport: %w", err)
+	}
+	return nil
+}
+
+// jsonRangeOf returns with the JSON range of the span of the input
+func jsonRangeOf(input string, span Span) jsonRange {
+	position := func(index int) jsonPosition {
+		line, column := rowColOf(input, index)
+		return jsonPosition{Offset: index, Line: line, Column: column}
+	}
+	return jsonRange{Start: position(span.Start), End: position(span.End)}
+}
+
+// sarifSchema is the address of the JSON schema of the SARIF 2.1.0 format
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// The types of the SARIF 2.1.0 log, that are used by the report
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool       sarifTool     `json:"tool"`
+		ColumnKind string        `json:"columnKind"`
+		Results    []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri,omitempty"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID           string           `json:"ruleId"`
+		RuleIndex        int              `json:"ruleIndex"`
+		Level            string           `json:"level"`
+		Message          sarifMessage     `json:"message"`
+		Locations        []sarifLocation  `json:"locations"`
+		RelatedLocations []sarifLocation  `json:"relatedLocations,omitempty"`
+		Properties       *sarifProperties `json:"properties,omitempty"`
+	}
+	sarifLocation struct {
+		ID               *int                  `json:"id,omitempty"`
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+		Message          *sarifMessage         `json:"message,omitempty"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+		ByteOffset  int `json:"byteOffset"`
+		ByteLength  int `json:"byteLength"`
+	}
+	sarifProperties struct {
+		Expected []string `json:"expected,omitempty"`
+		Notes    []string `json:"notes,omitempty"`
+	}
+)
+
+// WriteSARIF writes the diagnostics as a SARIF 2.1.0 log of a single run of the tool.
+// The rules of the run are the rules of the diagnostics in the order of their first occurrence.
+// The related locations of the diagnostics are the SARIF related locations of the results,
+// and the expected parsers and the notes are stored in the properties of the results.
+// The columns of the regions are counted in Unicode code points.
+func (r *DiagnosticReport) WriteSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool:       sarifTool{Driver: sarifDriver{Name: r.ToolName, Version: r.ToolVersion, InformationURI: r.InformationURI, Rules: []sarifRule{}}},
+		ColumnKind: "unicodeCodePoints",
+		Results:    []sarifResult{},
+	}
+	ruleIndexes := map[string]int{}
+	for _, entry := range r.entries {
+		d := entry.diagnostic
+		ruleIndex, ok := ruleIndexes[d.Rule]
+		if !ok {
+			ruleIndex = len(run.Tool.Driver.Rules)
+			ruleIndexes[d.Rule] = ruleIndex
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Rule, ShortDescription: sarifMessage{Text: ruleDescription(d.Rule)}})
+		}
+
+		result := sarifResult{
+			RuleID:    d.Rule,
+			RuleIndex: ruleIndex,
+			Level:     d.Severity.String(),
+			Message:   sarifMessage{Text: d.String()},
+			Locations: []sarifLocation{sarifLocationOf(entry.file, entry.input, d.Span)},
+		}
+		for i, related := range d.Related {
+			location := sarifLocationOf(entry.file, entry.input, related.Span)
+			location.ID = &i
+			location.Message = &sarifMessage{Text: related.Message}
+			result.RelatedLocations = append(result.RelatedLocations, location)
+		}
+		if len(d.Expected) > 0 || len(d.Notes) > 0 {
+			result.Properties = &sarifProperties{Expected: d.Expected, Notes: d.Notes}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifLog{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("DiagnosticReport: %w", err)
+	}
+	return nil
+}
+
+// ruleDescription returns with the description of the rule in the SARIF log
+func ruleDescription(rule string) string {
+	if rule == DefaultRule {
+		return "Syntax error"
+	}
+	return "Failure of " + rule
+}
+
+// sarifLocationOf returns with the SARIF location of the span of the input of the file
+func sarifLocationOf(file, input string, span Span) sarifLocation {
+	position := func(index int) (line, column int) {
+		line, column = rowColOf(input, index)
+		lineStart := index - (column - 1)
+		return line, utf8.RuneCountInString(input[lineStart:index]) + 1
+	}
+	startLine, startColumn := position(span.Start)
+	endLine, endColumn := position(span.End)
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(file)},
+		Region: sarifRegion{
+			StartLine:   startLine,
+			StartColumn: startColumn,
+			EndLine:     endLine,
+			EndColumn:   endColumn,
+			ByteOffset:  span.Start,
+			ByteLength:  span.End - span.Start,
+		},
+	}}
+}
@@ -0,0 +1,184 @@
+package parc
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// reportFixture returns a report of a syntax error, a failure inside of a named parser and a warning
+func reportFixture() *DiagnosticReport {
+	report := NewDiagnosticReport("conflint")
+	report.ToolVersion = "1.0.0"
+
+	server := "server {\n  listen }\n}"
+	_, diagnostic := configParser().Diagnose(&server)
+	report.Add("conf/server.conf", server, diagnostic)
+
+	value := Choice(Str("true"), Str("false"), SequenceOf(Char("["), Digits, Char("]"))).As("list value")
+	setting := SequenceOf(Letters, Char("="), value).As("setting")
+	flags := "a=[1"
+	_, diagnostic = setting.Diagnose(&flags)
+	report.Add("flags.ini", flags, diagnostic)
+
+	// The successful parsings have no diagnostics
+	valid := "a=true"
+	_, diagnostic = setting.Diagnose(&valid)
+	report.Add("valid.ini", valid, diagnostic)
+
+	title := "title = \"Árvíztűrő\""
+	report.Add("title.ini", title, &Diagnostic{
+		Severity: SeverityWarning,
+		Rule:     "long-title",
+		Message:  "the title is too long",
+		Span:     Span{Start: 8, End: len(title)},
+		Notes:    []string{"at most 8 characters are shown"},
+	})
+	return report
+}
+
+func TestDiagnosticReport_WriteJSON(t *testing.T) {
+	report := reportFixture()
+	require.Equal(t, 3, report.Len())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var actual []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	require.Len(t, actual, 3)
+
+	require.Equal(t, map[string]any{
+		"file":     "conf/server.conf",
+		"rule":     "syntax",
+		"severity": "error",
+		"message":  `unexpected "}"`,
+		"expected": []any{"IntegerLiteral", "Letters", `"{"`},
+		"range": map[string]any{
+			"start": map[string]any{"offset": 18.0, "line": 2.0, "column": 10.0},
+			"end":   map[string]any{"offset": 19.0, "line": 2.0, "column": 11.0},
+		},
+	}, actual[0])
+
+	require.Equal(t, map[string]any{
+		"file":     "flags.ini",
+		"rule":     "list-value",
+		"severity": "error",
+		"message":  "unexpected end of input",
+		"expected": []any{`"]"`},
+		"range": map[string]any{
+			"start": map[string]any{"offset": 4.0, "line": 1.0, "column": 5.0},
+			"end":   map[string]any{"offset": 4.0, "line": 1.0, "column": 5.0},
+		},
+		"related": []any{
+			map[string]any{"message": "in list value", "range": map[string]any{
+				"start": map[string]any{"offset": 2.0, "line": 1.0, "column": 3.0},
+				"end":   map[string]any{"offset": 2.0, "line": 1.0, "column": 3.0},
+			}},
+			map[string]any{"message": "in setting", "range": map[string]any{
+				"start": map[string]any{"offset": 0.0, "line": 1.0, "column": 1.0},
+				"end":   map[string]any{"offset": 0.0, "line": 1.0, "column": 1.0},
+			}},
+		},
+	}, actual[1])
+
+	require.Equal(t, "warning", actual[2]["severity"])
+	require.Equal(t, []any{"at most 8 characters are shown"}, actual[2]["notes"])
+	// The columns are byte based
+	require.Equal(t, map[string]any{"offset": 23.0, "line": 1.0, "column": 24.0}, actual[2]["range"].(map[string]any)["end"])
+
+	// The empty report is an empty array
+	buf.Reset()
+	require.NoError(t, NewDiagnosticReport("conflint").WriteJSON(&buf))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func TestDiagnosticReport_WriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, reportFixture().WriteSARIF(&buf))
+
+	var log struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool       sarifTool     `json:"tool"`
+			ColumnKind string        `json:"columnKind"`
+			Results    []sarifResult `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Equal(t, sarifSchema, log.Schema)
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	require.Equal(t, sarifDriver{Name: "conflint", Version: "1.0.0", Rules: []sarifRule{
+		{ID: "syntax", ShortDescription: sarifMessage{Text: "Syntax error"}},
+		{ID: "list-value", ShortDescription: sarifMessage{Text: "Failure of list-value"}},
+		{ID: "long-title", ShortDescription: sarifMessage{Text: "Failure of long-title"}},
+	}}, run.Tool.Driver)
+	require.Equal(t, "unicodeCodePoints", run.ColumnKind)
+	require.Len(t, run.Results, 3)
+
+	result := run.Results[0]
+	require.Equal(t, "syntax", result.RuleID)
+	require.Equal(t, 0, result.RuleIndex)
+	require.Equal(t, "error", result.Level)
+	require.Equal(t, `unexpected "}", expected one of IntegerLiteral, Letters, "{"`, result.Message.Text)
+	require.Equal(t, sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: "conf/server.conf"},
+		Region:           sarifRegion{StartLine: 2, StartColumn: 10, EndLine: 2, EndColumn: 11, ByteOffset: 18, ByteLength: 1},
+	}, result.Locations[0].PhysicalLocation)
+	require.Equal(t, &sarifProperties{Expected: []string{"IntegerLiteral", "Letters", `"{"`}}, result.Properties)
+
+	result = run.Results[1]
+	require.Equal(t, 1, result.RuleIndex)
+	require.Len(t, result.RelatedLocations, 2)
+	require.Equal(t, 1, *result.RelatedLocations[1].ID)
+	require.Equal(t, "in setting", result.RelatedLocations[1].Message.Text)
+	require.Equal(t, sarifRegion{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 1}, result.RelatedLocations[1].PhysicalLocation.Region)
+
+	// The columns are counted in code points
+	result = run.Results[2]
+	require.Equal(t, "warning", result.Level)
+	require.Equal(t, sarifRegion{StartLine: 1, StartColumn: 9, EndLine: 1, EndColumn: 20, ByteOffset: 8, ByteLength: 15}, result.Locations[0].PhysicalLocation.Region)
+	require.Equal(t, &sarifProperties{Notes: []string{"at most 8 characters are shown"}}, result.Properties)
+}
+
+func TestDiagnosticReport_MultipleErrors(t *testing.T) {
+	statement := SequenceOf(Letters, Str(" = "), Digits, Char(";"), Newline)
+	input := "a = ;\nb = 1;\nc = x;\nd = 2\n"
+	_, diagnostics := statement.DiagnoseAll(&input, Newline)
+	report := NewDiagnosticReport("conflint")
+	report.Add("errors.conf", input, diagnostics...)
+	require.Equal(t, 3, report.Len())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var actual []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &actual))
+	require.Len(t, actual, 3)
+	require.Equal(t, []any{
+		map[string]any{"message": `previous error: unexpected ";"`, "range": map[string]any{
+			"start": map[string]any{"offset": 4.0, "line": 1.0, "column": 5.0},
+			"end":   map[string]any{"offset": 5.0, "line": 1.0, "column": 6.0},
+		}},
+		map[string]any{"message": `next error: unexpected "\n"`, "range": map[string]any{
+			"start": map[string]any{"offset": 25.0, "line": 4.0, "column": 6.0},
+			"end":   map[string]any{"offset": 26.0, "line": 5.0, "column": 1.0},
+		}},
+	}, actual[1]["related"])
+
+	buf.Reset()
+	require.NoError(t, report.WriteSARIF(&buf))
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	results := log.Runs[0].Results
+	require.Len(t, results, 3)
+	require.Len(t, results[0].RelatedLocations, 1)
+	require.Equal(t, `next error: unexpected "x"`, results[0].RelatedLocations[0].Message.Text)
+	require.Equal(t, sarifRegion{StartLine: 3, StartColumn: 5, EndLine: 3, EndColumn: 6, ByteOffset: 17, ByteLength: 1}, results[0].RelatedLocations[0].PhysicalLocation.Region)
+	require.Len(t, results[2].RelatedLocations, 1)
+	require.Equal(t, `previous error: unexpected "x"`, results[2].RelatedLocations[0].Message.Text)
+	require.Equal(t, 0, *results[2].RelatedLocations[0].ID)
+}
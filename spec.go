@@ -0,0 +1,88 @@
+package parc
+
+// ParserKind identifies the primitive or combinator that created a parser
+type ParserKind int
+
+const (
+	// CustomParserKind is the kind of parsers created directly by NewParser with a custom parser function
+	CustomParserKind ParserKind = iota
+	StartOfInputKind
+	EndOfInputKind
+	RestKind
+	CharKind
+	StrKind
+	RegExpKind
+	CondKind
+	CondMinKind
+	CondMinMaxKind
+	SequenceOfKind
+	ChoiceKind
+	CountKind
+	CountMinKind
+	CountMinMaxKind
+	ZeroOrOneKind
+	ZeroOrMoreKind
+	OneOrMoreKind
+	MapKind
+	ChainKind
+	ErrorMapKind
+	MemoKind
+)
+
+// kindNames holds the printable names of the parser kinds
+var kindNames = map[ParserKind]string{
+	CustomParserKind: "Custom",
+	StartOfInputKind: "StartOfInput",
+	EndOfInputKind:   "EndOfInput",
+	RestKind:         "Rest",
+	CharKind:         "Char",
+	StrKind:          "Str",
+	RegExpKind:       "RegExp",
+	CondKind:         "Cond",
+	CondMinKind:      "CondMin",
+	CondMinMaxKind:   "CondMinMax",
+	SequenceOfKind:   "SequenceOf",
+	ChoiceKind:       "Choice",
+	CountKind:        "Count",
+	CountMinKind:     "CountMin",
+	CountMinMaxKind:  "CountMinMax",
+	ZeroOrOneKind:    "ZeroOrOne",
+	ZeroOrMoreKind:   "ZeroOrMore",
+	OneOrMoreKind:    "OneOrMore",
+	MapKind:          "Map",
+	ChainKind:        "Chain",
+	ErrorMapKind:     "ErrorMap",
+	MemoKind:         "Memo",
+}
+
+// String returns with the name of the parser kind
+func (k ParserKind) String() string {
+	return kindNames[k]
+}
+
+// Unbounded is the value of ParserSpec.Max in case of the repetitions without upper limit
+const Unbounded = -1
+
+// ParserSpec describes how a parser was built.
+// It makes possible to walk through the graph of the parsers, e.g. to generate sample inputs.
+type ParserSpec struct {
+	// Kind is the primitive or combinator that created the parser
+	Kind ParserKind
+	// Parsers holds the sub-parsers of the combinators
+	Parsers []*Parser
+	// Literal is the string to match in case of Str and Char, and the regular expression in case of RegExp
+	Literal string
+	// Condition is the condition function of the Cond, CondMin and CondMinMax parsers
+	Condition func(rune) bool
+	// Min is the minimum number of occurences of the repetitions
+	Min int
+	// Max is the maximum number of occurences of the repetitions. It is Unbounded if there is no upper limit.
+	Max int
+	// ChainFn is the parser maker function of the Chain parser
+	ChainFn func(Result) *Parser
+}
+
+// Spec returns with the description of how the parser was built
+func (p *Parser) Spec() ParserSpec {
+	return p.spec
+}
@@ -13,6 +13,14 @@ type ParserState struct {
 	Index       int
 	Err         error
 	IsError     bool
+	// indentation is the stack of the indentation levels of the layout-sensitive parsers. It is nil at the top level.
+	indentation *indentLevel
+	// memo is the memo table of the incremental parsing. It is nil in case of the normal parsing.
+	memo *memoTable
+	// partial is true if the input may continue after its end
+	partial bool
+	// failures is the failure tracker of the parsing started by the Diagnose method. It is nil in case of the normal parsing.
+	failures *failureTracker
 }
 
 // NewParserState creates a new ParserState instance
@@ -82,16 +90,19 @@ func (ps ParserState) IndexPosStr() string {
 	return fmt.Sprintf("index: %d, row: %d, col: %d", ps.Index, row, col)
 }
 
-// Returns with a new copy of state updated with the index and result values
-func updateParserState(state ParserState, index int, result Result) ParserState {
+// UpdateParserState returns with a new copy of state updated with the index and result values.
+// It can be used by custom parser functions to report a successful match.
+func UpdateParserState(state ParserState, index int, result Result) ParserState {
 	newState := state
 	newState.Index = index
 	newState.Results = result
 	return newState
 }
 
-// updateParserError returns with a new copy of parser state within an error message
-func updateParserError(state ParserState, errorMsg error) ParserState {
+// UpdateParserError returns with a new copy of parser state within an error message.
+// The error message is prefixed by the row and column position of the index of the state.
+// It can be used by custom parser functions to report a failure.
+func UpdateParserError(state ParserState, errorMsg error) ParserState {
 	newState := state
 	newState.IsError = true
 	row, col := newState.IndexRowCol()
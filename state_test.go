@@ -0,0 +1,45 @@
+package parc
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// run is a custom parser of a run of the same character, like the example of the tutorial
+var run = NewParser("run", func(parserState ParserState) ParserState {
+	if parserState.IsError {
+		return parserState
+	}
+	if parserState.AtTheEnd() {
+		return UpdateParserError(parserState, fmt.Errorf("run: got unexpected end of input"))
+	}
+	input := parserState.Remaining()
+	end := 1
+	for end < len(input) && input[end] == input[0] {
+		end++
+	}
+	return UpdateParserState(parserState, parserState.Index+end, input[:end])
+})
+
+func TestUpdateParserState(t *testing.T) {
+	testCases := []TestCase{
+		{Input: "a", ExpectedResult: []Result{"a"}},
+		{Input: "aaab", ExpectedResult: []Result{"aaa", "b"}},
+		{Input: "\n\n  ", ExpectedResult: []Result{"\n\n", "  "}},
+	}
+	for _, tc := range testCases {
+		newState := OneOrMore(run).Parse(&tc.Input)
+		require.False(t, newState.IsError, tc.Input)
+		require.Equal(t, tc.ExpectedResult, newState.Results, tc.Input)
+		require.Equal(t, len(tc.Input), newState.Index, tc.Input)
+	}
+}
+
+func TestUpdateParserError(t *testing.T) {
+	input := "ab\n"
+	newState := SequenceOf(Letters, Newline, run).Parse(&input)
+	require.True(t, newState.IsError)
+	require.Equal(t, 0, newState.Index)
+	require.EqualError(t, newState.Err, "1:1: SequenceOf(): 2:1: run: got unexpected end of input")
+}
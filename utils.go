@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -37,12 +38,16 @@ var (
 	// Digits is a parser that matches one or more digit characters with the target string
 	Digits = CondMin(IsDigit, 1).As("Digits")
 
-	// NonNegativeInteger is a parser that matches one or more digit characters with the target string and returns with a non negative int value
-	NonNegativeInteger = Digits.Map(func(in Result) Result {
+	// NonNegativeInteger is a parser that matches one or more digit characters with the target string and returns with a non negative int value.
+	// It fails if the value does not fit into an int.
+	NonNegativeInteger = Digits.TryMap(func(in Result) (Result, error) {
 		strValue := in.(string)
-		intValue, _ := strconv.Atoi(strValue)
-		return Result(intValue)
-	})
+		intValue, err := strconv.Atoi(strValue)
+		if err != nil {
+			return nil, fmt.Errorf("NonNegativeInteger: %s is out of range", strValue)
+		}
+		return Result(intValue), nil
+	}).PrintWith(printInt)
 
 	// WholeNumber is an aliad for a NonNegativeInteger
 	WholeNumber = NonNegativeInteger
@@ -60,25 +65,24 @@ var (
 			}
 		}
 		return Result(nil)
-	}).As("Integer")
-
-	// RealNumber is a parser for a double type real number
-	RealNumber = SequenceOf(Sign, Digits, Char("."), Optional(Digits), Exponent).Map(func(result Result) Result {
-		resultArr := result.([]Result)
-
-		sign := resultArr[0].(string)
-		intPart := resultArr[1].(string)
-		fractPart := "0"
-		if fractPartValue, ok := resultArr[3].(string); ok {
-			fractPart = fractPartValue
+	}).As("Integer").PrintWith(printInt)
+
+	// RealNumber is a parser for a double type real number.
+	// The number must have a fraction part or an exponent, e.g. `3.14`, `42.`, `-2.5e-3` or `1e10`.
+	RealNumber = SequenceOf(
+		numberSign,
+		Digits,
+		Choice(
+			SequenceOf(Char("."), Optional(Digits), Optional(exponentText)),
+			exponentText,
+		),
+	).Map(JoinStrResults).TryMap(func(result Result) (Result, error) {
+		realValue, err := strconv.ParseFloat(result.(string), 64)
+		if err != nil {
+			return nil, fmt.Errorf("RealNumber: %s is out of range", result)
 		}
-		exponent := resultArr[4].(int)
-
-		realValue, _ := strconv.ParseFloat(fmt.Sprintf("%s%s.%s", sign, intPart, fractPart), 64)
-		realValue = realValue * math.Pow10(exponent)
-
-		return Result(realValue)
-	}).As("RealNumber")
+		return Result(realValue), nil
+	}).As("RealNumber").PrintWith(printRealNumber)
 
 	// Sign is a parser for the sign of a number value. It has a default value, that is "+".
 	Sign = Optional(Choice(Char("+"), Char("-"))).Map(func(result Result) Result {
@@ -90,6 +94,9 @@ var (
 
 	}).As("Sign")
 
+	// exponentText matches the exponent part of a real number, and returns with its text
+	exponentText = SequenceOf(Choice(Char("e"), Char("E")), Optional(Choice(Char("+"), Char("-"))), Digits)
+
 	// Exponent is a parser for the exponent part of a real number
 	Exponent = Optional(SequenceOf(Choice(Char("e"), Char("E")), Sign, Integer)).Map(func(result Result) Result {
 
@@ -118,17 +125,52 @@ var (
 	})
 )
 
-// JoinStrResults merges the a string-array result into a single string. The items of the array must be string type.
-// TODO: Handle non-string type items, e.g. skip nils, stringify other types, etc.
+// JoinStrResults merges the a string-array result into a single string.
+// The items of the array must be string type, nil, e.g. the result of a missing optional item, or an array of such items.
+// The nil items are skipped, and the nested arrays are merged recursively.
 func JoinStrResults(in Result) Result {
 	resultsArr := in.([]Result)
 	var results string
 	for _, v := range resultsArr {
-		results = results + v.(string)
+		switch v := v.(type) {
+		case nil:
+		case []Result:
+			results = results + JoinStrResults(v).(string)
+		default:
+			results = results + v.(string)
+		}
 	}
 	return Result(results)
 }
 
+// printInt is the printer function of the int results of the NonNegativeInteger and Integer parsers
+func printInt(printer *Printer, result Result) error {
+	value, ok := result.(int)
+	if !ok {
+		return fmt.Errorf("Print: expected int, got %T", result)
+	}
+	printer.Write(strconv.Itoa(value))
+	return nil
+}
+
+// printRealNumber is the printer function of the float64 results of the RealNumber parser.
+// It prints a fraction point if it is needed, and fails in case of the infinite and NaN values.
+func printRealNumber(printer *Printer, result Result) error {
+	value, ok := result.(float64)
+	if !ok {
+		return fmt.Errorf("Print: expected float64, got %T", result)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return fmt.Errorf("Print: RealNumber can not print %v", value)
+	}
+	text := strconv.FormatFloat(value, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".e") {
+		text += "."
+	}
+	printer.Write(text)
+	return nil
+}
+
 // Ref creates a reference to any value
 // It is useful to define reference values of fixtures in test cases
 func Ref[T any](value T) *T {
@@ -143,6 +143,9 @@ func TestRealNumber(t *testing.T) {
 		TestCase{Input: "-3.14E2", ExpectedResult: Result(float64(-314.))},
 		TestCase{Input: "-2500.e-2", ExpectedResult: Result(float64(-25.))},
 		TestCase{Input: "2500.e0", ExpectedResult: Result(float64(2500.))},
+		TestCase{Input: "1e10", ExpectedResult: Result(float64(1e10))},
+		TestCase{Input: "-5E-1", ExpectedResult: Result(float64(-0.5))},
+		TestCase{Input: "0.1", ExpectedResult: Result(float64(0.1))},
 	}
 
 	for _, tc := range testCases {
@@ -151,3 +154,27 @@ func TestRealNumber(t *testing.T) {
 		require.False(t, newState.IsError)
 	}
 }
+
+func TestRealNumberErrors(t *testing.T) {
+	for _, input := range []string{"42", "abc", "1e999"} {
+		newState := RealNumber.Parse(&input)
+		require.True(t, newState.IsError, input)
+	}
+
+	input := "1e999"
+	newState := RealNumber.Parse(&input)
+	require.EqualError(t, newState.Err, "1:1: RealNumber: 1e999 is out of range")
+}
+
+func TestNonNegativeIntegerOverflow(t *testing.T) {
+	input := "x99999999999999999999"
+	newState := SequenceOf(Char("x"), NonNegativeInteger).Parse(&input)
+	require.True(t, newState.IsError)
+	require.Contains(t, newState.Err.Error(), "1:2: NonNegativeInteger: 99999999999999999999 is out of range")
+}
+
+func TestJoinStrResults(t *testing.T) {
+	require.Equal(t, Result("abc"), JoinStrResults([]Result{"a", "b", "c"}))
+	require.Equal(t, Result("ac"), JoinStrResults([]Result{"a", nil, "c"}))
+	require.Equal(t, Result("abcd"), JoinStrResults([]Result{"a", []Result{"b", []Result{"c"}, nil}, "d"}))
+}